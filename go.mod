@@ -4,6 +4,9 @@ go 1.24.0
 
 toolchain go1.24.4
 
-require gopkg.in/yaml.v3 v3.0.1
+require (
+	golang.org/x/sys v0.39.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require golang.org/x/crypto v0.46.0 // indirect
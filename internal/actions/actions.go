@@ -9,6 +9,7 @@ import (
 
 	"github.com/tgextreme/neon-watchdog/internal/config"
 	"github.com/tgextreme/neon-watchdog/internal/logger"
+	"github.com/tgextreme/neon-watchdog/internal/process"
 )
 
 // Result representa el resultado de ejecutar una acción
@@ -48,51 +49,55 @@ func (a *ActionWithHooks) Name() string {
 }
 
 func (a *ActionWithHooks) Execute(ctx context.Context) Result {
-	// Before hooks
-	if len(a.hooks.BeforeRestart) > 0 {
-		a.log.Debug("executing before_restart hooks", logger.Fields("count", len(a.hooks.BeforeRestart)))
-		for _, cmd := range a.hooks.BeforeRestart {
-			if err := runHook(ctx, cmd); err != nil {
-				a.log.Warn("before_restart hook failed", logger.Fields("command", cmd, "error", err.Error()))
-			}
+	hookCtx := hookContextFrom(ctx)
+	hookCtx.Action = a.action.Name()
+
+	// Before hooks: un fallo con on_error=abort cancela la acción
+	if err := a.runHooks(ctx, "before_restart", a.hooks.BeforeRestart, hookCtx); err != nil {
+		return Result{
+			Success: false,
+			Message: fmt.Sprintf("action cancelled: %v", err),
 		}
 	}
 
 	// Execute main action
 	result := a.action.Execute(ctx)
-
-	// After hooks (solo si success)
-	if result.Success && len(a.hooks.AfterRestart) > 0 {
-		a.log.Debug("executing after_restart hooks", logger.Fields("count", len(a.hooks.AfterRestart)))
-		for _, cmd := range a.hooks.AfterRestart {
-			if err := runHook(ctx, cmd); err != nil {
-				a.log.Warn("after_restart hook failed", logger.Fields("command", cmd, "error", err.Error()))
-			}
-		}
+	hookCtx.Result = "success"
+	if !result.Success {
+		hookCtx.Result = "failure"
 	}
-
-	// Failure hooks (solo si failed)
-	if !result.Success && len(a.hooks.OnFailure) > 0 {
-		a.log.Debug("executing on_failure hooks", logger.Fields("count", len(a.hooks.OnFailure)))
-		for _, cmd := range a.hooks.OnFailure {
-			if err := runHook(ctx, cmd); err != nil {
-				a.log.Warn("on_failure hook failed", logger.Fields("command", cmd, "error", err.Error()))
-			}
-		}
+	hookCtx.ResultMessage = result.Message
+
+	if result.Success {
+		// After hooks (solo si success)
+		a.runHooks(ctx, "after_restart", a.hooks.AfterRestart, hookCtx)
+	} else {
+		// Failure hooks (solo si failed)
+		a.runHooks(ctx, "on_failure", a.hooks.OnFailure, hookCtx)
 	}
 
 	return result
 }
 
-func runHook(ctx context.Context, cmdStr string) error {
-	parts := strings.Fields(cmdStr)
-	if len(parts) == 0 {
-		return fmt.Errorf("empty hook command")
+// runHooks ejecuta los hooks de una fase en orden. Se detiene en el primer fallo de un
+// hook con on_error=abort y lo retorna; los demás fallos solo se registran
+func (a *ActionWithHooks) runHooks(ctx context.Context, phase string, hooks []config.Hook, hookCtx HookContext) error {
+	if len(hooks) == 0 {
+		return nil
 	}
+	a.log.Debug("executing "+phase+" hooks", logger.Fields("count", len(hooks)))
 
-	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
-	if err := cmd.Run(); err != nil {
-		return err
+	hookCtx.Hook = phase
+	for _, hook := range hooks {
+		err := runHook(ctx, hook, &a.hooks.ExecOptions, hookCtx)
+		if err == nil {
+			continue
+		}
+		if hook.OnError == "abort" {
+			a.log.Error(phase+" hook failed, aborting", logger.Fields("command", hook.String(), "error", err.Error()))
+			return fmt.Errorf("%s hook '%s' failed: %w", phase, hook.String(), err)
+		}
+		a.log.Warn(phase+" hook failed", logger.Fields("command", hook.String(), "error", err.Error()))
 	}
 	return nil
 }
@@ -101,6 +106,7 @@ func runHook(ctx context.Context, cmdStr string) error {
 type ExecAction struct {
 	Command []string
 	Type    string // "start" o "restart"
+	Options config.ExecOptions
 }
 
 func (a *ExecAction) Name() string {
@@ -118,12 +124,17 @@ func (a *ExecAction) Execute(ctx context.Context) Result {
 		}
 	}
 
-	cmd := exec.CommandContext(ctx, a.Command[0], a.Command[1:]...)
-	output, err := cmd.CombinedOutput()
+	output := process.NewCappedBuffer(a.Options.MaxOutputBytes)
+	cmd, err := process.Command(ctx, &a.Options, a.Command[0], a.Command[1:]...)
+	if err == nil {
+		cmd.Stdout = output
+		cmd.Stderr = output
+		err = cmd.Run()
+	}
 	latency := time.Since(start)
 
 	if err != nil {
-		outputStr := strings.TrimSpace(string(output))
+		outputStr := strings.TrimSpace(output.String())
 		if len(outputStr) > 300 {
 			outputStr = outputStr[:300] + "..."
 		}
@@ -179,55 +190,12 @@ func (a *SystemdAction) Execute(ctx context.Context) Result {
 
 // NewAction crea una acción basada en la configuración
 func NewAction(actionCfg config.Action, isFirstFailure bool, log *logger.Logger) (Action, error) {
-	var baseAction Action
-	var err error
-
-	switch actionCfg.Type {
-	case "exec":
-		if actionCfg.Exec == nil {
-			return nil, fmt.Errorf("exec action config is nil")
-		}
-
-		// Decidir si usar start o restart
-		var command []string
-		actionType := "restart"
-
-		if isFirstFailure && len(actionCfg.Exec.Start) > 0 {
-			command = actionCfg.Exec.Start
-			actionType = "start"
-		} else if len(actionCfg.Exec.Restart) > 0 {
-			command = actionCfg.Exec.Restart
-		} else if len(actionCfg.Exec.Start) > 0 {
-			command = actionCfg.Exec.Start
-			actionType = "start"
-		} else {
-			return nil, fmt.Errorf("no command defined in exec action")
-		}
-
-		baseAction = &ExecAction{
-			Command: command,
-			Type:    actionType,
-		}
-
-	case "systemd":
-		if actionCfg.Systemd == nil {
-			return nil, fmt.Errorf("systemd action config is nil")
-		}
-
-		method := actionCfg.Systemd.Method
-		if method == "" {
-			method = "restart"
-		}
-
-		baseAction = &SystemdAction{
-			Unit:   actionCfg.Systemd.Unit,
-			Method: method,
-		}
-
-	default:
+	factory, ok := lookupFactory(actionCfg.Type)
+	if !ok {
 		return nil, fmt.Errorf("unknown action type: %s", actionCfg.Type)
 	}
 
+	baseAction, err := factory(actionCfg, isFirstFailure)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,87 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/containers"
+)
+
+// ContainerAction reinicia, arranca o recrea contenedores Docker/Podman vía Engine API
+type ContainerAction struct {
+	Config      *config.ContainerAction
+	Method      string // restart, start, recreate
+	StopTimeout int    // segundos antes de matar el contenedor
+}
+
+func (a *ContainerAction) Name() string {
+	selector := a.Config.Name
+	if selector == "" {
+		parts := make([]string, 0, len(a.Config.Labels))
+		for k, v := range a.Config.Labels {
+			parts = append(parts, k+"="+v)
+		}
+		sort.Strings(parts)
+		selector = strings.Join(parts, ",")
+	}
+	return fmt.Sprintf("container:%s %s", selector, a.Method)
+}
+
+func (a *ContainerAction) Execute(ctx context.Context) Result {
+	start := time.Now()
+
+	client, err := containers.NewClient(a.Config.Socket)
+	if err != nil {
+		return Result{Success: false, Message: err.Error(), Latency: time.Since(start)}
+	}
+	defer client.Close()
+
+	found, err := client.Find(ctx, a.Config.Name, a.Config.Labels)
+	if err != nil {
+		return Result{Success: false, Message: err.Error(), Latency: time.Since(start)}
+	}
+	if len(found) == 0 {
+		return Result{
+			Success: false,
+			Message: fmt.Sprintf("no container matches %s", strings.TrimPrefix(a.Name(), "container:")),
+			Latency: time.Since(start),
+		}
+	}
+
+	done := []string{}
+	errors := []string{}
+	for _, ct := range found {
+		var err error
+		switch a.Method {
+		case "start":
+			err = client.Start(ctx, ct.ID)
+		case "recreate":
+			_, err = client.Recreate(ctx, ct.ID, a.StopTimeout)
+		default:
+			err = client.Restart(ctx, ct.ID, a.StopTimeout)
+		}
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", ct.Name(), err))
+			continue
+		}
+		done = append(done, ct.Name())
+	}
+
+	if len(errors) > 0 {
+		return Result{
+			Success: false,
+			Message: fmt.Sprintf("container %s failed: %s", a.Method, strings.Join(errors, "; ")),
+			Latency: time.Since(start),
+		}
+	}
+
+	return Result{
+		Success: true,
+		Message: fmt.Sprintf("container %s: %s", a.Method, strings.Join(done, ", ")),
+		Latency: time.Since(start),
+	}
+}
@@ -0,0 +1,90 @@
+package actions
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/containers"
+	"github.com/tgextreme/neon-watchdog/internal/containers/containerstest"
+)
+
+func stoppedContainer(id, name string) *containerstest.Container {
+	ct := &containerstest.Container{ID: id, Name: name, Labels: map[string]string{"app": "shop"}}
+	ct.State = containers.State{Status: "exited", ExitCode: 1}
+	return ct
+}
+
+func TestContainerActionStart(t *testing.T) {
+	srv := containerstest.NewServer(t, stoppedContainer("aaa", "web"), stoppedContainer("bbb", "worker"))
+	action := &ContainerAction{
+		Config: &config.ContainerAction{Socket: srv.Socket, Labels: map[string]string{"app": "shop"}},
+		Method: "start",
+	}
+
+	result := action.Execute(context.Background())
+	if !result.Success {
+		t.Fatalf("start failed: %s", result.Message)
+	}
+	for _, id := range []string{"aaa", "bbb"} {
+		if !srv.Container(id).State.Running {
+			t.Fatalf("container %s was not started", id)
+		}
+	}
+}
+
+func TestContainerActionRestart(t *testing.T) {
+	srv := containerstest.NewServer(t, stoppedContainer("aaa", "web"))
+	action := &ContainerAction{
+		Config:      &config.ContainerAction{Socket: srv.Socket, Name: "web"},
+		Method:      "restart",
+		StopTimeout: 7,
+	}
+
+	result := action.Execute(context.Background())
+	if !result.Success || result.Message != "container restart: web" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if calls := srv.Calls(); len(calls) != 1 || calls[0] != "POST /containers/aaa/restart?t=7" {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
+
+func TestContainerActionRecreate(t *testing.T) {
+	ct := stoppedContainer("aaa", "web")
+	ct.State = containers.State{Status: "running", Running: true}
+	srv := containerstest.NewServer(t, ct)
+	action := &ContainerAction{
+		Config:      &config.ContainerAction{Socket: srv.Socket, Name: "web"},
+		Method:      "recreate",
+		StopTimeout: 10,
+	}
+
+	result := action.Execute(context.Background())
+	if !result.Success {
+		t.Fatalf("recreate failed: %s", result.Message)
+	}
+	calls := srv.Calls()
+	if len(calls) != 4 || calls[0] != "POST /containers/aaa/stop?t=10" || calls[1] != "DELETE /containers/aaa?force=1" {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
+
+func TestContainerActionErrors(t *testing.T) {
+	broken := stoppedContainer("aaa", "web")
+	broken.StartError = "driver failed programming external connectivity"
+	srv := containerstest.NewServer(t, broken)
+
+	action := &ContainerAction{Config: &config.ContainerAction{Socket: srv.Socket, Name: "web"}, Method: "start"}
+	result := action.Execute(context.Background())
+	if result.Success || !strings.Contains(result.Message, "container start failed: web: engine API POST /containers/aaa/start returned 500: driver failed") {
+		t.Fatalf("expected engine error in result, got %+v", result)
+	}
+
+	action = &ContainerAction{Config: &config.ContainerAction{Socket: srv.Socket, Name: "missing"}, Method: "restart"}
+	result = action.Execute(context.Background())
+	if result.Success || result.Message != "no container matches missing restart" {
+		t.Fatalf("expected no match error, got %+v", result)
+	}
+}
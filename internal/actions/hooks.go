@@ -0,0 +1,93 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/process"
+)
+
+// HookContext datos del evento disponibles para los hooks, como variables NEON_* y
+// como campos de las plantillas ({{.Target}}, {{.Reason}}, ...)
+type HookContext struct {
+	Target              string
+	Reason              string
+	ConsecutiveFailures int
+	Action              string
+	Result              string // vacío en before_restart, "success" o "failure" después
+	ResultMessage       string
+	Hook                string // before_restart, after_restart, on_failure
+	Diagnostics         string // bundle de diagnóstico recogido antes de la acción
+}
+
+type hookContextKey struct{}
+
+// WithHookContext añade al contexto los datos del evento que recibirán los hooks
+func WithHookContext(ctx context.Context, hookCtx HookContext) context.Context {
+	return context.WithValue(ctx, hookContextKey{}, hookCtx)
+}
+
+func hookContextFrom(ctx context.Context) HookContext {
+	hookCtx, _ := ctx.Value(hookContextKey{}).(HookContext)
+	return hookCtx
+}
+
+// env variables NEON_* del evento
+func (h HookContext) env() map[string]string {
+	return map[string]string{
+		"NEON_TARGET":               h.Target,
+		"NEON_REASON":               h.Reason,
+		"NEON_CONSECUTIVE_FAILURES": strconv.Itoa(h.ConsecutiveFailures),
+		"NEON_ACTION":               h.Action,
+		"NEON_RESULT":               h.Result,
+		"NEON_RESULT_MESSAGE":       h.ResultMessage,
+		"NEON_HOOK":                 h.Hook,
+		"NEON_DIAGNOSTICS":          h.Diagnostics,
+	}
+}
+
+// runHook expande las plantillas del hook y lo ejecuta con su timeout
+func runHook(ctx context.Context, hook config.Hook, opts *config.ExecOptions, hookCtx HookContext) error {
+	argv, err := hook.Argv(hookCtx)
+	if err != nil {
+		return err
+	}
+
+	if hook.TimeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(hook.TimeoutSeconds)*time.Second)
+		defer cancel()
+	}
+
+	// Las variables del evento se añaden a las de exec options sin modificarlas
+	hookOpts := *opts
+	hookOpts.Env = hookCtx.env()
+	for k, v := range opts.Env {
+		hookOpts.Env[k] = v
+	}
+
+	output := process.NewCappedBuffer(opts.MaxOutputBytes)
+	cmd, err := process.Command(ctx, &hookOpts, argv[0], argv[1:]...)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timeout after %ds", hook.TimeoutSeconds)
+		}
+		if out := strings.TrimSpace(output.String()); out != "" {
+			if len(out) > 300 {
+				out = out[:300] + "..."
+			}
+			return fmt.Errorf("%w (output: %s)", err, out)
+		}
+		return err
+	}
+	return nil
+}
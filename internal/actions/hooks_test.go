@@ -0,0 +1,34 @@
+package actions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// El motivo viene de la salida de los checks: un hook shell lo recibe como dato, nunca como código
+func TestShellHookReceivesReasonAsData(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "reason")
+	marker := filepath.Join(dir, "pwned")
+	reason := "$(touch " + marker + ") `touch " + marker + "` \"; touch " + marker + "; '"
+
+	hook := config.Hook{Shell: `printf '%s' "$NEON_REASON" > "` + out + `"`}
+	if err := runHook(context.Background(), hook, &config.ExecOptions{}, HookContext{Target: "api", Reason: reason}); err != nil {
+		t.Fatalf("hook failed: %v", err)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != reason {
+		t.Fatalf("hook received %q, want %q", got, reason)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("reason was executed by the shell")
+	}
+}
@@ -0,0 +1,82 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/httpclient"
+)
+
+// HTTPAction llama a un endpoint de administración o a la API de un orquestador
+type HTTPAction struct {
+	Config  *config.HTTPAction
+	Method  string
+	Timeout time.Duration
+}
+
+func (a *HTTPAction) Name() string {
+	return fmt.Sprintf("http:%s %s", a.Method, a.Config.URL)
+}
+
+func (a *HTTPAction) Execute(ctx context.Context) Result {
+	start := time.Now()
+
+	// El body es una plantilla con los mismos campos que los hooks
+	hookCtx := hookContextFrom(ctx)
+	hookCtx.Action = a.Name()
+	body, err := config.ExpandHookTemplate(a.Config.Body, hookCtx)
+	if err != nil {
+		return Result{Success: false, Message: fmt.Sprintf("invalid body: %v", err), Latency: time.Since(start)}
+	}
+
+	client, err := httpclient.New(a.Timeout, a.Config.Auth, a.Config.TLS)
+	if err != nil {
+		return Result{Success: false, Message: err.Error(), Latency: time.Since(start)}
+	}
+
+	resp, err := client.Do(ctx, httpclient.Request{
+		Method:  a.Method,
+		URL:     a.Config.URL,
+		Headers: a.Config.Headers,
+		Body:    body,
+	})
+	latency := time.Since(start)
+
+	if err != nil {
+		return Result{
+			Success: false,
+			Message: fmt.Sprintf("http request failed: %v", err),
+			Latency: latency,
+		}
+	}
+
+	if !a.expected(resp.StatusCode) {
+		respBody := strings.TrimSpace(resp.Body)
+		if len(respBody) > 300 {
+			respBody = respBody[:300] + "..."
+		}
+		return Result{
+			Success: false,
+			Message: fmt.Sprintf("%s %s returned unexpected status %d (body: %s)", a.Method, a.Config.URL, resp.StatusCode, respBody),
+			Latency: latency,
+		}
+	}
+
+	return Result{
+		Success: true,
+		Message: fmt.Sprintf("%s %s returned %d", a.Method, a.Config.URL, resp.StatusCode),
+		Latency: latency,
+	}
+}
+
+// expected indica si el código es uno de expected_status (o cualquier 2xx si no se configuró)
+func (a *HTTPAction) expected(status int) bool {
+	if len(a.Config.ExpectedStatus) == 0 {
+		return status >= 200 && status < 300
+	}
+	return slices.Contains(a.Config.ExpectedStatus, status)
+}
@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/plugins"
+)
+
+// PluginAction delega la recuperación en un plugin externo mediante el protocolo JSON
+type PluginAction struct {
+	Plugin    *config.PluginConfig
+	Operation string // start, restart
+}
+
+func (a *PluginAction) Name() string {
+	return fmt.Sprintf("plugin:%s %s", a.Plugin.Name, a.Operation)
+}
+
+func (a *PluginAction) Execute(ctx context.Context) Result {
+	start := time.Now()
+
+	req := plugins.Request{
+		Command:   plugins.CommandAction,
+		Target:    a.Plugin.Target,
+		Operation: a.Operation,
+		Config:    a.Plugin.Config,
+	}
+
+	var resp plugins.ActionResult
+	if err := plugins.Call(ctx, a.Plugin.Dir, a.Plugin.Name, req, &resp); err != nil {
+		return Result{
+			Success: false,
+			Message: err.Error(),
+			Latency: time.Since(start),
+		}
+	}
+
+	message := resp.Message
+	if message == "" {
+		message = fmt.Sprintf("plugin %s %s finished", a.Plugin.Name, a.Operation)
+	}
+
+	return Result{
+		Success: resp.Success,
+		Message: message,
+		Latency: time.Since(start),
+	}
+}
@@ -0,0 +1,146 @@
+package actions
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// Factory construye una acción; isFirstFailure permite elegir entre start y restart
+type Factory func(cfg config.Action, isFirstFailure bool) (Action, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+func init() {
+	Register("exec", newExecAction)
+	Register("systemd", newSystemdAction)
+	Register("plugin", newPluginAction)
+	Register("container", newContainerAction)
+	Register("http", newHTTPAction)
+}
+
+// Register da de alta la factory de un tipo de acción. Falla si el nombre ya existe
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if _, exists := factories[name]; exists {
+		panic(fmt.Sprintf("actions: type %q already registered", name))
+	}
+	factories[name] = factory
+}
+
+func lookupFactory(name string) (Factory, bool) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	factory, ok := factories[name]
+	return factory, ok
+}
+
+func newExecAction(cfg config.Action, isFirstFailure bool) (Action, error) {
+	if cfg.Exec == nil {
+		return nil, fmt.Errorf("exec action config is nil")
+	}
+
+	// Decidir si usar start o restart
+	var command []string
+	actionType := "restart"
+
+	if isFirstFailure && len(cfg.Exec.Start) > 0 {
+		command = cfg.Exec.Start
+		actionType = "start"
+	} else if len(cfg.Exec.Restart) > 0 {
+		command = cfg.Exec.Restart
+	} else if len(cfg.Exec.Start) > 0 {
+		command = cfg.Exec.Start
+		actionType = "start"
+	} else {
+		return nil, fmt.Errorf("no command defined in exec action")
+	}
+
+	return &ExecAction{
+		Command: command,
+		Type:    actionType,
+		Options: cfg.Exec.ExecOptions,
+	}, nil
+}
+
+func newSystemdAction(cfg config.Action, isFirstFailure bool) (Action, error) {
+	if cfg.Systemd == nil {
+		return nil, fmt.Errorf("systemd action config is nil")
+	}
+
+	method := cfg.Systemd.Method
+	if method == "" {
+		method = "restart"
+	}
+
+	return &SystemdAction{
+		Unit:   cfg.Systemd.Unit,
+		Method: method,
+	}, nil
+}
+
+func newPluginAction(cfg config.Action, isFirstFailure bool) (Action, error) {
+	if cfg.Plugin == nil {
+		return nil, fmt.Errorf("plugin action config is nil")
+	}
+
+	operation := "restart"
+	if isFirstFailure {
+		operation = "start"
+	}
+
+	return &PluginAction{
+		Plugin:    cfg.Plugin,
+		Operation: operation,
+	}, nil
+}
+
+func newContainerAction(cfg config.Action, isFirstFailure bool) (Action, error) {
+	if cfg.Container == nil {
+		return nil, fmt.Errorf("container action config is nil")
+	}
+
+	method := cfg.Container.Method
+	if method == "" {
+		method = "restart"
+	}
+	stopTimeout := cfg.Container.StopTimeoutSeconds
+	if stopTimeout == 0 {
+		stopTimeout = 10
+	}
+
+	return &ContainerAction{
+		Config:      cfg.Container,
+		Method:      method,
+		StopTimeout: stopTimeout,
+	}, nil
+}
+
+func newHTTPAction(cfg config.Action, isFirstFailure bool) (Action, error) {
+	if cfg.HTTP == nil {
+		return nil, fmt.Errorf("http action config is nil")
+	}
+
+	method := strings.ToUpper(cfg.HTTP.Method)
+	if method == "" {
+		method = "POST"
+	}
+	timeout := time.Duration(cfg.HTTP.TimeoutSeconds) * time.Second
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &HTTPAction{
+		Config:  cfg.HTTP,
+		Method:  method,
+		Timeout: timeout,
+	}, nil
+}
@@ -0,0 +1,342 @@
+// Package approval mantiene la cola de acciones de recuperación pendientes de aprobación
+// (mode: approval). El engine encola la acción y un operador la aprueba o rechaza desde
+// la API del dashboard o el CLI antes de que venza su plazo.
+package approval
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/atomicfile"
+	"github.com/tgextreme/neon-watchdog/internal/logger"
+)
+
+// Status estado de una acción encolada
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusApproved  Status = "approved" // aprobada, ejecutándose
+	StatusRejected  Status = "rejected"
+	StatusExpired   Status = "expired"   // venció sin decisión
+	StatusCancelled Status = "cancelled" // el target se recuperó antes de decidir
+	StatusExecuted  Status = "executed"  // aprobada y ejecutada con éxito
+	StatusFailed    Status = "failed"    // aprobada pero la acción falló
+)
+
+// maxFinished acciones ya resueltas que se conservan para consulta
+const maxFinished = 100
+
+// Action acción de recuperación encolada
+type Action struct {
+	ID                  string    `json:"id"`
+	Target              string    `json:"target"`
+	Action              string    `json:"action"`
+	Reason              string    `json:"reason,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Diagnostics         string    `json:"diagnostics,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+	ExpiresAt           time.Time `json:"expires_at"`
+	Status              Status    `json:"status"`
+	DecidedBy           string    `json:"decided_by,omitempty"`
+	DecidedAt           time.Time `json:"decided_at,omitempty"`
+	Result              string    `json:"result,omitempty"`
+}
+
+// Handler recibe las decisiones de un operador (aprobada o rechazada)
+type Handler func(action Action)
+
+// Queue cola de acciones pendientes y resueltas recientemente
+type Queue struct {
+	mu      sync.Mutex
+	actions []*Action
+	handler Handler
+	file    string
+	log     *logger.Logger
+}
+
+// NewQueue crea la cola vacía. Con file la cola se guarda en cada cambio y se recupera
+// con Load, para que las acciones pendientes sobrevivan a un reinicio del daemon
+func NewQueue(file string, log *logger.Logger) *Queue {
+	if log == nil {
+		log = logger.New("ERROR", os.Stderr)
+	}
+	return &Queue{file: file, log: log}
+}
+
+// SetHandler configura quién ejecuta las acciones aprobadas y registra las rechazadas
+func (q *Queue) SetHandler(handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handler = handler
+}
+
+// Add encola una acción pendiente. Si el target ya tiene una pendiente o aprobada en
+// ejecución no se encola otra y se retorna la existente con false
+func (q *Queue) Add(action Action, timeout time.Duration) (Action, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if existing := q.pendingFor(action.Target); existing != nil {
+		return *existing, false
+	}
+
+	now := time.Now()
+	action.ID = newID()
+	action.CreatedAt = now
+	action.ExpiresAt = now.Add(timeout)
+	action.Status = StatusPending
+	q.actions = append(q.actions, &action)
+	q.trim()
+	q.save()
+	return action, true
+}
+
+// List retorna las acciones (pendientes y resueltas recientemente), las más antiguas primero
+func (q *Queue) List() []Action {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	list := make([]Action, 0, len(q.actions))
+	for _, a := range q.actions {
+		list = append(list, *a)
+	}
+	return list
+}
+
+// Get retorna una acción por ID
+func (q *Queue) Get(id string) (Action, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if a := q.find(id); a != nil {
+		return *a, true
+	}
+	return Action{}, false
+}
+
+// Pending retorna la acción pendiente (no vencida) o aprobada en ejecución del target
+func (q *Queue) Pending(target string) (Action, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if a := q.pendingFor(target); a != nil {
+		return *a, true
+	}
+	return Action{}, false
+}
+
+// Approve aprueba una acción pendiente y la entrega al handler para que la ejecute
+func (q *Queue) Approve(id, by string) (Action, error) {
+	return q.decide(id, by, StatusApproved)
+}
+
+// Reject rechaza una acción pendiente
+func (q *Queue) Reject(id, by string) (Action, error) {
+	return q.decide(id, by, StatusRejected)
+}
+
+func (q *Queue) decide(id, by string, status Status) (Action, error) {
+	q.mu.Lock()
+	a := q.find(id)
+	if a == nil {
+		q.mu.Unlock()
+		return Action{}, fmt.Errorf("approval %s not found", id)
+	}
+	if a.Status == StatusPending && time.Now().After(a.ExpiresAt) {
+		a.Status = StatusExpired
+	}
+	if a.Status != StatusPending {
+		current := *a
+		q.mu.Unlock()
+		return current, fmt.Errorf("approval %s is %s", id, current.Status)
+	}
+
+	a.Status = status
+	a.DecidedBy = by
+	a.DecidedAt = time.Now()
+	q.save()
+	decided := *a
+	handler := q.handler
+	q.mu.Unlock()
+
+	if handler != nil {
+		handler(decided)
+	}
+	return decided, nil
+}
+
+// Finish registra el resultado de una acción aprobada
+func (q *Queue) Finish(id string, success bool, result string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if a := q.find(id); a != nil {
+		a.Status = StatusFailed
+		if success {
+			a.Status = StatusExecuted
+		}
+		a.Result = result
+		q.save()
+	}
+}
+
+// Expire marca como vencidas las acciones pendientes fuera de plazo y las retorna
+func (q *Queue) Expire() []Action {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	var expired []Action
+	for _, a := range q.actions {
+		if a.Status == StatusPending && now.After(a.ExpiresAt) {
+			a.Status = StatusExpired
+			expired = append(expired, *a)
+		}
+	}
+	if len(expired) > 0 {
+		q.save()
+	}
+	return expired
+}
+
+// CancelTarget cancela la acción pendiente del target (por ejemplo, porque se recuperó)
+func (q *Queue) CancelTarget(target, reason string) (Action, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// Una acción aprobada ya está en ejecución y no se cancela
+	a := q.pendingFor(target)
+	if a == nil || a.Status != StatusPending {
+		return Action{}, false
+	}
+	a.Status = StatusCancelled
+	a.Result = reason
+	q.save()
+	return *a, true
+}
+
+func (q *Queue) find(id string) *Action {
+	for _, a := range q.actions {
+		if a.ID == id {
+			return a
+		}
+	}
+	return nil
+}
+
+// pendingFor acción del target todavía sin resolver: pendiente de decisión o aprobada y en
+// ejecución. Mientras exista no se encola otra, para no repetir la recuperación
+func (q *Queue) pendingFor(target string) *Action {
+	now := time.Now()
+	for _, a := range q.actions {
+		if a.Target != target {
+			continue
+		}
+		if a.Status == StatusApproved || a.Status == StatusPending && !now.After(a.ExpiresAt) {
+			return a
+		}
+	}
+	return nil
+}
+
+// trim descarta las acciones resueltas más antiguas por encima de maxFinished
+func (q *Queue) trim() {
+	finished := 0
+	for _, a := range q.actions {
+		if a.resolved() {
+			finished++
+		}
+	}
+
+	kept := q.actions[:0]
+	for _, a := range q.actions {
+		if a.resolved() && finished > maxFinished {
+			finished--
+			continue
+		}
+		kept = append(kept, a)
+	}
+	q.actions = kept
+}
+
+// Load carga la cola guardada sin modificar el archivo
+func (q *Queue) Load() error {
+	if q.file == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(q.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read approval queue: %w", err)
+	}
+
+	var actions []*Action
+	if err := json.Unmarshal(data, &actions); err != nil {
+		return fmt.Errorf("failed to unmarshal approval queue: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.actions = actions
+	return nil
+}
+
+// FailInterrupted da por fallidas las acciones aprobadas que estaban ejecutándose cuando
+// paró el daemon: no se sabe si llegaron a completarse. Solo debe llamarlo el daemon que
+// tiene el bloqueo de instancia
+func (q *Queue) FailInterrupted() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	changed := false
+	for _, a := range q.actions {
+		if a.Status == StatusApproved {
+			a.Status = StatusFailed
+			a.Result = "interrupted by watchdog restart"
+			changed = true
+		}
+	}
+	if changed {
+		q.save()
+	}
+}
+
+// save guarda la cola (con q.mu tomado). Un error no impide seguir: la cola en memoria es la buena
+func (q *Queue) save() {
+	if q.file == "" {
+		return
+	}
+
+	actions := q.actions
+	if actions == nil {
+		actions = []*Action{}
+	}
+	data, err := json.MarshalIndent(actions, "", "  ")
+	if err == nil {
+		err = atomicfile.Write(q.file, data, 0644)
+	}
+	if err != nil {
+		q.log.Error("failed to save approval queue", logger.Fields("error", err.Error()))
+	}
+}
+
+// resolved indica si la acción ya no está pendiente ni ejecutándose
+func (a *Action) resolved() bool {
+	return a.Status != StatusPending && a.Status != StatusApproved
+}
+
+// newID identificador corto para usar desde el CLI
+func newID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,86 @@
+// Package atomicfile escribe los archivos de estado (cola de aprobaciones, silencios, posición
+// de los checks de logs) sin dejarlos nunca a medias: archivo temporal con fsync, rename y
+// fsync del directorio.
+package atomicfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// options opciones de escritura
+type options struct {
+	backup bool
+}
+
+// Option modifica la escritura de Write
+type Option func(*options)
+
+// WithBackup conserva la versión anterior como path.bak para recuperarse de un archivo
+// corrupto. Con un hard link el archivo principal existe en todo momento; si el sistema de
+// archivos no los admite se mueve
+func WithBackup() Option {
+	return func(o *options) {
+		o.backup = true
+	}
+}
+
+// Write escribe data en path. Tras un corte de luz queda el archivo anterior o el nuevo
+// completo, nunca uno truncado
+func Write(path string, data []byte, perm os.FileMode, opts ...Option) error {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmpFile := path + ".tmp"
+	f, err := os.OpenFile(tmpFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	if o.backup {
+		backup := path + ".bak"
+		if err := os.Remove(backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to replace backup: %w", err)
+		}
+		if err := os.Link(path, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+			if err := os.Rename(path, backup); err != nil {
+				return fmt.Errorf("failed to keep backup: %w", err)
+			}
+		}
+	}
+
+	if err := os.Rename(tmpFile, path); err != nil {
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+
+	// fsync del directorio para que los rename sobrevivan a un corte de luz
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to sync directory: %w", err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync directory: %w", err)
+	}
+	return nil
+}
@@ -1,12 +1,10 @@
 package checks
 
 import (
-	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
-	"io"
 	"net"
-	"net/http"
 	"os"
 	"os/exec"
 	"strconv"
@@ -14,6 +12,9 @@ import (
 	"time"
 
 	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/heartbeat"
+	"github.com/tgextreme/neon-watchdog/internal/httpclient"
+	"github.com/tgextreme/neon-watchdog/internal/process"
 )
 
 // Result representa el resultado de un check
@@ -22,6 +23,81 @@ type Result struct {
 	Message   string
 	Latency   time.Duration
 	CheckType string
+	Details   map[string]interface{} // datos adicionales para notificaciones
+	Degraded  bool                   // el check pasa pero hay un problema a vigilar
+	Metrics   []Metric               // valores numéricos (ej: perfdata de plugins Nagios)
+
+	// Salida de checks que ejecutan procesos (command, script)
+	Stdout          string
+	Stderr          string
+	OutputTruncated bool
+	ExitCode        *int
+
+	Attempt int // intento que produjo el resultado (checks con retries)
+}
+
+// Status estado resumido de un resultado
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusDegraded Status = "degraded"
+	StatusFailed   Status = "failed"
+)
+
+// Status retorna el estado resumido del resultado
+func (r Result) Status() Status {
+	switch {
+	case !r.Success:
+		return StatusFailed
+	case r.Degraded:
+		return StatusDegraded
+	}
+	return StatusOK
+}
+
+// MarshalJSON representación estable del resultado usada en eventos, historial y dashboard
+func (r Result) MarshalJSON() ([]byte, error) {
+	attempt := r.Attempt
+	if attempt == 0 {
+		attempt = 1
+	}
+	return json.Marshal(struct {
+		Status          Status                 `json:"status"`
+		CheckType       string                 `json:"check_type"`
+		Message         string                 `json:"message"`
+		LatencyMs       int64                  `json:"latency_ms"`
+		Attempt         int                    `json:"attempt"`
+		ExitCode        *int                   `json:"exit_code,omitempty"`
+		Stdout          string                 `json:"stdout,omitempty"`
+		Stderr          string                 `json:"stderr,omitempty"`
+		OutputTruncated bool                   `json:"output_truncated,omitempty"`
+		Details         map[string]interface{} `json:"details,omitempty"`
+		Metrics         []Metric               `json:"metrics,omitempty"`
+	}{
+		Status:          r.Status(),
+		CheckType:       r.CheckType,
+		Message:         r.Message,
+		LatencyMs:       r.Latency.Milliseconds(),
+		Attempt:         attempt,
+		ExitCode:        r.ExitCode,
+		Stdout:          r.Stdout,
+		Stderr:          r.Stderr,
+		OutputTruncated: r.OutputTruncated,
+		Details:         r.Details,
+		Metrics:         r.Metrics,
+	})
+}
+
+// Metric valor numérico reportado por un check
+type Metric struct {
+	Name  string   `json:"name"`
+	Value float64  `json:"value"`
+	Unit  string   `json:"unit,omitempty"`
+	Warn  string   `json:"warn,omitempty"` // rango en formato Nagios
+	Crit  string   `json:"crit,omitempty"`
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
 }
 
 // Checker es la interfaz que implementan todos los checkers
@@ -185,6 +261,7 @@ func (c *TcpPortChecker) Check(ctx context.Context) Result {
 // CommandChecker ejecuta un comando y verifica su exit code
 type CommandChecker struct {
 	Command []string
+	Options config.ExecOptions
 }
 
 func (c *CommandChecker) Name() string {
@@ -203,54 +280,94 @@ func (c *CommandChecker) Check(ctx context.Context) Result {
 		}
 	}
 
-	cmd := exec.CommandContext(ctx, c.Command[0], c.Command[1:]...)
-	output, err := cmd.CombinedOutput()
-	latency := time.Since(start)
-
+	out, err := runCaptured(ctx, &c.Options, c.Command[0], c.Command[1:])
 	if err != nil {
-		outputStr := strings.TrimSpace(string(output))
-		if len(outputStr) > 200 {
-			outputStr = outputStr[:200] + "..."
-		}
 		return Result{
 			Success:   false,
-			Message:   fmt.Sprintf("command failed: %v (output: %s)", err, outputStr),
-			Latency:   latency,
+			Message:   fmt.Sprintf("command failed: %v", err),
+			Latency:   time.Since(start),
 			CheckType: "command",
 		}
 	}
 
+	result := out.result("command", time.Since(start))
+	if out.exitCode != 0 {
+		result.Message = fmt.Sprintf("command failed: exit status %d (output: %s)", out.exitCode, out.summary())
+		return result
+	}
+
+	result.Success = true
+	result.Message = "command succeeded"
+	return result
+}
+
+// capturedOutput salida de un proceso ejecutado por un check
+type capturedOutput struct {
+	stdout   *process.CappedBuffer
+	stderr   *process.CappedBuffer
+	exitCode int
+}
+
+// runCaptured ejecuta el proceso con sus opciones guardando stdout y stderr hasta
+// max_output_bytes cada uno. Solo retorna error si el proceso no pudo ejecutarse;
+// un exit code distinto de 0 no es error
+func runCaptured(ctx context.Context, opts *config.ExecOptions, path string, args []string) (*capturedOutput, error) {
+	out := &capturedOutput{
+		stdout: process.NewCappedBuffer(opts.MaxOutputBytes),
+		stderr: process.NewCappedBuffer(opts.MaxOutputBytes),
+	}
+
+	cmd, err := process.Command(ctx, opts, path, args...)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = out.stdout
+	cmd.Stderr = out.stderr
+	if err := cmd.Run(); err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return nil, err
+		}
+		out.exitCode = exitErr.ExitCode()
+	}
+	return out, nil
+}
+
+// result resultado base con la salida capturada; Success y Message los completa el checker
+func (o *capturedOutput) result(checkType string, latency time.Duration) Result {
+	exitCode := o.exitCode
 	return Result{
-		Success:   true,
-		Message:   "command succeeded",
-		Latency:   latency,
-		CheckType: "command",
+		Latency:         latency,
+		CheckType:       checkType,
+		Stdout:          o.stdout.String(),
+		Stderr:          o.stderr.String(),
+		OutputTruncated: o.stdout.Truncated || o.stderr.Truncated,
+		ExitCode:        &exitCode,
 	}
 }
 
+// summary salida combinada y recortada para el mensaje; la completa va en Stdout/Stderr
+func (o *capturedOutput) summary() string {
+	text := strings.TrimSpace(strings.TrimSpace(o.stdout.String()) + "\n" + strings.TrimSpace(o.stderr.String()))
+	if len(text) > 200 {
+		text = text[:200] + "..."
+	}
+	return text
+}
+
 // NewChecker crea un checker basado en la configuración
 func NewChecker(check config.Check) (Checker, error) {
-	switch check.Type {
-	case "process_name":
-		return &ProcessNameChecker{
-			ProcessName:     check.ProcessName,
-			IgnoreExitCodes: check.IgnoreExitCodes,
-		}, nil
-	case "pid_file":
-		return &PidFileChecker{PidFile: check.PidFile}, nil
-	case "tcp_port":
-		return &TcpPortChecker{Address: check.TcpPort}, nil
-	case "command":
-		return &CommandChecker{Command: check.Command}, nil
-	case "http":
-		return NewHTTPChecker(check.HTTP)
-	case "script":
-		return NewScriptChecker(check.Script)
-	case "logic":
-		return NewLogicChecker(check.Logic, check.Checks)
-	default:
+	factory, ok := lookupFactory(check.Type)
+	if !ok {
 		return nil, fmt.Errorf("unknown check type: %s", check.Type)
 	}
+	checker, err := factory(check)
+	if err != nil || check.Retries == 0 {
+		return checker, err
+	}
+	retry := NewRetryChecker(checker, check.Retries, time.Duration(check.RetryDelayMs)*time.Millisecond, check.MinSuccess)
+	retry.CheckType = check.Type
+	return retry, nil
 }
 
 // HTTPChecker verifica un endpoint HTTP
@@ -261,7 +378,7 @@ type HTTPChecker struct {
 	Headers        map[string]string
 	Body           string
 	Timeout        time.Duration
-	client         *http.Client
+	client         *httpclient.Client
 }
 
 // NewHTTPChecker crea un nuevo HTTP checker
@@ -285,6 +402,11 @@ func NewHTTPChecker(cfg *config.HTTPCheck) (*HTTPChecker, error) {
 		timeout = 5 * time.Second
 	}
 
+	client, err := httpclient.New(timeout, cfg.Auth, cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
 	return &HTTPChecker{
 		URL:            cfg.URL,
 		Method:         method,
@@ -292,9 +414,7 @@ func NewHTTPChecker(cfg *config.HTTPCheck) (*HTTPChecker, error) {
 		Headers:        cfg.Headers,
 		Body:           cfg.Body,
 		Timeout:        timeout,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		client:         client,
 	}, nil
 }
 
@@ -305,26 +425,12 @@ func (c *HTTPChecker) Name() string {
 func (c *HTTPChecker) Check(ctx context.Context) Result {
 	start := time.Now()
 
-	var bodyReader io.Reader
-	if c.Body != "" {
-		bodyReader = bytes.NewBufferString(c.Body)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, c.Method, c.URL, bodyReader)
-	if err != nil {
-		return Result{
-			Success:   false,
-			Message:   fmt.Sprintf("failed to create request: %v", err),
-			Latency:   time.Since(start),
-			CheckType: "http",
-		}
-	}
-
-	for key, value := range c.Headers {
-		req.Header.Set(key, value)
-	}
-
-	resp, err := c.client.Do(req)
+	resp, err := c.client.Do(ctx, httpclient.Request{
+		Method:  c.Method,
+		URL:     c.URL,
+		Headers: c.Headers,
+		Body:    c.Body,
+	})
 	latency := time.Since(start)
 
 	if err != nil {
@@ -335,7 +441,6 @@ func (c *HTTPChecker) Check(ctx context.Context) Result {
 			CheckType: "http",
 		}
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != c.ExpectedStatus {
 		return Result{
@@ -360,6 +465,7 @@ type ScriptChecker struct {
 	Args             []string
 	SuccessExitCodes []int
 	WarningExitCodes []int
+	Options          config.ExecOptions
 }
 
 // NewScriptChecker crea un nuevo script checker
@@ -378,6 +484,7 @@ func NewScriptChecker(cfg *config.ScriptCheck) (*ScriptChecker, error) {
 		Args:             cfg.Args,
 		SuccessExitCodes: successCodes,
 		WarningExitCodes: cfg.WarningExitCodes,
+		Options:          cfg.ExecOptions,
 	}, nil
 }
 
@@ -388,77 +495,63 @@ func (c *ScriptChecker) Name() string {
 func (c *ScriptChecker) Check(ctx context.Context) Result {
 	start := time.Now()
 
-	cmd := exec.CommandContext(ctx, c.Path, c.Args...)
-	output, err := cmd.CombinedOutput()
-	latency := time.Since(start)
-
-	exitCode := 0
+	out, err := runCaptured(ctx, &c.Options, c.Path, c.Args)
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			exitCode = exitErr.ExitCode()
-		} else {
-			return Result{
-				Success:   false,
-				Message:   fmt.Sprintf("failed to execute script: %v", err),
-				Latency:   latency,
-				CheckType: "script",
-			}
+		return Result{
+			Success:   false,
+			Message:   fmt.Sprintf("failed to execute script: %v", err),
+			Latency:   time.Since(start),
+			CheckType: "script",
 		}
 	}
 
+	result := out.result("script", time.Since(start))
+	exitCode := out.exitCode
+
 	// Verificar si es exit code de success
 	for _, code := range c.SuccessExitCodes {
 		if exitCode == code {
-			return Result{
-				Success:   true,
-				Message:   fmt.Sprintf("script succeeded (exit code: %d)", exitCode),
-				Latency:   latency,
-				CheckType: "script",
-			}
+			result.Success = true
+			result.Message = fmt.Sprintf("script succeeded (exit code: %d)", exitCode)
+			return result
 		}
 	}
 
 	// Verificar si es exit code de warning (no falla pero registra)
 	for _, code := range c.WarningExitCodes {
 		if exitCode == code {
-			return Result{
-				Success:   true,
-				Message:   fmt.Sprintf("script warning (exit code: %d): %s", exitCode, strings.TrimSpace(string(output))),
-				Latency:   latency,
-				CheckType: "script",
-			}
+			result.Success = true
+			result.Message = fmt.Sprintf("script warning (exit code: %d): %s", exitCode, out.summary())
+			return result
 		}
 	}
 
 	// Fallo
-	outputStr := strings.TrimSpace(string(output))
-	if len(outputStr) > 200 {
-		outputStr = outputStr[:200] + "..."
-	}
-	return Result{
-		Success:   false,
-		Message:   fmt.Sprintf("script failed (exit code: %d): %s", exitCode, outputStr),
-		Latency:   latency,
-		CheckType: "script",
-	}
+	result.Message = fmt.Sprintf("script failed (exit code: %d): %s", exitCode, out.summary())
+	return result
 }
 
-// LogicChecker combina múltiples checks con lógica AND/OR
+// LogicChecker combina múltiples checks con lógica AND/OR/QUORUM/AT_LEAST n.
+// Los sub-checks corren en paralelo y, con ShortCircuit, se cancelan en cuanto el
+// resultado está decidido (OR al primer éxito, AND al primer fallo)
 type LogicChecker struct {
-	Logic    string // AND o OR
-	Checkers []Checker
+	Logic        string // AND, OR, QUORUM, AT_LEAST n
+	Checkers     []Checker
+	MinPassing   int
+	ShortCircuit bool
 }
 
 // NewLogicChecker crea un nuevo logic checker
-func NewLogicChecker(logic string, checks []config.Check) (*LogicChecker, error) {
-	if logic != "AND" && logic != "OR" {
-		return nil, fmt.Errorf("logic must be AND or OR, got: %s", logic)
-	}
-
+func NewLogicChecker(logic string, checks []config.Check, shortCircuit bool) (*LogicChecker, error) {
 	if len(checks) == 0 {
 		return nil, fmt.Errorf("logic checker requires at least one check")
 	}
 
+	minPassing, err := config.LogicMinPassing(logic, len(checks))
+	if err != nil {
+		return nil, err
+	}
+
 	checkers := make([]Checker, 0, len(checks))
 	for _, check := range checks {
 		checker, err := NewChecker(check)
@@ -469,8 +562,10 @@ func NewLogicChecker(logic string, checks []config.Check) (*LogicChecker, error)
 	}
 
 	return &LogicChecker{
-		Logic:    logic,
-		Checkers: checkers,
+		Logic:        logic,
+		Checkers:     checkers,
+		MinPassing:   minPassing,
+		ShortCircuit: shortCircuit,
 	}, nil
 }
 
@@ -485,61 +580,178 @@ func (c *LogicChecker) Name() string {
 func (c *LogicChecker) Check(ctx context.Context) Result {
 	start := time.Now()
 
-	results := make([]Result, len(c.Checkers))
-	for i, checker := range c.Checkers {
-		results[i] = checker.Check(ctx)
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type indexed struct {
+		index  int
+		result Result
 	}
+	// Con buffer para que los sub-checks cancelados terminen sin bloquearse
+	done := make(chan indexed, len(c.Checkers))
+	for i, checker := range c.Checkers {
+		go func(i int, checker Checker) {
+			done <- indexed{index: i, result: checker.Check(runCtx)}
+		}(i, checker)
+	}
+
+	results := make([]*Result, len(c.Checkers))
+	passed, failed := 0, 0
+	for pending := len(c.Checkers); pending > 0; pending-- {
+		r := <-done
+		results[r.index] = &r.result
+		if r.result.Success {
+			passed++
+		} else {
+			failed++
+		}
 
-	latency := time.Since(start)
+		decided := passed >= c.MinPassing || passed+pending-1 < c.MinPassing
+		if c.ShortCircuit && decided {
+			cancel()
+			break
+		}
+	}
 
-	if c.Logic == "AND" {
-		// Todos deben pasar
-		for _, result := range results {
-			if !result.Success {
-				messages := make([]string, len(results))
-				for i, r := range results {
-					status := "✓"
-					if !r.Success {
-						status = "✗"
-					}
-					messages[i] = fmt.Sprintf("%s %s", status, r.Message)
-				}
-				return Result{
-					Success:   false,
-					Message:   fmt.Sprintf("AND logic failed: %s", strings.Join(messages, "; ")),
-					Latency:   latency,
-					CheckType: "logic",
-				}
+	children := make([]map[string]interface{}, len(c.Checkers))
+	failures := []string{}
+	for i, checker := range c.Checkers {
+		child := map[string]interface{}{"check": checker.Name()}
+		if r := results[i]; r != nil {
+			child["check_type"] = r.CheckType
+			child["success"] = r.Success
+			child["degraded"] = r.Degraded
+			child["message"] = r.Message
+			child["latency_ms"] = r.Latency.Milliseconds()
+			if len(r.Details) > 0 {
+				child["details"] = r.Details
 			}
+			if !r.Success {
+				failures = append(failures, r.Message)
+			}
+		} else {
+			child["cancelled"] = true
+		}
+		children[i] = child
+	}
+
+	result := Result{
+		Success:   passed >= c.MinPassing,
+		Latency:   time.Since(start),
+		CheckType: "logic",
+		Details: map[string]interface{}{
+			"logic":       c.Logic,
+			"min_passing": c.MinPassing,
+			"passed":      passed,
+			"failed":      failed,
+			"children":    children,
+		},
+	}
+
+	if result.Success {
+		result.Message = fmt.Sprintf("%s logic passed (%d/%d checks passed, %d required)", c.Logic, passed, len(c.Checkers), c.MinPassing)
+	} else {
+		result.Message = fmt.Sprintf("%s logic failed (%d/%d checks passed, %d required): %s", c.Logic, passed, len(c.Checkers), c.MinPassing, failures[0])
+	}
+	return result
+}
+
+// HeartbeatChecker evalúa los pings recibidos de un job (dead-man's switch)
+type HeartbeatChecker struct {
+	Token       string
+	Period      time.Duration
+	Grace       time.Duration
+	MaxDuration time.Duration
+	store       *heartbeat.Store
+}
+
+// NewHeartbeatChecker crea un nuevo heartbeat checker sobre el store compartido
+func NewHeartbeatChecker(cfg *config.HeartbeatCheck) (*HeartbeatChecker, error) {
+	if cfg == nil || cfg.Token == "" {
+		return nil, fmt.Errorf("heartbeat check requires token")
+	}
+	if cfg.PeriodSeconds <= 0 {
+		return nil, fmt.Errorf("heartbeat check requires period_seconds > 0")
+	}
+
+	store := heartbeat.Default()
+	store.Register(cfg.Token)
+
+	return &HeartbeatChecker{
+		Token:       cfg.Token,
+		Period:      time.Duration(cfg.PeriodSeconds) * time.Second,
+		Grace:       time.Duration(cfg.GraceSeconds) * time.Second,
+		MaxDuration: time.Duration(cfg.MaxDurationSeconds) * time.Second,
+		store:       store,
+	}, nil
+}
+
+func (c *HeartbeatChecker) Name() string {
+	return fmt.Sprintf("heartbeat:%s", c.Token)
+}
+
+func (c *HeartbeatChecker) Check(ctx context.Context) Result {
+	start := time.Now()
+
+	st, ok := c.store.Get(c.Token)
+	if !ok {
+		return Result{
+			Success:   false,
+			Message:   fmt.Sprintf("heartbeat '%s' not registered", c.Token),
+			Latency:   time.Since(start),
+			CheckType: "heartbeat",
 		}
+	}
+
+	now := time.Now()
+
+	// Job en curso que supera la duración máxima
+	if st.Running && c.MaxDuration > 0 && now.Sub(st.LastStart) > c.MaxDuration {
 		return Result{
-			Success:   true,
-			Message:   "all AND checks passed",
-			Latency:   latency,
-			CheckType: "logic",
+			Success:   false,
+			Message:   fmt.Sprintf("job running for %s, exceeds max duration %s", now.Sub(st.LastStart).Round(time.Second), c.MaxDuration),
+			Latency:   time.Since(start),
+			CheckType: "heartbeat",
 		}
 	}
 
-	// OR: al menos uno debe pasar
-	for _, result := range results {
-		if result.Success {
-			return Result{
-				Success:   true,
-				Message:   fmt.Sprintf("OR logic passed: %s", result.Message),
-				Latency:   latency,
-				CheckType: "logic",
-			}
+	// El último resultado reportado fue un fallo
+	if st.LastKind == heartbeat.KindFail {
+		return Result{
+			Success:   false,
+			Message:   fmt.Sprintf("job reported failure (exit code: %d)", st.LastExitCode),
+			Latency:   time.Since(start),
+			CheckType: "heartbeat",
+		}
+	}
+
+	// Sin éxito dentro de period + grace (desde el último éxito o desde el alta)
+	reference := st.LastSuccess
+	if reference.IsZero() {
+		reference = st.FirstSeen
+	}
+	if since := now.Sub(reference); since > c.Period+c.Grace {
+		return Result{
+			Success:   false,
+			Message:   fmt.Sprintf("no success ping for %s (period %s + grace %s)", since.Round(time.Second), c.Period, c.Grace),
+			Latency:   time.Since(start),
+			CheckType: "heartbeat",
 		}
 	}
 
-	messages := make([]string, len(results))
-	for i, r := range results {
-		messages[i] = r.Message
+	if st.LastSuccess.IsZero() {
+		return Result{
+			Success:   true,
+			Message:   "waiting for first success ping",
+			Latency:   time.Since(start),
+			CheckType: "heartbeat",
+		}
 	}
+
 	return Result{
-		Success:   false,
-		Message:   fmt.Sprintf("OR logic failed (all checks failed): %s", strings.Join(messages, "; ")),
-		Latency:   latency,
-		CheckType: "logic",
+		Success:   true,
+		Message:   fmt.Sprintf("last success ping %s ago", now.Sub(st.LastSuccess).Round(time.Second)),
+		Latency:   time.Since(start),
+		CheckType: "heartbeat",
 	}
 }
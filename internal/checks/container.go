@@ -0,0 +1,152 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/containers"
+)
+
+// ContainerChecker verifica estado, HEALTHCHECK y reinicios de contenedores vía Engine API
+type ContainerChecker struct {
+	cfg *config.ContainerCheck
+}
+
+// NewContainerChecker crea un nuevo container checker
+func NewContainerChecker(cfg *config.ContainerCheck) (*ContainerChecker, error) {
+	if cfg == nil || (cfg.Name == "" && len(cfg.Labels) == 0) {
+		return nil, fmt.Errorf("container check requires name or labels")
+	}
+	return &ContainerChecker{cfg: cfg}, nil
+}
+
+func (c *ContainerChecker) Name() string {
+	if c.cfg.Name != "" {
+		return fmt.Sprintf("container:%s", c.cfg.Name)
+	}
+	return fmt.Sprintf("container:%s", formatLabels(c.cfg.Labels))
+}
+
+func (c *ContainerChecker) Check(ctx context.Context) Result {
+	start := time.Now()
+	result := Result{CheckType: "container"}
+
+	client, err := containers.NewClient(c.cfg.Socket)
+	if err != nil {
+		result.Message = err.Error()
+		result.Latency = time.Since(start)
+		return result
+	}
+	defer client.Close()
+
+	found, err := client.Find(ctx, c.cfg.Name, c.cfg.Labels)
+	if err != nil {
+		result.Message = err.Error()
+		result.Latency = time.Since(start)
+		return result
+	}
+	if len(found) == 0 {
+		result.Message = fmt.Sprintf("no container matches %s", strings.TrimPrefix(c.Name(), "container:"))
+		result.Latency = time.Since(start)
+		return result
+	}
+
+	details := make(map[string]interface{})
+	failures := []string{}
+	warnings := []string{}
+	maxRestarts := 0
+
+	for _, ct := range found {
+		info, err := client.Inspect(ctx, ct.ID)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", ct.Name(), err))
+			continue
+		}
+
+		name := strings.TrimPrefix(info.Name, "/")
+		entry := map[string]interface{}{
+			"id":            shortID(info.ID),
+			"status":        info.State.Status,
+			"restart_count": info.RestartCount,
+		}
+		if info.State.Health != nil {
+			entry["health"] = info.State.Health.Status
+		}
+		details[name] = entry
+		if info.RestartCount > maxRestarts {
+			maxRestarts = info.RestartCount
+		}
+
+		switch {
+		case info.State.Restarting:
+			failures = append(failures, fmt.Sprintf("%s is restarting", name))
+			continue
+		case !info.State.Running:
+			reason := fmt.Sprintf("%s is %s (exit code %d)", name, info.State.Status, info.State.ExitCode)
+			if info.State.OOMKilled {
+				reason += ", OOM killed"
+			}
+			failures = append(failures, reason)
+			continue
+		}
+
+		if info.State.Health != nil && !c.cfg.IgnoreHealth {
+			switch info.State.Health.Status {
+			case "unhealthy":
+				reason := fmt.Sprintf("%s is unhealthy", name)
+				if logs := info.State.Health.Log; len(logs) > 0 {
+					if output := strings.TrimSpace(logs[len(logs)-1].Output); output != "" {
+						reason += ": " + output
+					}
+				}
+				failures = append(failures, reason)
+				continue
+			case "starting":
+				warnings = append(warnings, fmt.Sprintf("%s health is starting", name))
+			}
+		}
+
+		if c.cfg.MaxRestartCount > 0 && info.RestartCount > c.cfg.MaxRestartCount {
+			warnings = append(warnings, fmt.Sprintf("%s restarted %d times (> %d)", name, info.RestartCount, c.cfg.MaxRestartCount))
+		}
+	}
+
+	result.Details = details
+	result.Metrics = []Metric{{Name: "restart_count", Value: float64(maxRestarts)}}
+	result.Latency = time.Since(start)
+
+	switch {
+	case len(failures) > 0:
+		result.Message = strings.Join(failures, "; ")
+	case len(warnings) > 0:
+		result.Success = true
+		result.Degraded = true
+		result.Message = strings.Join(warnings, "; ")
+	default:
+		result.Success = true
+		result.Message = fmt.Sprintf("%d container(s) running", len(found))
+	}
+	return result
+}
+
+// formatLabels representa las labels como k=v ordenadas
+func formatLabels(labels map[string]string) string {
+	parts := make([]string, 0, len(labels))
+	for k, v := range labels {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// shortID ID abreviado como lo muestra docker ps
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,91 @@
+package checks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/containers"
+	"github.com/tgextreme/neon-watchdog/internal/containers/containerstest"
+)
+
+// runningContainer contenedor arrancado con el estado de HEALTHCHECK indicado ("" = sin healthcheck)
+func runningContainer(id, name, health string) *containerstest.Container {
+	ct := &containerstest.Container{ID: id, Name: name, Labels: map[string]string{"app": "shop"}}
+	ct.State = containers.State{Status: "running", Running: true}
+	if health != "" {
+		ct.State.Health = &containers.Health{Status: health}
+	}
+	return ct
+}
+
+func newContainerTestChecker(t *testing.T, cfg *config.ContainerCheck) *ContainerChecker {
+	t.Helper()
+	checker, err := NewContainerChecker(cfg)
+	if err != nil {
+		t.Fatalf("NewContainerChecker: %v", err)
+	}
+	return checker
+}
+
+func TestContainerRunning(t *testing.T) {
+	srv := containerstest.NewServer(t, runningContainer("aaa", "web", "healthy"), runningContainer("bbb", "worker", ""))
+
+	result := runCheck(t, newContainerTestChecker(t, &config.ContainerCheck{Socket: srv.Socket, Labels: map[string]string{"app": "shop"}}))
+	expectHealthy(t, result)
+	if result.Message != "2 container(s) running" {
+		t.Fatalf("unexpected message %q", result.Message)
+	}
+	web := result.Details["web"].(map[string]interface{})
+	if web["health"] != "healthy" || web["status"] != "running" {
+		t.Fatalf("unexpected details: %+v", web)
+	}
+
+	result = runCheck(t, newContainerTestChecker(t, &config.ContainerCheck{Socket: srv.Socket, Name: "missing"}))
+	expectFailure(t, result, "no container matches missing")
+}
+
+func TestContainerHealth(t *testing.T) {
+	unhealthy := runningContainer("aaa", "web", "unhealthy")
+	if err := json.Unmarshal([]byte(`[{"ExitCode": 1, "Output": "curl: (7) connection refused\n"}]`), &unhealthy.State.Health.Log); err != nil {
+		t.Fatal(err)
+	}
+	srv := containerstest.NewServer(t, unhealthy, runningContainer("bbb", "api", "starting"))
+
+	result := runCheck(t, newContainerTestChecker(t, &config.ContainerCheck{Socket: srv.Socket, Name: "web"}))
+	expectFailure(t, result, "web is unhealthy: curl: (7) connection refused")
+
+	// ignore_health solo evalúa que el contenedor esté en marcha
+	expectHealthy(t, runCheck(t, newContainerTestChecker(t, &config.ContainerCheck{Socket: srv.Socket, Name: "web", IgnoreHealth: true})))
+
+	result = runCheck(t, newContainerTestChecker(t, &config.ContainerCheck{Socket: srv.Socket, Name: "api"}))
+	expectDegraded(t, result, "api health is starting")
+}
+
+func TestContainerStopped(t *testing.T) {
+	exited := &containerstest.Container{ID: "aaa", Name: "web"}
+	exited.State = containers.State{Status: "exited", ExitCode: 137, OOMKilled: true}
+	restarting := &containerstest.Container{ID: "bbb", Name: "worker"}
+	restarting.State = containers.State{Status: "restarting", Restarting: true}
+	srv := containerstest.NewServer(t, exited, restarting)
+
+	result := runCheck(t, newContainerTestChecker(t, &config.ContainerCheck{Socket: srv.Socket, Name: "web"}))
+	expectFailure(t, result, "web is exited (exit code 137), OOM killed")
+
+	result = runCheck(t, newContainerTestChecker(t, &config.ContainerCheck{Socket: srv.Socket, Name: "worker"}))
+	expectFailure(t, result, "worker is restarting")
+}
+
+func TestContainerRestartCount(t *testing.T) {
+	ct := runningContainer("aaa", "web", "")
+	ct.RestartCount = 12
+	srv := containerstest.NewServer(t, ct)
+
+	result := runCheck(t, newContainerTestChecker(t, &config.ContainerCheck{Socket: srv.Socket, Name: "web", MaxRestartCount: 5}))
+	expectDegraded(t, result, "web restarted 12 times (> 5)")
+	if len(result.Metrics) != 1 || result.Metrics[0].Value != 12 {
+		t.Fatalf("expected restart_count metric 12, got %+v", result.Metrics)
+	}
+
+	expectHealthy(t, runCheck(t, newContainerTestChecker(t, &config.ContainerCheck{Socket: srv.Socket, Name: "web", MaxRestartCount: 20})))
+}
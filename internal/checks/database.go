@@ -0,0 +1,192 @@
+package checks
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// dbStatus datos obtenidos por la sonda de una base de datos
+type dbStatus struct {
+	Value          string   // primera columna de la primera fila (o respuesta del comando)
+	Role           string   // primary, replica
+	Lag            *float64 // segundos de retraso de replicación (solo réplicas)
+	Connections    int
+	MaxConnections int
+}
+
+// dbConfig configuración resuelta común a postgres, mysql y redis
+type dbConfig struct {
+	*config.DatabaseCheck
+	network  string // tcp, unix
+	address  string
+	password string
+	timeout  time.Duration
+}
+
+// newDBConfig resuelve dirección, credenciales y timeout
+func newDBConfig(checkType string, cfg *config.DatabaseCheck, defaultPort string) (*dbConfig, error) {
+	if cfg == nil || cfg.Address == "" {
+		return nil, fmt.Errorf("%s check requires address", checkType)
+	}
+
+	network, address := "tcp", cfg.Address
+	if strings.HasPrefix(address, "/") {
+		network = "unix"
+	} else if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, defaultPort)
+	}
+
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &dbConfig{
+		DatabaseCheck: cfg,
+		network:       network,
+		address:       address,
+		timeout:       timeout,
+	}, nil
+}
+
+// resolvePassword lee la contraseña del archivo o de la variable de entorno configurada
+func (c *dbConfig) resolvePassword() error {
+	switch {
+	case c.PasswordFile != "":
+		data, err := os.ReadFile(c.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("cannot read password_file: %w", err)
+		}
+		c.password = strings.TrimRight(string(data), "\r\n")
+	case c.PasswordEnv != "":
+		value, ok := os.LookupEnv(c.PasswordEnv)
+		if !ok {
+			return fmt.Errorf("environment variable %s is not set", c.PasswordEnv)
+		}
+		c.password = value
+	}
+	return nil
+}
+
+// dial abre la conexión con el deadline de la sonda
+func (c *dbConfig) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %s: %w", c.address, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	return conn, nil
+}
+
+// tlsConfig configuración TLS del cliente para la conexión
+func (c *dbConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+	if host, _, err := net.SplitHostPort(c.address); err == nil {
+		tlsConfig.ServerName = host
+	}
+
+	if c.CACert != "" {
+		pem, err := os.ReadFile(c.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read ca_cert: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", c.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// startTLS envuelve la conexión en TLS y completa el handshake
+func (c *dbConfig) startTLS(ctx context.Context, conn net.Conn) (net.Conn, error) {
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, fmt.Errorf("TLS handshake failed: %w", err)
+	}
+	return tlsConn, nil
+}
+
+// wantsRole indica si hay que consultar rol/replicación
+func (c *dbConfig) wantsRole() bool {
+	return c.ExpectedRole != "" || c.MaxReplicationLagSeconds > 0
+}
+
+// evaluateDatabase compara lo obtenido por la sonda con las expectativas configuradas.
+// Un resultado inesperado o un rol distinto es fallo; replicación o conexiones por encima
+// de su límite dejan el check degradado
+func evaluateDatabase(checkType, label string, cfg *dbConfig, expect string, st *dbStatus, latency time.Duration) Result {
+	details := map[string]interface{}{"value": st.Value}
+	metrics := []Metric{}
+	if st.Role != "" {
+		details["role"] = st.Role
+	}
+	if st.Lag != nil {
+		details["replication_lag_seconds"] = *st.Lag
+		metrics = append(metrics, Metric{Name: "replication_lag", Value: *st.Lag, Unit: "s"})
+	}
+	if st.MaxConnections > 0 {
+		details["connections"] = st.Connections
+		details["max_connections"] = st.MaxConnections
+		metrics = append(metrics, Metric{Name: "connections", Value: float64(st.Connections)})
+	}
+
+	result := Result{
+		Success:   true,
+		Latency:   latency,
+		CheckType: checkType,
+		Details:   details,
+		Metrics:   metrics,
+	}
+
+	if expect != "" && st.Value != expect {
+		result.Success = false
+		result.Message = fmt.Sprintf("%s probe returned %q, expected %q", label, st.Value, expect)
+		return result
+	}
+
+	if cfg.ExpectedRole != "" && st.Role != cfg.ExpectedRole {
+		result.Success = false
+		result.Message = fmt.Sprintf("%s is %s, expected %s", label, st.Role, cfg.ExpectedRole)
+		return result
+	}
+
+	warnings := []string{}
+	if cfg.MaxReplicationLagSeconds > 0 && st.Lag != nil && *st.Lag > cfg.MaxReplicationLagSeconds {
+		warnings = append(warnings, fmt.Sprintf("replication lag %.1fs > %.1fs", *st.Lag, cfg.MaxReplicationLagSeconds))
+	}
+	if cfg.MaxConnectionsPercent > 0 && st.MaxConnections > 0 {
+		percent := float64(st.Connections) / float64(st.MaxConnections) * 100
+		if percent >= cfg.MaxConnectionsPercent {
+			warnings = append(warnings, fmt.Sprintf("connections %d/%d (%.0f%% >= %.0f%%)", st.Connections, st.MaxConnections, percent, cfg.MaxConnectionsPercent))
+		}
+	}
+
+	if len(warnings) > 0 {
+		result.Degraded = true
+		result.Message = fmt.Sprintf("%s: %s", label, strings.Join(warnings, ", "))
+		return result
+	}
+
+	result.Message = fmt.Sprintf("%s healthy", label)
+	if st.Role != "" {
+		result.Message = fmt.Sprintf("%s healthy (%s)", label, st.Role)
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package checks
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeServer escucha en un puerto local y atiende cada conexión con handler
+func fakeServer(t *testing.T, handler func(conn net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handler(conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// runCheck ejecuta el checker y retorna su resultado
+func runCheck(t *testing.T, checker Checker) Result {
+	t.Helper()
+	return checker.Check(context.Background())
+}
+
+func expectHealthy(t *testing.T, result Result) {
+	t.Helper()
+	if !result.Success || result.Degraded {
+		t.Fatalf("expected healthy result, got success=%v degraded=%v: %s", result.Success, result.Degraded, result.Message)
+	}
+}
+
+func expectDegraded(t *testing.T, result Result, contains string) {
+	t.Helper()
+	if !result.Success || !result.Degraded {
+		t.Fatalf("expected degraded result, got success=%v degraded=%v: %s", result.Success, result.Degraded, result.Message)
+	}
+	if !strings.Contains(result.Message, contains) {
+		t.Fatalf("expected message to contain %q, got %q", contains, result.Message)
+	}
+}
+
+func expectFailure(t *testing.T, result Result, contains string) {
+	t.Helper()
+	if result.Success {
+		t.Fatalf("expected failure, got success: %s", result.Message)
+	}
+	if !strings.Contains(result.Message, contains) {
+		t.Fatalf("expected message to contain %q, got %q", contains, result.Message)
+	}
+}
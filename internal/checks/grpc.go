@@ -0,0 +1,339 @@
+package checks
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// Ruta del método Check del servicio estándar de health de gRPC
+const grpcHealthPath = "/grpc.health.v1.Health/Check"
+
+// Valores de HealthCheckResponse.ServingStatus
+const (
+	grpcUnknown        = 0
+	grpcServing        = 1
+	grpcNotServing     = 2
+	grpcServiceUnknown = 3
+)
+
+var grpcServingStatusNames = map[uint64]string{
+	grpcUnknown:        "UNKNOWN",
+	grpcServing:        "SERVING",
+	grpcNotServing:     "NOT_SERVING",
+	grpcServiceUnknown: "SERVICE_UNKNOWN",
+}
+
+// Códigos grpc-status relevantes
+const (
+	grpcCodeOK            = 0
+	grpcCodeNotFound      = 5
+	grpcCodeUnimplemented = 12
+)
+
+// GRPCChecker llama a grpc.health.v1.Health/Check sobre HTTP/2
+type GRPCChecker struct {
+	Address        string
+	Service        string
+	Metadata       map[string]string
+	Timeout        time.Duration
+	TreatUnknownAs string
+	scheme         string
+	transport      *http.Transport
+}
+
+// NewGRPCChecker crea un nuevo gRPC health checker
+func NewGRPCChecker(cfg *config.GRPCCheck) (*GRPCChecker, error) {
+	if cfg == nil || cfg.Address == "" {
+		return nil, fmt.Errorf("grpc check requires address")
+	}
+
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	treatUnknownAs := cfg.TreatUnknownAs
+	if treatUnknownAs == "" {
+		treatUnknownAs = "critical"
+	}
+
+	transport := &http.Transport{Protocols: new(http.Protocols)}
+	scheme := "http"
+	if cfg.TLS {
+		tlsConfig, err := grpcTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+		transport.Protocols.SetHTTP2(true)
+		scheme = "https"
+	} else {
+		// gRPC sin TLS usa HTTP/2 con conocimiento previo (h2c)
+		transport.Protocols.SetUnencryptedHTTP2(true)
+	}
+
+	return &GRPCChecker{
+		Address:        cfg.Address,
+		Service:        cfg.Service,
+		Metadata:       cfg.Metadata,
+		Timeout:        timeout,
+		TreatUnknownAs: treatUnknownAs,
+		scheme:         scheme,
+		transport:      transport,
+	}, nil
+}
+
+// grpcTLSConfig construye la configuración TLS/mTLS del cliente
+func grpcTLSConfig(cfg *config.GRPCCheck) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CACert != "" {
+		pem, err := os.ReadFile(cfg.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read ca_cert: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func (c *GRPCChecker) Name() string {
+	if c.Service == "" {
+		return fmt.Sprintf("grpc:%s", c.Address)
+	}
+	return fmt.Sprintf("grpc:%s/%s", c.Address, c.Service)
+}
+
+func (c *GRPCChecker) Check(ctx context.Context) Result {
+	start := time.Now()
+	defer c.transport.CloseIdleConnections()
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	status, err := c.call(ctx)
+	latency := time.Since(start)
+	if err != nil {
+		return Result{
+			Success:   false,
+			Message:   err.Error(),
+			Latency:   latency,
+			CheckType: "grpc",
+		}
+	}
+
+	name, ok := grpcServingStatusNames[status]
+	if !ok {
+		name = strconv.FormatUint(status, 10)
+	}
+
+	result := Result{
+		Success:   true,
+		Message:   fmt.Sprintf("%s %s", c.serviceLabel(), name),
+		Latency:   latency,
+		CheckType: "grpc",
+		Details:   map[string]interface{}{"serving_status": name},
+	}
+
+	switch status {
+	case grpcServing:
+	case grpcUnknown:
+		if c.TreatUnknownAs == "warning" {
+			result.Degraded = true
+		} else {
+			result.Success = false
+		}
+	default:
+		result.Success = false
+	}
+
+	return result
+}
+
+// serviceLabel nombre del servicio para los mensajes
+func (c *GRPCChecker) serviceLabel() string {
+	if c.Service == "" {
+		return fmt.Sprintf("%s (server)", c.Address)
+	}
+	return fmt.Sprintf("%s/%s", c.Address, c.Service)
+}
+
+// call realiza la llamada unaria y retorna el ServingStatus de la respuesta
+func (c *GRPCChecker) call(ctx context.Context) (uint64, error) {
+	target := url.URL{Scheme: c.scheme, Host: c.Address, Path: grpcHealthPath}
+	body := grpcFrame(encodeHealthCheckRequest(c.Service))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range c.Metadata {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Header.Set("Grpc-Timeout", fmt.Sprintf("%dm", time.Until(deadline).Milliseconds()))
+	}
+
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return 0, fmt.Errorf("grpc call failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected HTTP status %d from %s", resp.StatusCode, c.Address)
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return 0, fmt.Errorf("error reading grpc response: %w", err)
+	}
+
+	// grpc-status llega en los trailers, o en las cabeceras si la respuesta es "trailers-only"
+	code, message := resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	if code == "" {
+		code, message = resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	}
+	if err := grpcStatusError(code, message, c.Service); err != nil {
+		return 0, err
+	}
+
+	msg, err := parseGRPCFrame(payload)
+	if err != nil {
+		return 0, err
+	}
+	return decodeHealthCheckResponse(msg)
+}
+
+// grpcStatusError traduce un grpc-status distinto de OK
+func grpcStatusError(code, message, service string) error {
+	if code == "" {
+		return fmt.Errorf("grpc response without grpc-status")
+	}
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return fmt.Errorf("invalid grpc-status: %s", code)
+	}
+	if decoded, err := url.PathUnescape(message); err == nil {
+		message = decoded
+	}
+
+	switch n {
+	case grpcCodeOK:
+		return nil
+	case grpcCodeNotFound:
+		return fmt.Errorf("service '%s' unknown to health server", service)
+	case grpcCodeUnimplemented:
+		return fmt.Errorf("server does not implement grpc.health.v1.Health")
+	}
+	return fmt.Errorf("grpc error code %d: %s", n, message)
+}
+
+// grpcFrame añade la cabecera de mensaje gRPC: 1 byte de compresión + 4 bytes de longitud
+func grpcFrame(msg []byte) []byte {
+	frame := make([]byte, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(msg)))
+	copy(frame[5:], msg)
+	return frame
+}
+
+// parseGRPCFrame extrae el primer mensaje de un cuerpo gRPC
+func parseGRPCFrame(data []byte) ([]byte, error) {
+	if len(data) < 5 {
+		return nil, fmt.Errorf("grpc response too short (%d bytes)", len(data))
+	}
+	if data[0] != 0 {
+		return nil, fmt.Errorf("compressed grpc responses are not supported")
+	}
+	size := binary.BigEndian.Uint32(data[1:5])
+	if uint32(len(data)-5) < size {
+		return nil, fmt.Errorf("truncated grpc message")
+	}
+	return data[5 : 5+size], nil
+}
+
+// encodeHealthCheckRequest codifica HealthCheckRequest{service = 1}
+func encodeHealthCheckRequest(service string) []byte {
+	if service == "" {
+		return nil
+	}
+	msg := []byte{0x0a} // campo 1, tipo length-delimited
+	msg = binary.AppendUvarint(msg, uint64(len(service)))
+	return append(msg, service...)
+}
+
+// decodeHealthCheckResponse lee HealthCheckResponse{status = 1}; un campo ausente vale UNKNOWN
+func decodeHealthCheckResponse(msg []byte) (uint64, error) {
+	status := uint64(grpcUnknown)
+
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return 0, fmt.Errorf("invalid protobuf field key")
+		}
+		msg = msg[n:]
+		field, wireType := key>>3, key&0x7
+
+		switch wireType {
+		case 0: // varint
+			v, n := binary.Uvarint(msg)
+			if n <= 0 {
+				return 0, fmt.Errorf("invalid protobuf varint")
+			}
+			msg = msg[n:]
+			if field == 1 {
+				status = v
+			}
+		case 1: // 64 bits
+			if len(msg) < 8 {
+				return 0, fmt.Errorf("truncated protobuf field")
+			}
+			msg = msg[8:]
+		case 2: // length-delimited
+			l, n := binary.Uvarint(msg)
+			if n <= 0 || uint64(len(msg)-n) < l {
+				return 0, fmt.Errorf("truncated protobuf field")
+			}
+			msg = msg[n+int(l):]
+		case 5: // 32 bits
+			if len(msg) < 4 {
+				return 0, fmt.Errorf("truncated protobuf field")
+			}
+			msg = msg[4:]
+		default:
+			return 0, fmt.Errorf("unsupported protobuf wire type %d", wireType)
+		}
+	}
+
+	return status, nil
+}
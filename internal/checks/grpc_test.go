@@ -0,0 +1,154 @@
+package checks
+
+import (
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// fakeHealthServer implementa grpc.health.v1.Health/Check sobre HTTP/2. Los servicios
+// que no están en statuses responden NOT_FOUND, como el servidor de health de grpc-go
+type fakeHealthServer struct {
+	statuses map[string]uint64
+	delay    time.Duration
+}
+
+func (f *fakeHealthServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != grpcHealthPath || r.Header.Get("Content-Type") != "application/grpc" {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", strconv.Itoa(grpcCodeUnimplemented))
+		return
+	}
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	msg, err := parseGRPCFrame(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	service := ""
+	if len(msg) > 0 && msg[0] == 0x0a {
+		size, n := binary.Uvarint(msg[1:])
+		service = string(msg[1+n : 1+n+int(size)])
+	}
+
+	w.Header().Set("Content-Type", "application/grpc")
+	status, ok := f.statuses[service]
+	if !ok {
+		// Respuesta "trailers-only": grpc-status en las cabeceras
+		w.Header().Set("Grpc-Status", strconv.Itoa(grpcCodeNotFound))
+		w.Header().Set("Grpc-Message", "unknown%20service")
+		return
+	}
+
+	w.Header().Set("Trailer", "Grpc-Status")
+	w.Write(grpcFrame(binary.AppendUvarint([]byte{0x08}, status)))
+	w.Header().Set("Grpc-Status", "0")
+}
+
+// newH2CServer arranca el servidor falso con HTTP/2 sin TLS
+func newH2CServer(t *testing.T, handler http.Handler) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(handler)
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newGRPCTestChecker(t *testing.T, cfg *config.GRPCCheck) *GRPCChecker {
+	t.Helper()
+	checker, err := NewGRPCChecker(cfg)
+	if err != nil {
+		t.Fatalf("NewGRPCChecker: %v", err)
+	}
+	return checker
+}
+
+func TestGRPCServingStatus(t *testing.T) {
+	srv := newH2CServer(t, &fakeHealthServer{statuses: map[string]uint64{
+		"":        grpcServing,
+		"api":     grpcServing,
+		"backend": grpcNotServing,
+		"cache":   grpcUnknown,
+	}})
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	result := runCheck(t, newGRPCTestChecker(t, &config.GRPCCheck{Address: addr}))
+	expectHealthy(t, result)
+	if result.Details["serving_status"] != "SERVING" {
+		t.Fatalf("expected SERVING, got %v", result.Details["serving_status"])
+	}
+
+	expectHealthy(t, runCheck(t, newGRPCTestChecker(t, &config.GRPCCheck{Address: addr, Service: "api"})))
+	expectFailure(t, runCheck(t, newGRPCTestChecker(t, &config.GRPCCheck{Address: addr, Service: "backend"})), "backend NOT_SERVING")
+
+	expectFailure(t, runCheck(t, newGRPCTestChecker(t, &config.GRPCCheck{Address: addr, Service: "cache"})), "cache UNKNOWN")
+	result = runCheck(t, newGRPCTestChecker(t, &config.GRPCCheck{Address: addr, Service: "cache", TreatUnknownAs: "warning"}))
+	if !result.Success || !result.Degraded {
+		t.Fatalf("expected UNKNOWN to be degraded with treat_unknown_as=warning, got %+v", result)
+	}
+}
+
+func TestGRPCUnknownService(t *testing.T) {
+	srv := newH2CServer(t, &fakeHealthServer{statuses: map[string]uint64{"": grpcServing}})
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	result := runCheck(t, newGRPCTestChecker(t, &config.GRPCCheck{Address: addr, Service: "missing"}))
+	expectFailure(t, result, "service 'missing' unknown to health server")
+}
+
+func TestGRPCTLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(&fakeHealthServer{statuses: map[string]uint64{"": grpcServing}})
+	srv.EnableHTTP2 = true
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0) // el intento sin CA registra el handshake fallido
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	addr := strings.TrimPrefix(srv.URL, "https://")
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	expectHealthy(t, runCheck(t, newGRPCTestChecker(t, &config.GRPCCheck{Address: addr, TLS: true, CACert: caFile})))
+
+	// Sin la CA el certificado del servidor no se acepta
+	result := runCheck(t, newGRPCTestChecker(t, &config.GRPCCheck{Address: addr, TLS: true}))
+	expectFailure(t, result, "grpc call failed")
+}
+
+func TestGRPCTimeout(t *testing.T) {
+	srv := newH2CServer(t, &fakeHealthServer{statuses: map[string]uint64{"": grpcServing}, delay: 5 * time.Second})
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	checker := newGRPCTestChecker(t, &config.GRPCCheck{Address: addr})
+	checker.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	result := runCheck(t, checker)
+	expectFailure(t, result, "context deadline exceeded")
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("check did not honour its timeout: took %s", elapsed)
+	}
+}
@@ -0,0 +1,262 @@
+package checks
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// Rutas de /proc con las métricas del host
+const (
+	procPressureDir = "/proc/pressure"
+	procLoadavg     = "/proc/loadavg"
+	procMeminfo     = "/proc/meminfo"
+)
+
+// ReadHostMetrics lee PSI, carga normalizada por CPU y memoria disponible del host.
+// Las métricas de PSI se omiten si el kernel no las soporta
+func ReadHostMetrics() (map[string]float64, error) {
+	metrics := make(map[string]float64)
+
+	for _, resource := range []string{"cpu", "memory", "io"} {
+		if err := readPressure(resource, metrics); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	if err := readLoadavg(metrics); err != nil {
+		return nil, err
+	}
+	if err := readMeminfo(metrics); err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+// readPressure parsea /proc/pressure/<resource>: "some avg10=0.00 avg60=0.00 avg300=0.00 total=0"
+func readPressure(resource string, metrics map[string]float64) error {
+	f, err := os.Open(procPressureDir + "/" + resource)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		kind := fields[0]
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok || !strings.HasPrefix(key, "avg") {
+				continue
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			metrics[fmt.Sprintf("%s_%s_%s", resource, kind, key)] = v
+		}
+	}
+	return scanner.Err()
+}
+
+// readLoadavg lee /proc/loadavg y normaliza por número de CPUs
+func readLoadavg(metrics map[string]float64) error {
+	data, err := os.ReadFile(procLoadavg)
+	if err != nil {
+		return fmt.Errorf("cannot read loadavg: %w", err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return fmt.Errorf("unexpected loadavg format")
+	}
+
+	cpus := float64(runtime.NumCPU())
+	for i, name := range []string{"load1_per_cpu", "load5_per_cpu", "load15_per_cpu"} {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return fmt.Errorf("invalid loadavg value: %s", fields[i])
+		}
+		metrics[name] = v / cpus
+	}
+	return nil
+}
+
+// readMeminfo lee MemTotal y MemAvailable de /proc/meminfo
+func readMeminfo(metrics map[string]float64) error {
+	f, err := os.Open(procMeminfo)
+	if err != nil {
+		return fmt.Errorf("cannot read meminfo: %w", err)
+	}
+	defer f.Close()
+
+	var total, available float64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		v, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total = v
+		case "MemAvailable:":
+			available = v
+		}
+	}
+
+	if total == 0 {
+		return fmt.Errorf("MemTotal not found in meminfo")
+	}
+	metrics["mem_available_mb"] = available / 1024
+	metrics["mem_available_percent"] = available / total * 100
+	return nil
+}
+
+// lowerIsWorse indica si la métrica empeora al bajar (memoria disponible)
+func lowerIsWorse(name string) bool {
+	return strings.HasPrefix(name, "mem_available")
+}
+
+// HostMetricExceeds indica si un valor alcanza el límite en la dirección "mala" de la métrica
+func HostMetricExceeds(name string, value, limit float64) bool {
+	if lowerIsWorse(name) {
+		return value <= limit
+	}
+	return value >= limit
+}
+
+// HostUnderPressure evalúa los límites de una política; retorna las métricas que los superan
+func HostUnderPressure(limits map[string]float64) ([]string, error) {
+	if len(limits) == 0 {
+		return nil, nil
+	}
+
+	metrics, err := ReadHostMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	exceeded := []string{}
+	for name, limit := range limits {
+		value, ok := metrics[name]
+		if ok && HostMetricExceeds(name, value, limit) {
+			exceeded = append(exceeded, fmt.Sprintf("%s=%.2f (limit %.2f)", name, value, limit))
+		}
+	}
+	sort.Strings(exceeded)
+	return exceeded, nil
+}
+
+// HostChecker evalúa la presión del host contra umbrales de warning/critical
+type HostChecker struct {
+	Thresholds map[string]config.Threshold
+}
+
+// NewHostChecker crea un nuevo host checker
+func NewHostChecker(cfg *config.HostCheck) (*HostChecker, error) {
+	if cfg == nil || len(cfg.Thresholds) == 0 {
+		return nil, fmt.Errorf("host check requires thresholds")
+	}
+	for name := range cfg.Thresholds {
+		if !config.ValidHostMetric(name) {
+			return nil, fmt.Errorf("invalid host metric: %s", name)
+		}
+	}
+	return &HostChecker{Thresholds: cfg.Thresholds}, nil
+}
+
+func (c *HostChecker) Name() string {
+	names := make([]string, 0, len(c.Thresholds))
+	for name := range c.Thresholds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("host:%s", strings.Join(names, ","))
+}
+
+func (c *HostChecker) Check(ctx context.Context) Result {
+	start := time.Now()
+
+	metrics, err := ReadHostMetrics()
+	if err != nil {
+		return Result{
+			Success:   false,
+			Message:   fmt.Sprintf("cannot read host metrics: %v", err),
+			Latency:   time.Since(start),
+			CheckType: "host",
+		}
+	}
+
+	names := make([]string, 0, len(c.Thresholds))
+	for name := range c.Thresholds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	critical := []string{}
+	warning := []string{}
+	details := make(map[string]interface{})
+	for _, name := range names {
+		th := c.Thresholds[name]
+		value, ok := metrics[name]
+		if !ok {
+			details[name] = "unavailable"
+			continue
+		}
+		details[name] = value
+
+		switch {
+		case th.Critical != 0 && HostMetricExceeds(name, value, th.Critical):
+			critical = append(critical, fmt.Sprintf("%s=%.2f (critical %.2f)", name, value, th.Critical))
+		case th.Warning != 0 && HostMetricExceeds(name, value, th.Warning):
+			warning = append(warning, fmt.Sprintf("%s=%.2f (warning %.2f)", name, value, th.Warning))
+		}
+	}
+
+	if len(critical) > 0 {
+		return Result{
+			Success:   false,
+			Message:   fmt.Sprintf("host critical: %s", strings.Join(append(critical, warning...), ", ")),
+			Latency:   time.Since(start),
+			CheckType: "host",
+			Details:   details,
+		}
+	}
+
+	if len(warning) > 0 {
+		return Result{
+			Success:   true,
+			Degraded:  true,
+			Message:   fmt.Sprintf("host warning: %s", strings.Join(warning, ", ")),
+			Latency:   time.Since(start),
+			CheckType: "host",
+			Details:   details,
+		}
+	}
+
+	return Result{
+		Success:   true,
+		Message:   "host metrics within thresholds",
+		Latency:   time.Since(start),
+		CheckType: "host",
+		Details:   details,
+	}
+}
@@ -0,0 +1,454 @@
+package checks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// DetailNewEvents clave de Details con los eventos discretos detectados en esta pasada.
+// El engine los notifica una única vez aunque el target siga healthy
+const DetailNewEvents = "new_events"
+
+// KernelEvent evento del kernel atribuido a un proceso
+type KernelEvent struct {
+	Kind    string            `json:"kind"` // oom, segfault, hung_task
+	PID     int               `json:"pid"`
+	Process string            `json:"process"`
+	Cgroup  string            `json:"cgroup,omitempty"`
+	Memory  map[string]string `json:"memory,omitempty"` // total-vm, anon-rss, file-rss, shmem-rss
+	Raw     string            `json:"raw"`
+	Time    time.Time         `json:"time"`
+}
+
+var (
+	reOOMKill     = regexp.MustCompile(`oom-kill:.*task_memcg=([^,]*),task=([^,]+),pid=(\d+)`)
+	reOOMKilled   = regexp.MustCompile(`Killed process (\d+) \(([^)]+)\)(.*)`)
+	reOOMMemory   = regexp.MustCompile(`([a-z][a-z-]*):(\d+kB)`)
+	reSegfault    = regexp.MustCompile(`(\S+)\[(\d+)\]: segfault at`)
+	reHungTask    = regexp.MustCompile(`task (\S+):(\d+) blocked for more than \d+ seconds`)
+	reDmesgPrefix = regexp.MustCompile(`^\[\s*(\d+\.\d+)\]\s?(.*)$`)
+)
+
+// bootIDFile identifica el arranque actual: las secuencias de /dev/kmsg empiezan de cero
+var bootIDFile = "/proc/sys/kernel/random/boot_id"
+
+// kernelState posición leída y eventos en la ventana, guardados entre pasadas. Seq solo
+// vale para /dev/kmsg; Usec (tiempo desde el arranque) es común a kmsg y dmesg
+type kernelState struct {
+	BootID string        `json:"boot_id"`
+	Source string        `json:"source"` // kmsg o dmesg
+	Seq    int64         `json:"seq"`
+	Usec   int64         `json:"usec"`
+	Events []KernelEvent `json:"events,omitempty"`
+}
+
+// kernelFollower recuerda la posición leída de /dev/kmsg (o dmesg) entre pasadas
+type kernelFollower struct {
+	mu         sync.Mutex
+	state      kernelState
+	started    bool // hay posición: se leen los mensajes posteriores
+	loaded     bool
+	stateFile  string
+	stateErr   error          // último error al cargar o guardar el estado
+	oomCgroups map[int]string // pid -> cgroup de la línea oom-kill previa
+}
+
+var (
+	kernelFollowersMu sync.Mutex
+	kernelFollowers   = make(map[string]*kernelFollower)
+)
+
+// KernelEventsChecker detecta OOM-kills, segfaults y hung tasks de los procesos del target
+type KernelEventsChecker struct {
+	ProcessNames []string
+	Cgroup       string
+	Events       map[string]bool
+	Window       time.Duration
+	follower     *kernelFollower
+}
+
+// NewKernelEventsChecker crea un nuevo kernel events checker
+func NewKernelEventsChecker(cfg *config.KernelEventsCheck) (*KernelEventsChecker, error) {
+	if cfg == nil || (len(cfg.ProcessNames) == 0 && cfg.Cgroup == "") {
+		return nil, fmt.Errorf("kernel_events check requires process_names or cgroup")
+	}
+
+	events := map[string]bool{"oom": true, "segfault": true, "hung_task": true}
+	if len(cfg.Events) > 0 {
+		events = make(map[string]bool)
+		for _, ev := range cfg.Events {
+			events[ev] = true
+		}
+	}
+
+	window := time.Duration(cfg.WindowSeconds) * time.Second
+	if window == 0 {
+		window = time.Hour
+	}
+
+	// La ventana forma parte de la clave: cada ventana necesita sus propios eventos
+	key := fmt.Sprintf("%s|%s|%s|%s", strings.Join(cfg.ProcessNames, ","), cfg.Cgroup, strings.Join(cfg.Events, ","), window)
+	stateFile := cfg.OffsetFile
+	if stateFile == "" {
+		stateFile = defaultStateFile("kernel-events", key)
+	}
+	key += "|" + stateFile
+
+	kernelFollowersMu.Lock()
+	f, ok := kernelFollowers[key]
+	if !ok {
+		f = &kernelFollower{stateFile: stateFile, oomCgroups: make(map[int]string)}
+		kernelFollowers[key] = f
+	}
+	kernelFollowersMu.Unlock()
+
+	return &KernelEventsChecker{
+		ProcessNames: cfg.ProcessNames,
+		Cgroup:       cfg.Cgroup,
+		Events:       events,
+		Window:       window,
+		follower:     f,
+	}, nil
+}
+
+func (c *KernelEventsChecker) Name() string {
+	if c.Cgroup != "" {
+		return fmt.Sprintf("kernel_events:%s", c.Cgroup)
+	}
+	return fmt.Sprintf("kernel_events:%s", strings.Join(c.ProcessNames, ","))
+}
+
+func (c *KernelEventsChecker) Check(ctx context.Context) Result {
+	start := time.Now()
+
+	c.follower.mu.Lock()
+	defer c.follower.mu.Unlock()
+
+	lines, err := c.follower.readNew(ctx)
+	if err != nil {
+		return Result{
+			Success:   false,
+			Message:   fmt.Sprintf("cannot read kernel log: %v", err),
+			Latency:   time.Since(start),
+			CheckType: "kernel_events",
+		}
+	}
+
+	now := time.Now()
+	newEvents := []KernelEvent{}
+	for _, line := range lines {
+		ev, ok := c.follower.parse(line)
+		if !ok || !c.Events[ev.Kind] || !c.matches(ev) {
+			continue
+		}
+		ev.Time = now
+		newEvents = append(newEvents, ev)
+	}
+	events := append(c.follower.state.Events, newEvents...)
+
+	// Mantener solo los eventos dentro de la ventana
+	cutoff := now.Add(-c.Window)
+	kept := events[:0]
+	for _, ev := range events {
+		if ev.Time.After(cutoff) {
+			kept = append(kept, ev)
+		}
+	}
+	pruned := len(kept) != len(c.follower.state.Events)+len(newEvents)
+	c.follower.state.Events = kept
+	if len(newEvents) > 0 || pruned {
+		c.follower.save()
+	}
+
+	if len(kept) == 0 {
+		result := Result{
+			Success:   true,
+			Message:   "no kernel events",
+			Latency:   time.Since(start),
+			CheckType: "kernel_events",
+		}
+		// Sin el estado guardado el siguiente check del timer vuelve a empezar por el final
+		if c.follower.stateErr != nil {
+			result.Degraded = true
+			result.Message += fmt.Sprintf(" (kernel log position not persisted: %v)", c.follower.stateErr)
+		}
+		return result
+	}
+
+	last := kept[len(kept)-1]
+	details := map[string]interface{}{
+		"events_in_window": len(kept),
+		"last_event":       last,
+	}
+	if len(newEvents) > 0 {
+		details[DetailNewEvents] = newEvents
+	}
+
+	message := fmt.Sprintf("%d kernel event(s) in last %s, last: %s", len(kept), c.Window, describeKernelEvent(last))
+	if c.follower.stateErr != nil {
+		message += fmt.Sprintf(" (kernel log position not persisted: %v)", c.follower.stateErr)
+	}
+
+	// El servicio puede estar ya levantado: el target queda degradado, no caído
+	return Result{
+		Success:   true,
+		Degraded:  true,
+		Message:   message,
+		Latency:   time.Since(start),
+		CheckType: "kernel_events",
+		Details:   details,
+	}
+}
+
+// matches indica si el evento pertenece a este target
+func (c *KernelEventsChecker) matches(ev KernelEvent) bool {
+	if c.Cgroup != "" {
+		cgroup := ev.Cgroup
+		if cgroup == "" {
+			cgroup = readProcCgroup(ev.PID)
+		}
+		if cgroup != "" && strings.Contains(cgroup, c.Cgroup) {
+			return true
+		}
+	}
+
+	for _, name := range c.ProcessNames {
+		// El kernel trunca comm a 15 caracteres
+		if ev.Process == name || (len(name) > 15 && ev.Process == name[:15]) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeKernelEvent resume un evento para el mensaje del resultado
+func describeKernelEvent(ev KernelEvent) string {
+	switch ev.Kind {
+	case "oom":
+		return fmt.Sprintf("OOM-killed %s (pid %d, anon-rss %s, total-vm %s)", ev.Process, ev.PID, ev.Memory["anon-rss"], ev.Memory["total-vm"])
+	case "segfault":
+		return fmt.Sprintf("segfault in %s (pid %d)", ev.Process, ev.PID)
+	default:
+		return fmt.Sprintf("hung task %s (pid %d)", ev.Process, ev.PID)
+	}
+}
+
+// parse interpreta una línea del kernel; las líneas oom-kill solo aportan el cgroup
+func (f *kernelFollower) parse(line string) (KernelEvent, bool) {
+	if m := reOOMKill.FindStringSubmatch(line); m != nil {
+		pid, _ := strconv.Atoi(m[3])
+		f.oomCgroups[pid] = m[1]
+		return KernelEvent{}, false
+	}
+
+	if m := reOOMKilled.FindStringSubmatch(line); m != nil {
+		pid, _ := strconv.Atoi(m[1])
+		memory := make(map[string]string)
+		for _, mm := range reOOMMemory.FindAllStringSubmatch(m[3], -1) {
+			memory[mm[1]] = mm[2]
+		}
+		ev := KernelEvent{Kind: "oom", PID: pid, Process: m[2], Cgroup: f.oomCgroups[pid], Memory: memory, Raw: line}
+		delete(f.oomCgroups, pid)
+		return ev, true
+	}
+
+	if m := reSegfault.FindStringSubmatch(line); m != nil {
+		pid, _ := strconv.Atoi(m[2])
+		return KernelEvent{Kind: "segfault", PID: pid, Process: m[1], Raw: line}, true
+	}
+
+	if m := reHungTask.FindStringSubmatch(line); m != nil {
+		pid, _ := strconv.Atoi(m[2])
+		return KernelEvent{Kind: "hung_task", PID: pid, Process: m[1], Raw: line}, true
+	}
+
+	return KernelEvent{}, false
+}
+
+// readNew retorna los mensajes nuevos desde la última posición. En la primera lectura sin
+// estado guardado solo se posiciona al final. Tras un reinicio (otro boot_id) se leen todos
+// los mensajes del arranque actual
+func (f *kernelFollower) readNew(ctx context.Context) ([]string, error) {
+	if !f.loaded {
+		f.load()
+	}
+
+	source, records, err := readKernelLog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bootID := readBootID()
+	if f.started && bootID != "" && f.state.BootID != "" && bootID != f.state.BootID {
+		f.state.Seq, f.state.Usec = -1, -1
+	}
+
+	// Mismo origen: la secuencia de kmsg es exacta. Si cambió (kmsg <-> dmesg) se compara
+	// el tiempo desde el arranque, que comparten
+	bySeq := source == "kmsg" && f.state.Source == "kmsg"
+
+	lines := []string{}
+	last := f.state
+	for _, r := range records {
+		newer := r.usec > f.state.Usec
+		if bySeq {
+			newer = r.seq > f.state.Seq
+		}
+		if !newer && f.started {
+			continue
+		}
+		if f.started {
+			lines = append(lines, r.msg)
+		}
+		if r.usec > last.Usec || r.seq > last.Seq {
+			last.Seq, last.Usec = r.seq, r.usec
+		}
+	}
+
+	changed := !f.started || last.Seq != f.state.Seq || last.Usec != f.state.Usec ||
+		bootID != f.state.BootID || source != f.state.Source
+	f.started = true
+	f.state.Seq, f.state.Usec = last.Seq, last.Usec
+	f.state.BootID, f.state.Source = bootID, source
+	if changed {
+		f.save()
+	}
+	return lines, nil
+}
+
+// readKernelLog lee /dev/kmsg o, si no es accesible, dmesg. Retorna el origen usado
+var readKernelLog = func(ctx context.Context) (string, []kmsgRecord, error) {
+	records, err := readKmsg()
+	if err == nil {
+		return "kmsg", records, nil
+	}
+	records, err = readDmesg(ctx)
+	return "dmesg", records, err
+}
+
+// load carga el estado guardado; si existe, se leen los eventos posteriores
+func (f *kernelFollower) load() {
+	f.loaded = true
+	loaded, err := loadCheckState(f.stateFile, &f.state)
+	f.stateErr = err
+	f.started = loaded
+}
+
+// save persiste la posición y los eventos de la ventana
+func (f *kernelFollower) save() {
+	f.stateErr = saveCheckState(f.stateFile, &f.state)
+}
+
+// readBootID identificador del arranque actual ("" si no se puede leer)
+func readBootID() string {
+	data, err := os.ReadFile(bootIDFile)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// kmsgRecord un mensaje del kernel con su número de secuencia (solo kmsg) y su tiempo en
+// microsegundos desde el arranque
+type kmsgRecord struct {
+	seq  int64
+	usec int64
+	msg  string
+}
+
+// readKmsg lee todos los registros disponibles en /dev/kmsg sin bloquear
+func readKmsg() ([]kmsgRecord, error) {
+	fd, err := syscall.Open("/dev/kmsg", syscall.O_RDONLY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.Close(fd)
+
+	records := []kmsgRecord{}
+	buf := make([]byte, 8192)
+	for {
+		n, err := syscall.Read(fd, buf)
+		if err != nil {
+			if errors.Is(err, syscall.EAGAIN) {
+				break
+			}
+			if errors.Is(err, syscall.EPIPE) {
+				// Registros sobrescritos en el ring buffer: continuar
+				continue
+			}
+			return nil, err
+		}
+		if n <= 0 {
+			break
+		}
+
+		// Formato: "prioridad,secuencia,timestamp,flags;mensaje\n[ KEY=valor\n...]"
+		record := string(buf[:n])
+		header, msg, ok := strings.Cut(record, ";")
+		if !ok {
+			continue
+		}
+		fields := strings.Split(header, ",")
+		if len(fields) < 3 {
+			continue
+		}
+		seq, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		usec, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		msg, _, _ = strings.Cut(msg, "\n")
+		records = append(records, kmsgRecord{seq: seq, usec: usec, msg: msg})
+	}
+
+	return records, nil
+}
+
+// readDmesg es el fallback cuando /dev/kmsg no es accesible; solo aporta el timestamp en µs
+func readDmesg(ctx context.Context) ([]kmsgRecord, error) {
+	output, err := exec.CommandContext(ctx, "dmesg").Output()
+	if err != nil {
+		return nil, fmt.Errorf("dmesg failed: %w", err)
+	}
+
+	records := []kmsgRecord{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		m := reDmesgPrefix.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		ts, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			continue
+		}
+		records = append(records, kmsgRecord{usec: int64(math.Round(ts * 1e6)), msg: m[2]})
+	}
+	return records, nil
+}
+
+// readProcCgroup retorna el cgroup de un proceso vivo, o "" si ya no existe
+func readProcCgroup(pid int) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
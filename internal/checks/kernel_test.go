@@ -0,0 +1,93 @@
+package checks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// fakeKernelLog sustituye /dev/kmsg y el boot_id durante el test
+type fakeKernelLog struct {
+	source  string
+	records []kmsgRecord
+}
+
+func useFakeKernelLog(t *testing.T) (*fakeKernelLog, string) {
+	t.Helper()
+	log := &fakeKernelLog{source: "kmsg"}
+	prevRead, prevBootID := readKernelLog, bootIDFile
+	readKernelLog = func(ctx context.Context) (string, []kmsgRecord, error) {
+		return log.source, log.records, nil
+	}
+	bootIDFile = filepath.Join(t.TempDir(), "boot_id")
+	t.Cleanup(func() {
+		readKernelLog, bootIDFile = prevRead, prevBootID
+		kernelFollowersMu.Lock()
+		kernelFollowers = make(map[string]*kernelFollower)
+		kernelFollowersMu.Unlock()
+	})
+	return log, bootIDFile
+}
+
+func setBootID(t *testing.T, path, id string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(id+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newKernelProcess simula una nueva ejecución del timer
+func newKernelProcess(t *testing.T) *KernelEventsChecker {
+	t.Helper()
+	kernelFollowersMu.Lock()
+	kernelFollowers = make(map[string]*kernelFollower)
+	kernelFollowersMu.Unlock()
+	checker, err := NewKernelEventsChecker(&config.KernelEventsCheck{ProcessNames: []string{"java"}, WindowSeconds: 3600})
+	if err != nil {
+		t.Fatalf("NewKernelEventsChecker: %v", err)
+	}
+	return checker
+}
+
+func oomRecord(seq, usec int64) kmsgRecord {
+	return kmsgRecord{seq: seq, usec: usec, msg: "Out of memory: Killed process 4242 (java) total-vm:100kB, anon-rss:50kB"}
+}
+
+func TestKernelEventsTimerModeAndReboot(t *testing.T) {
+	useStateDir(t)
+	log, bootID := useFakeKernelLog(t)
+	setBootID(t, bootID, "boot-1")
+	log.records = []kmsgRecord{oomRecord(10, 1000)}
+
+	// Primera ejecución: se posiciona al final sin contar lo anterior
+	expectHealthy(t, runCheck(t, newKernelProcess(t)))
+
+	log.records = append(log.records, oomRecord(11, 2000))
+	expectDegraded(t, runCheck(t, newKernelProcess(t)), "1 kernel event(s)")
+
+	// Tras un reinicio las secuencias empiezan de cero: el OOM temprano no se pierde
+	setBootID(t, bootID, "boot-2")
+	log.records = []kmsgRecord{oomRecord(0, 500)}
+	result := runCheck(t, newKernelProcess(t))
+	expectDegraded(t, result, "2 kernel event(s)")
+	if got := len(result.Details[DetailNewEvents].([]KernelEvent)); got != 1 {
+		t.Fatalf("expected 1 new event after reboot, got %d", got)
+	}
+}
+
+func TestKernelEventsSourceChange(t *testing.T) {
+	useStateDir(t)
+	log, bootID := useFakeKernelLog(t)
+	setBootID(t, bootID, "boot-1")
+	log.records = []kmsgRecord{oomRecord(500, 9000000)}
+	expectHealthy(t, runCheck(t, newKernelProcess(t)))
+
+	// dmesg no tiene secuencia: se compara el tiempo desde el arranque
+	log.source = "dmesg"
+	log.records = []kmsgRecord{oomRecord(0, 9000000), oomRecord(0, 9500000)}
+	result := runCheck(t, newKernelProcess(t))
+	expectDegraded(t, result, "1 kernel event(s)")
+}
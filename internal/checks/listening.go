@@ -0,0 +1,353 @@
+package checks
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// Estados de /proc/net/* relevantes
+const (
+	tcpStateListen  = "0A"
+	udpStateUnconn  = "07"
+	unixFlagAccept  = 0x10000 // __SO_ACCEPTCON: socket unix en escucha
+	unixStateUnconn = "01"
+)
+
+// socketEntry un socket en escucha leído de /proc/net
+type socketEntry struct {
+	Address string
+	Port    int
+	Path    string
+	Inode   string
+	RecvQ   int
+}
+
+// ListeningChecker verifica que un puerto/socket está en escucha por el proceso esperado
+type ListeningChecker struct {
+	Protocol    string
+	Port        int
+	Address     string
+	Path        string
+	ProcessName string
+	MaxBacklog  int
+}
+
+// NewListeningChecker crea un nuevo listening checker
+func NewListeningChecker(cfg *config.ListeningCheck) (*ListeningChecker, error) {
+	if cfg == nil || cfg.ProcessName == "" {
+		return nil, fmt.Errorf("listening check requires process_name")
+	}
+
+	protocol := cfg.Protocol
+	if protocol == "" {
+		protocol = "tcp"
+	}
+	if protocol == "unix" && cfg.Path == "" {
+		return nil, fmt.Errorf("listening check requires path for unix sockets")
+	}
+	if protocol != "unix" && cfg.Port <= 0 {
+		return nil, fmt.Errorf("listening check requires port")
+	}
+
+	return &ListeningChecker{
+		Protocol:    protocol,
+		Port:        cfg.Port,
+		Address:     cfg.Address,
+		Path:        cfg.Path,
+		ProcessName: cfg.ProcessName,
+		MaxBacklog:  cfg.MaxBacklog,
+	}, nil
+}
+
+func (c *ListeningChecker) Name() string {
+	if c.Protocol == "unix" {
+		return fmt.Sprintf("listening:unix:%s", c.Path)
+	}
+	return fmt.Sprintf("listening:%s:%d", c.Protocol, c.Port)
+}
+
+// endpoint describe el socket buscado para los mensajes
+func (c *ListeningChecker) endpoint() string {
+	if c.Protocol == "unix" {
+		return "unix:" + c.Path
+	}
+	if c.Address != "" {
+		return fmt.Sprintf("%s:%s", c.Protocol, net.JoinHostPort(c.Address, strconv.Itoa(c.Port)))
+	}
+	return fmt.Sprintf("%s:%d", c.Protocol, c.Port)
+}
+
+func (c *ListeningChecker) Check(ctx context.Context) Result {
+	start := time.Now()
+
+	entries, err := c.listeningSockets()
+	if err != nil {
+		return Result{
+			Success:   false,
+			Message:   fmt.Sprintf("cannot read sockets: %v", err),
+			Latency:   time.Since(start),
+			CheckType: "listening",
+		}
+	}
+
+	if len(entries) == 0 {
+		return Result{
+			Success:   false,
+			Message:   fmt.Sprintf("nothing listening on %s", c.endpoint()),
+			Latency:   time.Since(start),
+			CheckType: "listening",
+		}
+	}
+
+	inodes := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		inodes[e.Inode] = true
+	}
+	owners := socketOwners(inodes)
+
+	// Todos los sockets que coinciden deben pertenecer al proceso esperado
+	ownerNames := []string{}
+	for _, e := range entries {
+		pids := owners[e.Inode]
+		if len(pids) == 0 {
+			return Result{
+				Success:   false,
+				Message:   fmt.Sprintf("%s is listening but owner process not found (insufficient permissions?)", c.endpoint()),
+				Latency:   time.Since(start),
+				CheckType: "listening",
+			}
+		}
+		for _, pid := range pids {
+			name := processName(pid)
+			ownerNames = append(ownerNames, fmt.Sprintf("%s[%d]", name, pid))
+			if !c.matchesProcess(pid, name) {
+				return Result{
+					Success:   false,
+					Message:   fmt.Sprintf("%s is held by %s[%d], expected '%s'", c.endpoint(), name, pid, c.ProcessName),
+					Latency:   time.Since(start),
+					CheckType: "listening",
+					Details:   map[string]interface{}{"pid": pid, "process": name},
+				}
+			}
+		}
+
+		// Recv-Q en LISTEN es la cola de accept pendiente
+		if c.MaxBacklog > 0 && c.Protocol == "tcp" && e.RecvQ > c.MaxBacklog {
+			return Result{
+				Success:   false,
+				Message:   fmt.Sprintf("%s accept queue saturated: backlog %d > %d", c.endpoint(), e.RecvQ, c.MaxBacklog),
+				Latency:   time.Since(start),
+				CheckType: "listening",
+				Details:   map[string]interface{}{"backlog": e.RecvQ, "max_backlog": c.MaxBacklog},
+			}
+		}
+	}
+
+	return Result{
+		Success:   true,
+		Message:   fmt.Sprintf("%s held by %s", c.endpoint(), strings.Join(ownerNames, ", ")),
+		Latency:   time.Since(start),
+		CheckType: "listening",
+	}
+}
+
+// matchesProcess compara comm y el basename del ejecutable con el nombre esperado
+func (c *ListeningChecker) matchesProcess(pid int, comm string) bool {
+	if comm == c.ProcessName || (len(c.ProcessName) > 15 && comm == c.ProcessName[:15]) {
+		return true
+	}
+	if exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid)); err == nil {
+		return filepath.Base(strings.TrimSuffix(exe, " (deleted)")) == c.ProcessName
+	}
+	return false
+}
+
+// listeningSockets retorna los sockets en escucha que coinciden con la configuración
+func (c *ListeningChecker) listeningSockets() ([]socketEntry, error) {
+	if c.Protocol == "unix" {
+		all, err := parseProcNetUnix("/proc/net/unix")
+		if err != nil {
+			return nil, err
+		}
+		matched := []socketEntry{}
+		for _, e := range all {
+			if e.Path == c.Path {
+				matched = append(matched, e)
+			}
+		}
+		return matched, nil
+	}
+
+	state := tcpStateListen
+	if c.Protocol == "udp" {
+		state = udpStateUnconn
+	}
+
+	matched := []socketEntry{}
+	for _, file := range []string{"/proc/net/" + c.Protocol, "/proc/net/" + c.Protocol + "6"} {
+		all, err := parseProcNetInet(file, state)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // IPv6 deshabilitado
+			}
+			return nil, err
+		}
+		for _, e := range all {
+			if e.Port != c.Port {
+				continue
+			}
+			// Un socket en la dirección comodín también atiende la dirección pedida
+			if c.Address != "" && !sameIP(e.Address, c.Address) && !net.ParseIP(e.Address).IsUnspecified() {
+				continue
+			}
+			matched = append(matched, e)
+		}
+	}
+	return matched, nil
+}
+
+// sameIP compara dos direcciones IP en forma normalizada
+func sameIP(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	return ipA != nil && ipB != nil && ipA.Equal(ipB)
+}
+
+// parseProcNetInet parsea /proc/net/{tcp,tcp6,udp,udp6} filtrando por estado
+func parseProcNetInet(path, state string) ([]socketEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []socketEntry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // cabecera
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != state {
+			continue
+		}
+
+		addr, port, err := decodeHexAddr(fields[1])
+		if err != nil {
+			continue
+		}
+
+		recvQ := 0
+		if _, rx, ok := strings.Cut(fields[4], ":"); ok {
+			if v, err := strconv.ParseInt(rx, 16, 64); err == nil {
+				recvQ = int(v)
+			}
+		}
+
+		entries = append(entries, socketEntry{Address: addr, Port: port, Inode: fields[9], RecvQ: recvQ})
+	}
+	return entries, scanner.Err()
+}
+
+// decodeHexAddr decodifica "0100007F:1F90" (IPv4) o la forma de 32 dígitos (IPv6)
+func decodeHexAddr(s string) (string, int, error) {
+	hexIP, hexPort, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("invalid address: %s", s)
+	}
+
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, err
+	}
+
+	raw, err := hex.DecodeString(hexIP)
+	if err != nil || (len(raw) != 4 && len(raw) != 16) {
+		return "", 0, fmt.Errorf("invalid ip: %s", hexIP)
+	}
+
+	// El kernel escribe cada palabra de 32 bits en orden del host (little-endian)
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+	return ip.String(), int(port), nil
+}
+
+// parseProcNetUnix parsea /proc/net/unix retornando los sockets en escucha
+func parseProcNetUnix(path string) ([]socketEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []socketEntry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // cabecera
+	for scanner.Scan() {
+		// Num RefCount Protocol Flags Type St Inode Path
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil || flags&unixFlagAccept == 0 || fields[5] != unixStateUnconn {
+			continue
+		}
+		entries = append(entries, socketEntry{Path: fields[7], Inode: fields[6]})
+	}
+	return entries, scanner.Err()
+}
+
+// socketOwners recorre /proc/*/fd y retorna los PIDs que tienen abierto cada inode
+func socketOwners(inodes map[string]bool) map[string][]int {
+	owners := make(map[string][]int)
+
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return owners
+	}
+
+	for _, p := range procs {
+		pid, err := strconv.Atoi(p.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := fmt.Sprintf("/proc/%d/fd", pid)
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, fd := range fds {
+			link, err := os.Readlink(fdDir + "/" + fd.Name())
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			inode := strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")
+			if inodes[inode] && !seen[inode] {
+				seen[inode] = true
+				owners[inode] = append(owners[inode], pid)
+			}
+		}
+	}
+	return owners
+}
+
+// processName lee /proc/<pid>/comm
+func processName(pid int) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return "?"
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,418 @@
+package checks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// maxLogChunk limita lo que se lee de un log en cada pasada
+const maxLogChunk = 4 * 1024 * 1024
+
+// maxLogMatches coincidencias que se conservan en la ventana; por encima se descartan las
+// más antiguas y el recuento se queda en este valor
+const maxLogMatches = 10000
+
+// logMatch una línea que coincidió con el patrón
+type logMatch struct {
+	Time time.Time `json:"time"`
+	Line string    `json:"line,omitempty"` // solo en las últimas muestras al guardar el estado
+}
+
+// logSource fuente incremental de líneas nuevas (fichero o journal)
+type logSource interface {
+	ReadNew(ctx context.Context) ([]string, error)
+	save(state *logState)
+	restore(state *logState)
+}
+
+// logState posición de lectura y coincidencias en la ventana, guardadas entre pasadas para
+// que un check puntual del timer continúe donde lo dejó el anterior
+type logState struct {
+	Inode   uint64     `json:"inode,omitempty"`
+	Offset  int64      `json:"offset,omitempty"`
+	Cursor  string     `json:"cursor,omitempty"`
+	Since   time.Time  `json:"since,omitempty"`
+	Matches []logMatch `json:"matches,omitempty"`
+}
+
+// logFollower mantiene la posición de lectura y la ventana de coincidencias entre pasadas
+type logFollower struct {
+	mu        sync.Mutex
+	source    logSource
+	matches   []logMatch
+	stateFile string
+	stateErr  error // último error al cargar o guardar el estado
+}
+
+var (
+	followersMu sync.Mutex
+	followers   = make(map[string]*logFollower)
+)
+
+// getFollower retorna el follower compartido para una fuente, patrón y ventana. Al crearlo
+// recupera el estado guardado en stateFile
+func getFollower(key, stateFile string, newSource func() logSource) *logFollower {
+	followersMu.Lock()
+	defer followersMu.Unlock()
+
+	f, ok := followers[key]
+	if !ok {
+		f = &logFollower{source: newSource(), stateFile: stateFile}
+		var state logState
+		loaded, err := loadCheckState(stateFile, &state)
+		if loaded {
+			f.source.restore(&state)
+			f.matches = state.Matches
+		}
+		f.stateErr = err
+		followers[key] = f
+	}
+	return f
+}
+
+// saveState guarda la posición y las coincidencias; solo las últimas maxSamples conservan la línea
+func (f *logFollower) saveState(maxSamples int) {
+	if f.stateFile == "" {
+		return
+	}
+	state := logState{Matches: make([]logMatch, len(f.matches))}
+	for i, m := range f.matches {
+		if i < len(f.matches)-maxSamples {
+			m.Line = ""
+		}
+		state.Matches[i] = m
+	}
+	f.source.save(&state)
+	f.stateErr = saveCheckState(f.stateFile, &state)
+}
+
+// LogPatternChecker cuenta coincidencias de un patrón en una ventana de tiempo
+type LogPatternChecker struct {
+	Path        string
+	JournalUnit string
+	Pattern     *regexp.Regexp
+	Window      time.Duration
+	Threshold   int
+	MaxSamples  int
+	follower    *logFollower
+}
+
+// NewLogPatternChecker crea un nuevo log pattern checker
+func NewLogPatternChecker(cfg *config.LogPatternCheck) (*LogPatternChecker, error) {
+	if cfg == nil || cfg.Pattern == "" {
+		return nil, fmt.Errorf("log_pattern check requires pattern")
+	}
+	if (cfg.Path == "") == (cfg.JournalUnit == "") {
+		return nil, fmt.Errorf("log_pattern check requires exactly one of path or journal_unit")
+	}
+
+	re, err := regexp.Compile(cfg.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	window := time.Duration(cfg.WindowSeconds) * time.Second
+	if window == 0 {
+		window = 5 * time.Minute
+	}
+
+	maxSamples := cfg.MaxSamples
+	if maxSamples <= 0 {
+		maxSamples = 5
+	}
+
+	c := &LogPatternChecker{
+		Path:        cfg.Path,
+		JournalUnit: cfg.JournalUnit,
+		Pattern:     re,
+		Window:      window,
+		Threshold:   cfg.Threshold,
+		MaxSamples:  maxSamples,
+	}
+
+	// La ventana forma parte de la clave: cada ventana necesita sus propias coincidencias
+	key := fmt.Sprintf("file:%s|%s|%s", cfg.Path, cfg.Pattern, window)
+	newSource := func() logSource { return &fileSource{path: cfg.Path} }
+	if cfg.JournalUnit != "" {
+		key = fmt.Sprintf("journal:%s|%s|%s", cfg.JournalUnit, cfg.Pattern, window)
+		newSource = func() logSource { return &journalSource{unit: cfg.JournalUnit} }
+	}
+	stateFile := cfg.OffsetFile
+	if stateFile == "" {
+		stateFile = defaultStateFile("log-pattern", key)
+	}
+	c.follower = getFollower(key, stateFile, newSource)
+
+	return c, nil
+}
+
+func (c *LogPatternChecker) Name() string {
+	if c.JournalUnit != "" {
+		return fmt.Sprintf("log_pattern:journal:%s", c.JournalUnit)
+	}
+	return fmt.Sprintf("log_pattern:%s", c.Path)
+}
+
+func (c *LogPatternChecker) Check(ctx context.Context) Result {
+	start := time.Now()
+
+	c.follower.mu.Lock()
+	defer c.follower.mu.Unlock()
+
+	lines, err := c.follower.source.ReadNew(ctx)
+	if err != nil {
+		return Result{
+			Success:   false,
+			Message:   fmt.Sprintf("cannot read log: %v", err),
+			Latency:   time.Since(start),
+			CheckType: "log_pattern",
+		}
+	}
+
+	now := time.Now()
+	for _, line := range lines {
+		if c.Pattern.MatchString(line) {
+			if len(line) > 300 {
+				line = line[:300] + "..."
+			}
+			c.follower.matches = append(c.follower.matches, logMatch{Time: now, Line: line})
+		}
+	}
+
+	// Descartar coincidencias fuera de la ventana
+	cutoff := now.Add(-c.Window)
+	kept := c.follower.matches[:0]
+	for _, m := range c.follower.matches {
+		if m.Time.After(cutoff) {
+			kept = append(kept, m)
+		}
+	}
+	if len(kept) > maxLogMatches {
+		kept = kept[len(kept)-maxLogMatches:]
+	}
+	c.follower.matches = kept
+	c.follower.saveState(c.MaxSamples)
+
+	count := len(kept)
+	samples := make([]string, 0, c.MaxSamples)
+	for i := count - 1; i >= 0 && len(samples) < c.MaxSamples; i-- {
+		if kept[i].Line != "" {
+			samples = append(samples, kept[i].Line)
+		}
+	}
+
+	details := map[string]interface{}{
+		"matches":        count,
+		"threshold":      c.Threshold,
+		"window_seconds": int(c.Window.Seconds()),
+		"samples":        samples,
+	}
+
+	if count > c.Threshold {
+		return Result{
+			Success:   false,
+			Message:   fmt.Sprintf("%d matches of '%s' in last %s (threshold %d): %s", count, c.Pattern.String(), c.Window, c.Threshold, strings.Join(samples, " | ")),
+			Latency:   time.Since(start),
+			CheckType: "log_pattern",
+			Details:   details,
+		}
+	}
+
+	result := Result{
+		Success:   true,
+		Message:   fmt.Sprintf("%d matches of '%s' in last %s (threshold %d)", count, c.Pattern.String(), c.Window, c.Threshold),
+		Latency:   time.Since(start),
+		CheckType: "log_pattern",
+		Details:   details,
+	}
+	// Sin el estado guardado el siguiente check del timer no ve las líneas de esta pasada
+	if c.follower.stateErr != nil {
+		result.Degraded = true
+		result.Message += fmt.Sprintf(" (log position not persisted: %v)", c.follower.stateErr)
+	}
+	return result
+}
+
+// fileSource sigue un fichero como `tail -F`, soportando rotación, truncado y ficheros que
+// todavía no existen
+type fileSource struct {
+	path    string
+	file    *os.File
+	inode   uint64
+	offset  int64 // siempre tras la última línea completa leída
+	started bool  // ya hubo una primera lectura: las aperturas siguientes empiezan desde el inicio
+}
+
+func (s *fileSource) ReadNew(ctx context.Context) ([]string, error) {
+	if s.file == nil {
+		first := !s.started
+		if err := s.open(); err != nil {
+			if os.IsNotExist(err) {
+				// Como tail -F: cuando aparezca se lee desde el principio
+				s.started = true
+				return nil, nil
+			}
+			return nil, err
+		}
+		s.started = true
+		if first {
+			// Primera lectura: solo posicionarse al final
+			if info, err := s.file.Stat(); err == nil {
+				s.offset = info.Size()
+			}
+			return nil, nil
+		}
+	}
+
+	// Truncado: el fichero es más pequeño que nuestra posición
+	if info, err := s.file.Stat(); err == nil && info.Size() < s.offset {
+		s.offset = 0
+	}
+
+	lines, err := s.drain()
+	if err != nil {
+		return lines, err
+	}
+
+	// Rotación: la ruta apunta a otro inode; drenado el anterior, abrir el nuevo desde el
+	// inicio. Si no se puede abrir ahora, la siguiente pasada lo reintenta también desde el inicio
+	if info, err := os.Stat(s.path); err == nil {
+		if st, ok := info.Sys().(*syscall.Stat_t); ok && st.Ino != s.inode {
+			s.file.Close()
+			s.file = nil
+			if err := s.open(); err != nil {
+				return lines, nil
+			}
+			more, err := s.drain()
+			lines = append(lines, more...)
+			if err != nil {
+				return lines, err
+			}
+		}
+	}
+
+	return lines, nil
+}
+
+// open abre el fichero. Conserva la posición si es el mismo inode y no se ha truncado;
+// si no (rotado, recreado) empieza desde el inicio
+func (s *fileSource) open() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	var inode uint64
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		inode = st.Ino
+	}
+	if inode != s.inode || info.Size() < s.offset {
+		s.offset = 0
+	}
+	s.file = f
+	s.inode = inode
+	return nil
+}
+
+// drain lee desde la posición actual hasta EOF y retorna las líneas completas. Una línea a
+// medio escribir se deja para la siguiente pasada
+func (s *fileSource) drain() ([]string, error) {
+	data, err := io.ReadAll(io.NewSectionReader(s.file, s.offset, maxLogChunk))
+	if err != nil {
+		return nil, err
+	}
+
+	end := bytes.LastIndexByte(data, '\n') + 1
+	if end == 0 && len(data) == maxLogChunk {
+		// Una línea más larga que el bloque: se toma entera
+		end = len(data)
+	}
+	s.offset += int64(end)
+	if end == 0 {
+		return nil, nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data[:end]), "\n"), "\n"), nil
+}
+
+func (s *fileSource) save(state *logState) {
+	state.Inode, state.Offset = s.inode, s.offset
+}
+
+func (s *fileSource) restore(state *logState) {
+	s.inode, s.offset, s.started = state.Inode, state.Offset, true
+}
+
+// journalSource lee entradas nuevas de journald para una unidad usando cursores
+type journalSource struct {
+	unit   string
+	cursor string
+	since  time.Time
+}
+
+const cursorPrefix = "-- cursor: "
+
+func (s *journalSource) ReadNew(ctx context.Context) ([]string, error) {
+	first := s.cursor == "" && s.since.IsZero()
+
+	args := []string{"-u", s.unit, "--no-pager", "-o", "cat", "--show-cursor"}
+	switch {
+	case s.cursor != "":
+		args = append(args, "--after-cursor", s.cursor)
+	case !first:
+		// Aún no había entradas para la unidad: leer desde la primera pasada
+		args = append(args, "--since", fmt.Sprintf("@%d", s.since.Unix()))
+	default:
+		// Primera lectura: solo posicionarse al final
+		args = append(args, "-n", "0")
+		s.since = time.Now()
+	}
+
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("journalctl failed: %w", err)
+	}
+
+	lines := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, cursorPrefix) {
+			s.cursor = strings.TrimPrefix(line, cursorPrefix)
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if first {
+		return nil, nil
+	}
+	return lines, nil
+}
+
+func (s *journalSource) save(state *logState) {
+	state.Cursor, state.Since = s.cursor, s.since
+}
+
+func (s *journalSource) restore(state *logState) {
+	s.cursor, s.since = state.Cursor, state.Since
+}
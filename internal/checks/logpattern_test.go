@@ -0,0 +1,130 @@
+package checks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// useStateDir guarda el estado de los checks en un directorio temporal durante el test
+func useStateDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	SetStateDir(dir)
+	t.Cleanup(func() { SetStateDir("") })
+	return dir
+}
+
+// newProcess simula una nueva ejecución del timer: se pierden los followers en memoria
+func newProcess() {
+	followersMu.Lock()
+	followers = make(map[string]*logFollower)
+	followersMu.Unlock()
+}
+
+func appendLine(t *testing.T, path, line string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newLogPatternTestChecker(t *testing.T, cfg *config.LogPatternCheck) *LogPatternChecker {
+	t.Helper()
+	checker, err := NewLogPatternChecker(cfg)
+	if err != nil {
+		t.Fatalf("NewLogPatternChecker: %v", err)
+	}
+	return checker
+}
+
+func expectMatches(t *testing.T, r Result, want int) {
+	t.Helper()
+	if got := r.Details["matches"]; got != want {
+		t.Fatalf("expected %d matches, got %v (%s)", want, got, r.Message)
+	}
+}
+
+func TestLogPatternTimerMode(t *testing.T) {
+	useStateDir(t)
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendLine(t, path, "ERROR before the first check")
+	cfg := &config.LogPatternCheck{Path: path, Pattern: "ERROR", WindowSeconds: 3600}
+
+	// Primera ejecución: se posiciona al final sin contar lo anterior
+	expectMatches(t, runCheck(t, newLogPatternTestChecker(t, cfg)), 0)
+
+	appendLine(t, path, "ERROR between runs")
+	appendLine(t, path, "INFO fine")
+	newProcess()
+	result := runCheck(t, newLogPatternTestChecker(t, cfg))
+	expectFailure(t, result, "ERROR between runs")
+	expectMatches(t, result, 1)
+
+	// La coincidencia sigue en la ventana en la ejecución siguiente
+	newProcess()
+	expectMatches(t, runCheck(t, newLogPatternTestChecker(t, cfg)), 1)
+}
+
+func TestLogPatternMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "later.log")
+	cfg := &config.LogPatternCheck{Path: path, Pattern: "panic", Threshold: 0}
+
+	result := runCheck(t, newLogPatternTestChecker(t, cfg))
+	expectHealthy(t, result)
+	expectMatches(t, result, 0)
+
+	// Cuando el fichero aparece se lee desde el principio
+	appendLine(t, path, "panic: nil map")
+	expectFailure(t, runCheck(t, newLogPatternTestChecker(t, cfg)), "panic: nil map")
+}
+
+func TestLogPatternRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendLine(t, path, "start")
+	cfg := &config.LogPatternCheck{Path: path, Pattern: "ERROR", Threshold: 10}
+	expectMatches(t, runCheck(t, newLogPatternTestChecker(t, cfg)), 0)
+
+	appendLine(t, path, "ERROR last line of the old file")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendLine(t, path, "ERROR first line of the new file")
+
+	expectMatches(t, runCheck(t, newLogPatternTestChecker(t, cfg)), 2)
+
+	// Una línea a medio escribir no se cuenta hasta que se completa
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("ERROR partial")
+	expectMatches(t, runCheck(t, newLogPatternTestChecker(t, cfg)), 2)
+	f.WriteString(" line\n")
+	f.Close()
+	expectMatches(t, runCheck(t, newLogPatternTestChecker(t, cfg)), 3)
+}
+
+func TestLogPatternWindowsDoNotShareMatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendLine(t, path, "start")
+
+	short := newLogPatternTestChecker(t, &config.LogPatternCheck{Path: path, Pattern: "ERROR", WindowSeconds: 60})
+	long := newLogPatternTestChecker(t, &config.LogPatternCheck{Path: path, Pattern: "ERROR", WindowSeconds: 3600})
+	if short.follower == long.follower {
+		t.Fatal("checks with different windows must not share a follower")
+	}
+	runCheck(t, short)
+	runCheck(t, long)
+
+	appendLine(t, path, "ERROR once")
+	expectMatches(t, runCheck(t, short), 1)
+	expectMatches(t, runCheck(t, long), 1)
+}
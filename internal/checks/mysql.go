@@ -0,0 +1,564 @@
+package checks
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// Capacidades del protocolo cliente/servidor de MySQL
+const (
+	mysqlClientLongPassword     = 0x00000001
+	mysqlClientConnectWithDB    = 0x00000008
+	mysqlClientProtocol41       = 0x00000200
+	mysqlClientSSL              = 0x00000800
+	mysqlClientTransactions     = 0x00002000
+	mysqlClientSecureConnection = 0x00008000
+	mysqlClientPluginAuth       = 0x00080000
+	mysqlClientPluginAuthLenenc = 0x00200000
+
+	mysqlMaxPacket = 16 * 1024 * 1024
+	mysqlCharset   = 45 // utf8mb4_general_ci
+
+	mysqlComQuit  = 0x01
+	mysqlComQuery = 0x03
+)
+
+// MySQLChecker verifica MySQL/MariaDB con el protocolo nativo: handshake y consulta de prueba
+type MySQLChecker struct {
+	cfg *dbConfig
+}
+
+// NewMySQLChecker crea un nuevo mysql checker
+func NewMySQLChecker(cfg *config.DatabaseCheck) (*MySQLChecker, error) {
+	dbCfg, err := newDBConfig("mysql", cfg, "3306")
+	if err != nil {
+		return nil, err
+	}
+	if dbCfg.Username == "" {
+		return nil, fmt.Errorf("mysql check requires username")
+	}
+	return &MySQLChecker{cfg: dbCfg}, nil
+}
+
+func (c *MySQLChecker) Name() string {
+	return fmt.Sprintf("mysql:%s", c.cfg.address)
+}
+
+func (c *MySQLChecker) Check(ctx context.Context) Result {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.timeout)
+	defer cancel()
+
+	expect := c.cfg.Expect
+	if c.cfg.Query == "" && expect == "" {
+		expect = "1"
+	}
+
+	st, err := c.probe(ctx)
+	if err != nil {
+		return Result{
+			Success:   false,
+			Message:   fmt.Sprintf("mysql %s: %v", c.cfg.address, err),
+			Latency:   time.Since(start),
+			CheckType: "mysql",
+		}
+	}
+	return evaluateDatabase("mysql", "mysql "+c.cfg.address, c.cfg, expect, st, time.Since(start))
+}
+
+// probe conecta, se autentica y ejecuta las consultas configuradas
+func (c *MySQLChecker) probe(ctx context.Context) (*dbStatus, error) {
+	if err := c.cfg.resolvePassword(); err != nil {
+		return nil, err
+	}
+
+	conn, err := c.cfg.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	my := &mysqlConn{conn: conn}
+	if err := my.handshake(ctx, c.cfg); err != nil {
+		return nil, err
+	}
+	defer my.quit()
+
+	query := c.cfg.Query
+	if query == "" {
+		query = "SELECT 1"
+	}
+	_, rows, err := my.query(query)
+	if err != nil {
+		return nil, fmt.Errorf("probe query failed: %w", err)
+	}
+	st := &dbStatus{Value: firstValue(rows)}
+
+	if c.cfg.wantsRole() {
+		if err := my.replicationStatus(st); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.cfg.MaxConnectionsPercent > 0 {
+		_, rows, err := my.query("SHOW GLOBAL STATUS LIKE 'Threads_connected'")
+		if err != nil {
+			return nil, fmt.Errorf("cannot read connections: %w", err)
+		}
+		if len(rows) > 0 && len(rows[0]) == 2 && rows[0][1] != nil {
+			st.Connections, _ = strconv.Atoi(*rows[0][1])
+		}
+		_, rows, err = my.query("SELECT @@max_connections")
+		if err != nil {
+			return nil, fmt.Errorf("cannot read max_connections: %w", err)
+		}
+		st.MaxConnections, _ = strconv.Atoi(firstValue(rows))
+	}
+
+	return st, nil
+}
+
+// mysqlConn cliente mínimo del protocolo de MySQL (text protocol)
+type mysqlConn struct {
+	conn     net.Conn
+	seq      byte
+	scramble []byte
+	tls      bool
+	password string
+}
+
+// writePacket escribe un paquete: longitud (3 bytes) + secuencia + payload
+func (my *mysqlConn) writePacket(payload []byte) error {
+	header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), my.seq}
+	my.seq++
+	_, err := my.conn.Write(append(header, payload...))
+	return err
+}
+
+// readPacket lee un paquete completo
+func (my *mysqlConn) readPacket() ([]byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(my.conn, header); err != nil {
+		return nil, err
+	}
+	size := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	my.seq = header[3] + 1
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(my.conn, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// handshake procesa el saludo del servidor, negocia TLS y se autentica
+func (my *mysqlConn) handshake(ctx context.Context, cfg *dbConfig) error {
+	greeting, err := my.readPacket()
+	if err != nil {
+		return err
+	}
+	if len(greeting) > 0 && greeting[0] == 0xff {
+		return mysqlError(greeting)
+	}
+	if len(greeting) < 1 || greeting[0] != 10 {
+		return fmt.Errorf("unsupported handshake protocol")
+	}
+
+	// protocol(1) version(NUL) thread_id(4) scramble1(8) filler(1) caps_low(2) charset(1) status(2) caps_high(2) scramble_len(1) reserved(10)
+	pos := bytes.IndexByte(greeting[1:], 0) + 2
+	if pos < 2 || len(greeting) < pos+31 {
+		return fmt.Errorf("invalid handshake packet")
+	}
+	scramble := append([]byte{}, greeting[pos+4:pos+12]...)
+	serverCaps := uint32(binary.LittleEndian.Uint16(greeting[pos+13:pos+15])) |
+		uint32(binary.LittleEndian.Uint16(greeting[pos+18:pos+20]))<<16
+	rest := greeting[pos+31:]
+	// scramble2 tiene 12 bytes útiles terminados en NUL
+	if len(rest) >= 12 {
+		scramble = append(scramble, rest[:12]...)
+		rest = rest[12:]
+		if len(rest) > 0 && rest[0] == 0 {
+			rest = rest[1:]
+		}
+	}
+	plugin := "mysql_native_password"
+	if end := bytes.IndexByte(rest, 0); end > 0 {
+		plugin = string(rest[:end])
+	} else if len(rest) > 0 && end < 0 {
+		plugin = string(rest)
+	}
+	my.scramble = scramble
+	my.password = cfg.password
+
+	caps := uint32(mysqlClientLongPassword | mysqlClientProtocol41 | mysqlClientTransactions |
+		mysqlClientSecureConnection | mysqlClientPluginAuth | mysqlClientPluginAuthLenenc)
+	if cfg.Database != "" {
+		caps |= mysqlClientConnectWithDB
+	}
+
+	prefix := binary.LittleEndian.AppendUint32(nil, caps)
+	prefix = binary.LittleEndian.AppendUint32(prefix, mysqlMaxPacket)
+	prefix = append(prefix, mysqlCharset)
+	prefix = append(prefix, make([]byte, 23)...)
+
+	if cfg.TLS {
+		if serverCaps&mysqlClientSSL == 0 {
+			return fmt.Errorf("server does not support TLS")
+		}
+		caps |= mysqlClientSSL
+		binary.LittleEndian.PutUint32(prefix[0:4], caps)
+		if err := my.writePacket(prefix); err != nil {
+			return err
+		}
+		tlsConn, err := cfg.startTLS(ctx, my.conn)
+		if err != nil {
+			return err
+		}
+		my.conn = tlsConn
+		my.tls = true
+	}
+
+	authResp, err := my.authResponse(plugin, scramble)
+	if err != nil {
+		return err
+	}
+
+	resp := append(prefix, cfg.Username...)
+	resp = append(resp, 0)
+	resp = appendLenencInt(resp, uint64(len(authResp)))
+	resp = append(resp, authResp...)
+	if cfg.Database != "" {
+		resp = append(append(resp, cfg.Database...), 0)
+	}
+	resp = append(append(resp, plugin...), 0)
+	if err := my.writePacket(resp); err != nil {
+		return err
+	}
+
+	return my.authResult(plugin)
+}
+
+// authResponse calcula la respuesta del plugin de autenticación
+func (my *mysqlConn) authResponse(plugin string, scramble []byte) ([]byte, error) {
+	if my.password == "" {
+		return nil, nil
+	}
+	if len(scramble) < 20 {
+		return nil, fmt.Errorf("invalid auth scramble")
+	}
+
+	switch plugin {
+	case "mysql_native_password":
+		// SHA1(password) XOR SHA1(scramble + SHA1(SHA1(password)))
+		stage1 := sha1.Sum([]byte(my.password))
+		stage2 := sha1.Sum(stage1[:])
+		h := sha1.New()
+		h.Write(scramble[:20])
+		h.Write(stage2[:])
+		return xorBytes(stage1[:], h.Sum(nil)), nil
+	case "caching_sha2_password":
+		// SHA256(password) XOR SHA256(SHA256(SHA256(password)) + scramble)
+		stage1 := sha256.Sum256([]byte(my.password))
+		stage2 := sha256.Sum256(stage1[:])
+		h := sha256.New()
+		h.Write(stage2[:])
+		h.Write(scramble[:20])
+		return xorBytes(stage1[:], h.Sum(nil)), nil
+	case "mysql_clear_password":
+		if !my.tls {
+			return nil, fmt.Errorf("mysql_clear_password requires TLS")
+		}
+		return append([]byte(my.password), 0), nil
+	}
+	return nil, fmt.Errorf("unsupported authentication plugin %s", plugin)
+}
+
+// authResult procesa OK, ERR, AuthSwitchRequest y el intercambio de caching_sha2_password
+func (my *mysqlConn) authResult(plugin string) error {
+	for {
+		packet, err := my.readPacket()
+		if err != nil {
+			return err
+		}
+		if len(packet) == 0 {
+			return fmt.Errorf("empty authentication packet")
+		}
+
+		switch packet[0] {
+		case 0x00:
+			return nil
+		case 0xff:
+			return mysqlError(packet)
+		case 0xfe:
+			// AuthSwitchRequest: plugin NUL scramble
+			end := bytes.IndexByte(packet[1:], 0)
+			if end < 0 {
+				return fmt.Errorf("invalid auth switch request")
+			}
+			plugin = string(packet[1 : 1+end])
+			my.scramble = bytes.TrimRight(packet[2+end:], "\x00")
+			resp, err := my.authResponse(plugin, my.scramble)
+			if err != nil {
+				return err
+			}
+			if err := my.writePacket(resp); err != nil {
+				return err
+			}
+		case 0x01:
+			if plugin != "caching_sha2_password" || len(packet) < 2 {
+				return fmt.Errorf("unexpected auth data for %s", plugin)
+			}
+			switch packet[1] {
+			case 3: // fast auth correcta, sigue un OK
+			case 4: // autenticación completa
+				if err := my.fullAuth(); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("unexpected caching_sha2_password state %d", packet[1])
+			}
+		default:
+			return fmt.Errorf("unexpected authentication packet 0x%02x", packet[0])
+		}
+	}
+}
+
+// fullAuth envía la contraseña en claro sobre TLS o cifrada con la clave RSA del servidor
+func (my *mysqlConn) fullAuth() error {
+	if my.tls {
+		return my.writePacket(append([]byte(my.password), 0))
+	}
+
+	if err := my.writePacket([]byte{0x02}); err != nil {
+		return err
+	}
+	packet, err := my.readPacket()
+	if err != nil {
+		return err
+	}
+	if len(packet) < 2 || packet[0] != 0x01 {
+		return fmt.Errorf("server did not send its public key")
+	}
+
+	block, _ := pem.Decode(packet[1:])
+	if block == nil {
+		return fmt.Errorf("invalid server public key")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("invalid server public key: %w", err)
+	}
+	pub, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("server public key is not RSA")
+	}
+
+	plain := append([]byte(my.password), 0)
+	for i := range plain {
+		plain[i] ^= my.scramble[i%len(my.scramble)]
+	}
+	encrypted, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, pub, plain, nil)
+	if err != nil {
+		return err
+	}
+	return my.writePacket(encrypted)
+}
+
+// query ejecuta COM_QUERY y retorna nombres de columna y filas (text protocol)
+func (my *mysqlConn) query(sql string) ([]string, [][]*string, error) {
+	my.seq = 0
+	if err := my.writePacket(append([]byte{mysqlComQuery}, sql...)); err != nil {
+		return nil, nil, err
+	}
+
+	packet, err := my.readPacket()
+	if err != nil {
+		return nil, nil, err
+	}
+	switch {
+	case len(packet) == 0:
+		return nil, nil, fmt.Errorf("empty response")
+	case packet[0] == 0x00:
+		return nil, nil, nil // sin resultado (OK)
+	case packet[0] == 0xff:
+		return nil, nil, mysqlError(packet)
+	}
+
+	count, _ := readLenencInt(packet)
+	columns := make([]string, 0, count)
+	for i := uint64(0); i < count; i++ {
+		def, err := my.readPacket()
+		if err != nil {
+			return nil, nil, err
+		}
+		// catalog, schema, table, org_table, name
+		var name *string
+		for field := 0; field < 5; field++ {
+			name, def = readLenencString(def)
+		}
+		if name != nil {
+			columns = append(columns, *name)
+		} else {
+			columns = append(columns, "")
+		}
+	}
+	if _, err := my.readPacket(); err != nil { // EOF tras las columnas
+		return nil, nil, err
+	}
+
+	var rows [][]*string
+	for {
+		packet, err := my.readPacket()
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(packet) > 0 && packet[0] == 0xff {
+			return nil, nil, mysqlError(packet)
+		}
+		if len(packet) < 9 && len(packet) > 0 && packet[0] == 0xfe {
+			return columns, rows, nil
+		}
+
+		row := make([]*string, 0, len(columns))
+		for range columns {
+			var value *string
+			value, packet = readLenencString(packet)
+			row = append(row, value)
+		}
+		rows = append(rows, row)
+	}
+}
+
+// replicationStatus obtiene rol y retraso con SHOW REPLICA STATUS (o SHOW SLAVE STATUS en versiones antiguas)
+func (my *mysqlConn) replicationStatus(st *dbStatus) error {
+	columns, rows, err := my.query("SHOW REPLICA STATUS")
+	if err != nil {
+		columns, rows, err = my.query("SHOW SLAVE STATUS")
+		if err != nil {
+			return fmt.Errorf("cannot read replication status: %w", err)
+		}
+	}
+
+	st.Role = "primary"
+	if len(rows) == 0 {
+		return nil
+	}
+	st.Role = "replica"
+
+	values := make(map[string]*string, len(columns))
+	for i, col := range columns {
+		if i < len(rows[0]) {
+			values[col] = rows[0][i]
+		}
+	}
+
+	lag := values["Seconds_Behind_Source"]
+	if lag == nil {
+		lag = values["Seconds_Behind_Master"]
+	}
+	if lag == nil {
+		// NULL indica que los hilos de replicación no están corriendo
+		return fmt.Errorf("replication is not running")
+	}
+	if v, err := strconv.ParseFloat(*lag, 64); err == nil {
+		st.Lag = &v
+	}
+	return nil
+}
+
+// quit cierra la sesión de forma ordenada
+func (my *mysqlConn) quit() {
+	my.seq = 0
+	my.writePacket([]byte{mysqlComQuit})
+}
+
+// mysqlError decodifica un paquete ERR
+func mysqlError(packet []byte) error {
+	if len(packet) < 3 {
+		return fmt.Errorf("mysql error")
+	}
+	code := binary.LittleEndian.Uint16(packet[1:3])
+	msg := packet[3:]
+	if len(msg) > 6 && msg[0] == '#' {
+		msg = msg[6:]
+	}
+	return fmt.Errorf("error %d: %s", code, msg)
+}
+
+// appendLenencInt codifica un entero de longitud variable
+func appendLenencInt(b []byte, n uint64) []byte {
+	switch {
+	case n < 251:
+		return append(b, byte(n))
+	case n < 1<<16:
+		return append(b, 0xfc, byte(n), byte(n>>8))
+	case n < 1<<24:
+		return append(b, 0xfd, byte(n), byte(n>>8), byte(n>>16))
+	}
+	return binary.LittleEndian.AppendUint64(append(b, 0xfe), n)
+}
+
+// readLenencInt decodifica un entero de longitud variable y retorna los bytes consumidos
+func readLenencInt(b []byte) (uint64, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	switch b[0] {
+	case 0xfc:
+		if len(b) < 3 {
+			return 0, len(b)
+		}
+		return uint64(binary.LittleEndian.Uint16(b[1:3])), 3
+	case 0xfd:
+		if len(b) < 4 {
+			return 0, len(b)
+		}
+		return uint64(b[1]) | uint64(b[2])<<8 | uint64(b[3])<<16, 4
+	case 0xfe:
+		if len(b) < 9 {
+			return 0, len(b)
+		}
+		return binary.LittleEndian.Uint64(b[1:9]), 9
+	}
+	return uint64(b[0]), 1
+}
+
+// readLenencString decodifica una cadena de longitud variable (0xfb = NULL)
+func readLenencString(b []byte) (*string, []byte) {
+	if len(b) == 0 {
+		return nil, b
+	}
+	if b[0] == 0xfb {
+		return nil, b[1:]
+	}
+	size, n := readLenencInt(b)
+	if uint64(len(b)-n) < size {
+		return nil, nil
+	}
+	value := string(b[n : n+int(size)])
+	return &value, b[n+int(size):]
+}
+
+// xorBytes XOR byte a byte de dos slices de igual longitud
+func xorBytes(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
@@ -0,0 +1,188 @@
+package checks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// mysqlResult conjunto de resultados que devuelve el servidor falso
+type mysqlResult struct {
+	columns []string
+	rows    [][]*string
+}
+
+// fakeMySQL servidor mínimo: handshake v10 con mysql_native_password y text protocol
+type fakeMySQL struct {
+	password string
+	results  map[string]mysqlResult
+}
+
+func (f *fakeMySQL) serve(conn net.Conn) {
+	my := &mysqlConn{conn: conn}
+	scramble := []byte("abcdefghijklmnopqrst")
+
+	greeting := append([]byte{10}, "8.0.36-fake\x00"...)
+	greeting = binary.LittleEndian.AppendUint32(greeting, 1)
+	greeting = append(append(greeting, scramble[:8]...), 0)
+	greeting = binary.LittleEndian.AppendUint16(greeting, uint16(mysqlClientProtocol41|mysqlClientSecureConnection))
+	greeting = append(greeting, mysqlCharset, 2, 0)
+	greeting = binary.LittleEndian.AppendUint16(greeting, uint16(mysqlClientPluginAuth>>16))
+	greeting = append(greeting, 21)
+	greeting = append(greeting, make([]byte, 10)...)
+	greeting = append(append(greeting, scramble[8:]...), 0)
+	greeting = append(greeting, "mysql_native_password\x00"...)
+	if my.writePacket(greeting) != nil {
+		return
+	}
+
+	resp, err := my.readPacket()
+	if err != nil || len(resp) < 33 {
+		return
+	}
+	rest := resp[32:]
+	user := string(rest[:bytes.IndexByte(rest, 0)])
+	rest = rest[len(user)+1:]
+	size, n := readLenencInt(rest)
+	authResp := rest[n : n+int(size)]
+
+	expected, _ := (&mysqlConn{password: f.password}).authResponse("mysql_native_password", scramble)
+	if !bytes.Equal(authResp, expected) {
+		my.writePacket(append([]byte{0xff, 0x15, 0x04}, "#28000Access denied for user '"+user+"'"...))
+		return
+	}
+	my.writePacket([]byte{0x00, 0, 0, 2, 0, 0, 0})
+
+	for {
+		packet, err := my.readPacket()
+		if err != nil || len(packet) == 0 || packet[0] == mysqlComQuit {
+			return
+		}
+		result, ok := f.results[string(packet[1:])]
+		if !ok {
+			my.writePacket(append([]byte{0xff, 0x28, 0x04}, "#42000You have an error in your SQL syntax"...))
+			continue
+		}
+		f.writeResult(my, result)
+	}
+}
+
+// writeResult envía columnas, EOF, filas y EOF
+func (f *fakeMySQL) writeResult(my *mysqlConn, result mysqlResult) {
+	eof := []byte{0xfe, 0, 0, 2, 0}
+	my.writePacket(appendLenencInt(nil, uint64(len(result.columns))))
+	for _, col := range result.columns {
+		var def []byte
+		for _, field := range []string{"def", "", "", "", col, col} {
+			def = appendLenencInt(def, uint64(len(field)))
+			def = append(def, field...)
+		}
+		my.writePacket(append(append(def, 0x0c), make([]byte, 12)...))
+	}
+	my.writePacket(eof)
+	for _, row := range result.rows {
+		var data []byte
+		for _, value := range row {
+			if value == nil {
+				data = append(data, 0xfb)
+				continue
+			}
+			data = appendLenencInt(data, uint64(len(*value)))
+			data = append(data, *value...)
+		}
+		my.writePacket(data)
+	}
+	my.writePacket(eof)
+}
+
+// mysqlRow construye una fila; "NULL" se envía como NULL
+func mysqlRow(values ...string) []*string {
+	row := make([]*string, 0, len(values))
+	for _, v := range values {
+		if v == "NULL" {
+			row = append(row, nil)
+			continue
+		}
+		value := v
+		row = append(row, &value)
+	}
+	return row
+}
+
+var mysqlSelectOne = mysqlResult{columns: []string{"1"}, rows: [][]*string{mysqlRow("1")}}
+
+func newMySQLTestChecker(t *testing.T, cfg *config.DatabaseCheck) *MySQLChecker {
+	t.Helper()
+	if cfg.Username == "" {
+		cfg.Username = "watchdog"
+	}
+	checker, err := NewMySQLChecker(cfg)
+	if err != nil {
+		t.Fatalf("NewMySQLChecker: %v", err)
+	}
+	return checker
+}
+
+func TestMySQLProbe(t *testing.T) {
+	addr := fakeServer(t, (&fakeMySQL{results: map[string]mysqlResult{"SELECT 1": mysqlSelectOne}}).serve)
+	expectHealthy(t, runCheck(t, newMySQLTestChecker(t, &config.DatabaseCheck{Address: addr})))
+
+	result := runCheck(t, newMySQLTestChecker(t, &config.DatabaseCheck{Address: addr, Query: "SELEC 1"}))
+	expectFailure(t, result, "error 1064")
+}
+
+func TestMySQLAuth(t *testing.T) {
+	addr := fakeServer(t, (&fakeMySQL{password: "secret", results: map[string]mysqlResult{"SELECT 1": mysqlSelectOne}}).serve)
+
+	t.Setenv("MYSQL_TEST_PASSWORD", "secret")
+	expectHealthy(t, runCheck(t, newMySQLTestChecker(t, &config.DatabaseCheck{Address: addr, PasswordEnv: "MYSQL_TEST_PASSWORD"})))
+
+	t.Setenv("MYSQL_TEST_PASSWORD", "wrong")
+	result := runCheck(t, newMySQLTestChecker(t, &config.DatabaseCheck{Address: addr, PasswordEnv: "MYSQL_TEST_PASSWORD"}))
+	expectFailure(t, result, "error 1045: Access denied")
+}
+
+func TestMySQLReplication(t *testing.T) {
+	status := []string{"Replica_IO_Running", "Seconds_Behind_Source"}
+	replica := &fakeMySQL{results: map[string]mysqlResult{
+		"SELECT 1":            mysqlSelectOne,
+		"SHOW REPLICA STATUS": {columns: status, rows: [][]*string{mysqlRow("Yes", "25")}},
+	}}
+	addr := fakeServer(t, replica.serve)
+
+	result := runCheck(t, newMySQLTestChecker(t, &config.DatabaseCheck{Address: addr, ExpectedRole: "replica", MaxReplicationLagSeconds: 10}))
+	expectDegraded(t, result, "replication lag 25.0s > 10.0s")
+
+	result = runCheck(t, newMySQLTestChecker(t, &config.DatabaseCheck{Address: addr, ExpectedRole: "primary"}))
+	expectFailure(t, result, "is replica, expected primary")
+
+	// Versiones antiguas solo aceptan SHOW SLAVE STATUS; NULL indica replicación parada
+	stopped := &fakeMySQL{results: map[string]mysqlResult{
+		"SELECT 1":          mysqlSelectOne,
+		"SHOW SLAVE STATUS": {columns: []string{"Slave_IO_Running", "Seconds_Behind_Master"}, rows: [][]*string{mysqlRow("No", "NULL")}},
+	}}
+	addr = fakeServer(t, stopped.serve)
+	result = runCheck(t, newMySQLTestChecker(t, &config.DatabaseCheck{Address: addr, ExpectedRole: "replica"}))
+	expectFailure(t, result, "replication is not running")
+
+	primary := &fakeMySQL{results: map[string]mysqlResult{
+		"SELECT 1":            mysqlSelectOne,
+		"SHOW REPLICA STATUS": {columns: status},
+	}}
+	addr = fakeServer(t, primary.serve)
+	expectHealthy(t, runCheck(t, newMySQLTestChecker(t, &config.DatabaseCheck{Address: addr, ExpectedRole: "primary"})))
+}
+
+func TestMySQLConnections(t *testing.T) {
+	addr := fakeServer(t, (&fakeMySQL{results: map[string]mysqlResult{
+		"SELECT 1": mysqlSelectOne,
+		"SHOW GLOBAL STATUS LIKE 'Threads_connected'": {columns: []string{"Variable_name", "Value"}, rows: [][]*string{mysqlRow("Threads_connected", "151")}},
+		"SELECT @@max_connections":                    {columns: []string{"@@max_connections"}, rows: [][]*string{mysqlRow("151")}},
+	}}).serve)
+
+	result := runCheck(t, newMySQLTestChecker(t, &config.DatabaseCheck{Address: addr, MaxConnectionsPercent: 90}))
+	expectDegraded(t, result, "connections 151/151")
+}
@@ -0,0 +1,259 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/process"
+)
+
+// Códigos de salida del API de plugins Nagios
+const (
+	nagiosOK       = 0
+	nagiosWarning  = 1
+	nagiosCritical = 2
+	nagiosUnknown  = 3
+)
+
+var nagiosStatusNames = map[int]string{
+	nagiosOK:       "OK",
+	nagiosWarning:  "WARNING",
+	nagiosCritical: "CRITICAL",
+	nagiosUnknown:  "UNKNOWN",
+}
+
+var rePerfValue = regexp.MustCompile(`^(-?[0-9]*[.,]?[0-9]+(?:[eE][-+]?[0-9]+)?)([a-zA-Z%]*)$`)
+
+// NagiosPluginChecker ejecuta un plugin compatible con Nagios/Monitoring-Plugins
+type NagiosPluginChecker struct {
+	Path           string
+	Args           []string
+	TreatUnknownAs string
+	Options        config.ExecOptions
+}
+
+// NewNagiosPluginChecker crea un nuevo nagios plugin checker
+func NewNagiosPluginChecker(cfg *config.NagiosPluginCheck) (*NagiosPluginChecker, error) {
+	if cfg == nil || cfg.Path == "" {
+		return nil, fmt.Errorf("nagios_plugin check requires path")
+	}
+
+	treatUnknownAs := cfg.TreatUnknownAs
+	if treatUnknownAs == "" {
+		treatUnknownAs = "critical"
+	}
+
+	return &NagiosPluginChecker{
+		Path:           cfg.Path,
+		Args:           cfg.Args,
+		TreatUnknownAs: treatUnknownAs,
+		Options:        cfg.ExecOptions,
+	}, nil
+}
+
+func (c *NagiosPluginChecker) Name() string {
+	return fmt.Sprintf("nagios_plugin:%s", c.Path)
+}
+
+func (c *NagiosPluginChecker) Check(ctx context.Context) Result {
+	start := time.Now()
+
+	output := process.NewCappedBuffer(c.Options.MaxOutputBytes)
+	cmd, err := process.Command(ctx, &c.Options, c.Path, c.Args...)
+	if err == nil {
+		cmd.Stdout = output
+		cmd.Stderr = output
+		err = cmd.Run()
+	}
+	latency := time.Since(start)
+
+	exitCode := 0
+	if err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return Result{
+				Success:   false,
+				Message:   fmt.Sprintf("failed to execute plugin: %v", err),
+				Latency:   latency,
+				CheckType: "nagios_plugin",
+			}
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	text, longOutput, metrics := ParseNagiosOutput(output.String())
+
+	status, known := nagiosStatusNames[exitCode]
+	if !known {
+		// Códigos fuera del API se tratan como UNKNOWN
+		status = nagiosStatusNames[nagiosUnknown]
+		exitCode = nagiosUnknown
+	}
+
+	details := map[string]interface{}{
+		"status":    status,
+		"exit_code": exitCode,
+	}
+	if longOutput != "" {
+		details["long_output"] = longOutput
+	}
+	if output.Truncated {
+		details["output_truncated"] = true
+	}
+
+	if text == "" {
+		text = "(no output)"
+	}
+
+	result := Result{
+		Success:   true,
+		Message:   fmt.Sprintf("%s: %s", status, text),
+		Latency:   latency,
+		CheckType: "nagios_plugin",
+		Details:   details,
+		Metrics:   metrics,
+	}
+
+	switch exitCode {
+	case nagiosWarning:
+		result.Degraded = true
+	case nagiosCritical:
+		result.Success = false
+	case nagiosUnknown:
+		if c.TreatUnknownAs == "warning" {
+			result.Degraded = true
+		} else {
+			result.Success = false
+		}
+	}
+
+	return result
+}
+
+// ParseNagiosOutput separa la salida de un plugin en texto de estado, long output y perfdata.
+// Formato: "TEXTO | perfdata\nLONG OUTPUT\n... | más perfdata\nmás perfdata"
+func ParseNagiosOutput(output string) (string, string, []Metric) {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return "", "", nil
+	}
+
+	lines := strings.Split(output, "\n")
+	perfParts := []string{}
+
+	text, perf, hasPerf := strings.Cut(lines[0], "|")
+	text = strings.TrimSpace(text)
+	if hasPerf {
+		perfParts = append(perfParts, perf)
+	}
+
+	longLines := []string{}
+	inPerf := false
+	for _, line := range lines[1:] {
+		if inPerf {
+			perfParts = append(perfParts, line)
+			continue
+		}
+		before, after, found := strings.Cut(line, "|")
+		longLines = append(longLines, before)
+		if found {
+			perfParts = append(perfParts, after)
+			inPerf = true
+		}
+	}
+
+	longOutput := strings.TrimSpace(strings.Join(longLines, "\n"))
+	return text, longOutput, ParsePerfdata(strings.Join(perfParts, " "))
+}
+
+// ParsePerfdata parsea "'label'=value[UOM];[warn];[crit];[min];[max]" separados por espacios
+func ParsePerfdata(perf string) []Metric {
+	metrics := []Metric{}
+
+	for _, item := range splitPerfdata(perf) {
+		eq := strings.LastIndex(item, "=")
+		if eq <= 0 {
+			continue
+		}
+		label := strings.ReplaceAll(strings.Trim(item[:eq], "'"), "''", "'")
+
+		fields := strings.Split(item[eq+1:], ";")
+		m := rePerfValue.FindStringSubmatch(fields[0])
+		if m == nil {
+			continue // "U" u otro valor indeterminado
+		}
+		value, err := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64)
+		if err != nil {
+			continue
+		}
+
+		metric := Metric{Name: label, Value: value, Unit: m[2]}
+		if len(fields) > 1 {
+			metric.Warn = fields[1]
+		}
+		if len(fields) > 2 {
+			metric.Crit = fields[2]
+		}
+		if len(fields) > 3 {
+			metric.Min = parseOptionalFloat(fields[3])
+		}
+		if len(fields) > 4 {
+			metric.Max = parseOptionalFloat(fields[4])
+		}
+		metrics = append(metrics, metric)
+	}
+
+	return metrics
+}
+
+// splitPerfdata separa los items de perfdata respetando labels entre comillas simples
+func splitPerfdata(perf string) []string {
+	items := []string{}
+	var current strings.Builder
+	inQuote := false
+
+	for i := 0; i < len(perf); i++ {
+		ch := perf[i]
+		switch {
+		case ch == '\'':
+			// '' dentro de comillas es una comilla escapada
+			if inQuote && i+1 < len(perf) && perf[i+1] == '\'' {
+				current.WriteString("''")
+				i++
+				continue
+			}
+			inQuote = !inQuote
+			current.WriteByte(ch)
+		case (ch == ' ' || ch == '\t' || ch == '\n') && !inQuote:
+			if current.Len() > 0 {
+				items = append(items, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	if current.Len() > 0 {
+		items = append(items, current.String())
+	}
+	return items
+}
+
+// parseOptionalFloat retorna nil si el campo está vacío o no es numérico
+func parseOptionalFloat(s string) *float64 {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	v, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,74 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/plugins"
+)
+
+// PluginChecker ejecuta un check externo mediante el protocolo JSON sobre stdin/stdout
+type PluginChecker struct {
+	Plugin *config.PluginConfig
+}
+
+// NewPluginChecker crea un nuevo plugin checker
+func NewPluginChecker(cfg *config.PluginConfig) (*PluginChecker, error) {
+	if cfg == nil || cfg.Name == "" {
+		return nil, fmt.Errorf("plugin check requires name")
+	}
+	return &PluginChecker{Plugin: cfg}, nil
+}
+
+func (c *PluginChecker) Name() string {
+	return fmt.Sprintf("plugin:%s", c.Plugin.Name)
+}
+
+func (c *PluginChecker) Check(ctx context.Context) Result {
+	start := time.Now()
+
+	req := plugins.Request{
+		Command: plugins.CommandCheck,
+		Target:  c.Plugin.Target,
+		Config:  c.Plugin.Config,
+	}
+
+	var resp plugins.CheckResult
+	if err := plugins.Call(ctx, c.Plugin.Dir, c.Plugin.Name, req, &resp); err != nil {
+		return Result{
+			Success:   false,
+			Message:   err.Error(),
+			Latency:   time.Since(start),
+			CheckType: "plugin",
+		}
+	}
+
+	metrics := make([]Metric, 0, len(resp.Metrics))
+	for _, m := range resp.Metrics {
+		metrics = append(metrics, Metric{Name: m.Name, Value: m.Value, Unit: m.Unit})
+	}
+
+	result := Result{
+		Success:   true,
+		Message:   resp.Message,
+		Latency:   time.Since(start),
+		CheckType: "plugin",
+		Details:   resp.Details,
+		Metrics:   metrics,
+	}
+
+	switch resp.Status {
+	case "ok":
+	case "warning":
+		result.Degraded = true
+	case "critical", "unknown":
+		result.Success = false
+	default:
+		result.Success = false
+		result.Message = fmt.Sprintf("plugin %s returned invalid status '%s': %s", c.Plugin.Name, resp.Status, resp.Message)
+	}
+
+	return result
+}
@@ -0,0 +1,465 @@
+package checks
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// Códigos del protocolo v3 de PostgreSQL
+const (
+	pgProtocolVersion = 196608   // 3.0
+	pgSSLRequestCode  = 80877103 // SSLRequest
+
+	pgAuthOK           = 0
+	pgAuthCleartext    = 3
+	pgAuthMD5          = 5
+	pgAuthSASL         = 10
+	pgAuthSASLContinue = 11
+	pgAuthSASLFinal    = 12
+)
+
+// Sondas de rol, replicación y conexiones
+const (
+	pgRoleQuery = "SELECT pg_is_in_recovery()"
+	pgLagQuery  = "SELECT CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0 " +
+		"ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) END"
+	pgConnectionsQuery = "SELECT count(*), current_setting('max_connections') FROM pg_stat_activity"
+)
+
+// PostgresChecker verifica PostgreSQL con el protocolo v3: autenticación y consulta de prueba
+type PostgresChecker struct {
+	cfg *dbConfig
+}
+
+// NewPostgresChecker crea un nuevo postgres checker
+func NewPostgresChecker(cfg *config.DatabaseCheck) (*PostgresChecker, error) {
+	dbCfg, err := newDBConfig("postgres", cfg, "5432")
+	if err != nil {
+		return nil, err
+	}
+	if dbCfg.Username == "" {
+		return nil, fmt.Errorf("postgres check requires username")
+	}
+	if dbCfg.network == "unix" && !strings.Contains(dbCfg.address, ".s.PGSQL.") {
+		// Se acepta el directorio del socket, como en libpq
+		dbCfg.address = strings.TrimSuffix(dbCfg.address, "/") + "/.s.PGSQL.5432"
+	}
+	return &PostgresChecker{cfg: dbCfg}, nil
+}
+
+func (c *PostgresChecker) Name() string {
+	return fmt.Sprintf("postgres:%s", c.cfg.address)
+}
+
+func (c *PostgresChecker) Check(ctx context.Context) Result {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.timeout)
+	defer cancel()
+
+	expect := c.cfg.Expect
+	if c.cfg.Query == "" && expect == "" {
+		expect = "1"
+	}
+
+	st, err := c.probe(ctx)
+	if err != nil {
+		return Result{
+			Success:   false,
+			Message:   fmt.Sprintf("postgres %s: %v", c.cfg.address, err),
+			Latency:   time.Since(start),
+			CheckType: "postgres",
+		}
+	}
+	return evaluateDatabase("postgres", "postgres "+c.cfg.address, c.cfg, expect, st, time.Since(start))
+}
+
+// probe conecta, se autentica y ejecuta las consultas configuradas
+func (c *PostgresChecker) probe(ctx context.Context) (*dbStatus, error) {
+	if err := c.cfg.resolvePassword(); err != nil {
+		return nil, err
+	}
+
+	conn, err := c.cfg.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if c.cfg.TLS {
+		if conn, err = c.startTLS(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	pg := &pgConn{conn: conn, r: bufio.NewReader(conn)}
+	defer pg.terminate()
+
+	if err := pg.startup(c.cfg.Username, c.cfg.Database, c.cfg.password); err != nil {
+		return nil, err
+	}
+
+	query := c.cfg.Query
+	if query == "" {
+		query = "SELECT 1"
+	}
+	rows, err := pg.query(query)
+	if err != nil {
+		return nil, fmt.Errorf("probe query failed: %w", err)
+	}
+	st := &dbStatus{Value: firstValue(rows)}
+
+	if c.cfg.wantsRole() {
+		rows, err := pg.query(pgRoleQuery)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read role: %w", err)
+		}
+		st.Role = "primary"
+		if firstValue(rows) == "t" {
+			st.Role = "replica"
+			rows, err := pg.query(pgLagQuery)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read replication lag: %w", err)
+			}
+			if lag, err := strconv.ParseFloat(firstValue(rows), 64); err == nil {
+				st.Lag = &lag
+			}
+		}
+	}
+
+	if c.cfg.MaxConnectionsPercent > 0 {
+		rows, err := pg.query(pgConnectionsQuery)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read connections: %w", err)
+		}
+		if len(rows) > 0 && len(rows[0]) == 2 && rows[0][0] != nil && rows[0][1] != nil {
+			st.Connections, _ = strconv.Atoi(*rows[0][0])
+			st.MaxConnections, _ = strconv.Atoi(*rows[0][1])
+		}
+	}
+
+	return st, nil
+}
+
+// startTLS negocia SSL con SSLRequest antes del mensaje de arranque
+func (c *PostgresChecker) startTLS(ctx context.Context, conn net.Conn) (net.Conn, error) {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint32(msg[0:4], 8)
+	binary.BigEndian.PutUint32(msg[4:8], pgSSLRequestCode)
+	if _, err := conn.Write(msg); err != nil {
+		return nil, err
+	}
+
+	reply := make([]byte, 1)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return nil, err
+	}
+	if reply[0] != 'S' {
+		return nil, fmt.Errorf("server does not support TLS")
+	}
+	return c.cfg.startTLS(ctx, conn)
+}
+
+// firstValue primera columna de la primera fila ("" si no hay o es NULL)
+func firstValue(rows [][]*string) string {
+	if len(rows) == 0 || len(rows[0]) == 0 || rows[0][0] == nil {
+		return ""
+	}
+	return *rows[0][0]
+}
+
+// pgConn cliente mínimo del protocolo v3 (simple query)
+type pgConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// send escribe un mensaje tipado: tipo + longitud + payload
+func (pg *pgConn) send(msgType byte, payload []byte) error {
+	msg := make([]byte, 5+len(payload))
+	msg[0] = msgType
+	binary.BigEndian.PutUint32(msg[1:5], uint32(4+len(payload)))
+	copy(msg[5:], payload)
+	_, err := pg.conn.Write(msg)
+	return err
+}
+
+// receive lee un mensaje del backend
+func (pg *pgConn) receive() (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(pg.r, header); err != nil {
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header[1:5])
+	if size < 4 || size > 64*1024*1024 {
+		return 0, nil, fmt.Errorf("invalid message length %d", size)
+	}
+	payload := make([]byte, size-4)
+	if _, err := io.ReadFull(pg.r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[0], payload, nil
+}
+
+// startup envía el mensaje de arranque y completa la autenticación
+func (pg *pgConn) startup(user, database, password string) error {
+	params := []string{"user", user, "application_name", "neon-watchdog"}
+	if database != "" {
+		params = append(params, "database", database)
+	}
+
+	payload := binary.BigEndian.AppendUint32(nil, pgProtocolVersion)
+	for _, p := range params {
+		payload = append(append(payload, p...), 0)
+	}
+	payload = append(payload, 0)
+
+	msg := binary.BigEndian.AppendUint32(nil, uint32(4+len(payload)))
+	if _, err := pg.conn.Write(append(msg, payload...)); err != nil {
+		return err
+	}
+
+	var scram *scramClient
+	for {
+		msgType, data, err := pg.receive()
+		if err != nil {
+			return err
+		}
+
+		switch msgType {
+		case 'E':
+			return pgError(data)
+		case 'R':
+			if len(data) < 4 {
+				return fmt.Errorf("invalid authentication message")
+			}
+			code := binary.BigEndian.Uint32(data[0:4])
+			switch code {
+			case pgAuthOK:
+			case pgAuthCleartext:
+				if err := pg.send('p', append([]byte(password), 0)); err != nil {
+					return err
+				}
+			case pgAuthMD5:
+				if len(data) < 8 {
+					return fmt.Errorf("invalid md5 salt")
+				}
+				if err := pg.send('p', append([]byte(pgMD5Password(user, password, data[4:8])), 0)); err != nil {
+					return err
+				}
+			case pgAuthSASL:
+				if !strings.Contains(string(data[4:]), "SCRAM-SHA-256\x00") {
+					return fmt.Errorf("server requires unsupported SASL mechanism")
+				}
+				scram = newSCRAMClient(password)
+				first := scram.clientFirst()
+				payload := append([]byte("SCRAM-SHA-256"), 0)
+				payload = binary.BigEndian.AppendUint32(payload, uint32(len(first)))
+				if err := pg.send('p', append(payload, first...)); err != nil {
+					return err
+				}
+			case pgAuthSASLContinue:
+				if scram == nil {
+					return fmt.Errorf("unexpected SASL continue")
+				}
+				final, err := scram.clientFinal(string(data[4:]))
+				if err != nil {
+					return err
+				}
+				if err := pg.send('p', []byte(final)); err != nil {
+					return err
+				}
+			case pgAuthSASLFinal:
+				if scram == nil || !scram.verifyServer(string(data[4:])) {
+					return fmt.Errorf("invalid SCRAM server signature")
+				}
+			default:
+				return fmt.Errorf("unsupported authentication method %d", code)
+			}
+		case 'Z':
+			return nil
+		}
+		// 'S' ParameterStatus, 'K' BackendKeyData y 'N' NoticeResponse se ignoran
+	}
+}
+
+// query ejecuta una consulta simple y retorna las filas de su resultado
+func (pg *pgConn) query(sql string) ([][]*string, error) {
+	if err := pg.send('Q', append([]byte(sql), 0)); err != nil {
+		return nil, err
+	}
+
+	var rows [][]*string
+	var queryErr error
+	for {
+		msgType, data, err := pg.receive()
+		if err != nil {
+			return nil, err
+		}
+
+		switch msgType {
+		case 'D':
+			row, err := parsePGDataRow(data)
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		case 'E':
+			queryErr = pgError(data)
+		case 'Z':
+			return rows, queryErr
+		}
+	}
+}
+
+// terminate cierra la sesión de forma ordenada
+func (pg *pgConn) terminate() {
+	pg.send('X', nil)
+}
+
+// parsePGDataRow decodifica un DataRow en formato texto
+func parsePGDataRow(data []byte) ([]*string, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("invalid DataRow")
+	}
+	count := int(binary.BigEndian.Uint16(data[0:2]))
+	data = data[2:]
+
+	row := make([]*string, 0, count)
+	for i := 0; i < count; i++ {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("truncated DataRow")
+		}
+		size := int32(binary.BigEndian.Uint32(data[0:4]))
+		data = data[4:]
+		if size < 0 {
+			row = append(row, nil)
+			continue
+		}
+		if int(size) > len(data) {
+			return nil, fmt.Errorf("truncated DataRow")
+		}
+		value := string(data[:size])
+		row = append(row, &value)
+		data = data[size:]
+	}
+	return row, nil
+}
+
+// pgError convierte un ErrorResponse en error con su SQLSTATE
+func pgError(data []byte) error {
+	fields := make(map[byte]string)
+	for len(data) > 1 {
+		code := data[0]
+		end := strings.IndexByte(string(data[1:]), 0)
+		if end < 0 {
+			break
+		}
+		fields[code] = string(data[1 : 1+end])
+		data = data[end+2:]
+	}
+	return fmt.Errorf("%s: %s (SQLSTATE %s)", fields['S'], fields['M'], fields['C'])
+}
+
+// pgMD5Password calcula "md5" + md5(md5(password + user) + salt)
+func pgMD5Password(user, password string, salt []byte) string {
+	inner := md5.Sum([]byte(password + user))
+	outer := md5.Sum(append([]byte(hex.EncodeToString(inner[:])), salt...))
+	return "md5" + hex.EncodeToString(outer[:])
+}
+
+// scramClient implementa SCRAM-SHA-256 (RFC 7677) sin channel binding
+type scramClient struct {
+	password        string
+	nonce           string
+	clientFirstBare string
+	authMessage     string
+	saltedPassword  []byte
+}
+
+func newSCRAMClient(password string) *scramClient {
+	raw := make([]byte, 18)
+	rand.Read(raw)
+	return &scramClient{password: password, nonce: base64.RawStdEncoding.EncodeToString(raw)}
+}
+
+// clientFirst mensaje inicial; el usuario va en el mensaje de arranque
+func (s *scramClient) clientFirst() string {
+	s.clientFirstBare = "n=,r=" + s.nonce
+	return "n,," + s.clientFirstBare
+}
+
+// clientFinal calcula la prueba a partir de "r=...,s=...,i=..."
+func (s *scramClient) clientFinal(serverFirst string) (string, error) {
+	attrs := scramAttributes(serverFirst)
+	nonce, salt64, iter := attrs["r"], attrs["s"], attrs["i"]
+	if !strings.HasPrefix(nonce, s.nonce) {
+		return "", fmt.Errorf("invalid SCRAM server nonce")
+	}
+	salt, err := base64.StdEncoding.DecodeString(salt64)
+	if err != nil {
+		return "", fmt.Errorf("invalid SCRAM salt")
+	}
+	iterations, err := strconv.Atoi(iter)
+	if err != nil || iterations <= 0 {
+		return "", fmt.Errorf("invalid SCRAM iteration count")
+	}
+
+	s.saltedPassword, err = pbkdf2.Key(sha256.New, s.password, salt, iterations, sha256.Size)
+	if err != nil {
+		return "", err
+	}
+
+	finalWithoutProof := "c=biws,r=" + nonce
+	s.authMessage = s.clientFirstBare + "," + serverFirst + "," + finalWithoutProof
+
+	clientKey := hmacSHA256(s.saltedPassword, "Client Key")
+	storedKey := sha256.Sum256(clientKey)
+	signature := hmacSHA256(storedKey[:], s.authMessage)
+	proof := make([]byte, len(clientKey))
+	for i := range clientKey {
+		proof[i] = clientKey[i] ^ signature[i]
+	}
+
+	return finalWithoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+// verifyServer comprueba la firma del servidor "v=..."
+func (s *scramClient) verifyServer(serverFinal string) bool {
+	expected := hmacSHA256(hmacSHA256(s.saltedPassword, "Server Key"), s.authMessage)
+	got, err := base64.StdEncoding.DecodeString(scramAttributes(serverFinal)["v"])
+	return err == nil && hmac.Equal(expected, got)
+}
+
+// scramAttributes separa "a=1,b=2" en un mapa
+func scramAttributes(msg string) map[string]string {
+	attrs := make(map[string]string)
+	for _, part := range strings.Split(msg, ",") {
+		if key, value, ok := strings.Cut(part, "="); ok {
+			attrs[key] = value
+		}
+	}
+	return attrs
+}
+
+func hmacSHA256(key []byte, msg string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(msg))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,159 @@
+package checks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// fakePostgres servidor mínimo del protocolo v3 con autenticación MD5 y consultas simples
+type fakePostgres struct {
+	password string
+	rows     map[string][]string // consulta -> valores de la única fila
+}
+
+func (f *fakePostgres) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+
+	// Mensaje de arranque: longitud + versión + pares clave/valor
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return
+	}
+	startup := make([]byte, binary.BigEndian.Uint32(header)-4)
+	if _, err := io.ReadFull(r, startup); err != nil {
+		return
+	}
+	params := bytes.Split(startup[4:], []byte{0})
+	user := ""
+	for i := 0; i+1 < len(params); i += 2 {
+		if string(params[i]) == "user" {
+			user = string(params[i+1])
+		}
+	}
+
+	if f.password != "" {
+		salt := []byte{1, 2, 3, 4}
+		pgSend(conn, 'R', append(binary.BigEndian.AppendUint32(nil, pgAuthMD5), salt...))
+		msgType, data, err := pgReceive(r)
+		if err != nil || msgType != 'p' {
+			return
+		}
+		if string(bytes.TrimRight(data, "\x00")) != pgMD5Password(user, f.password, salt) {
+			pgSend(conn, 'E', pgErrorFields("FATAL", "28P01", "password authentication failed for user \""+user+"\""))
+			return
+		}
+	}
+	pgSend(conn, 'R', binary.BigEndian.AppendUint32(nil, pgAuthOK))
+	pgSend(conn, 'S', []byte("server_version\x0016.0\x00"))
+	pgSend(conn, 'Z', []byte{'I'})
+
+	for {
+		msgType, data, err := pgReceive(r)
+		if err != nil || msgType == 'X' {
+			return
+		}
+		if msgType != 'Q' {
+			continue
+		}
+		values, ok := f.rows[string(bytes.TrimRight(data, "\x00"))]
+		if !ok {
+			pgSend(conn, 'E', pgErrorFields("ERROR", "42601", "syntax error"))
+			pgSend(conn, 'Z', []byte{'I'})
+			continue
+		}
+		row := binary.BigEndian.AppendUint16(nil, uint16(len(values)))
+		for _, v := range values {
+			row = binary.BigEndian.AppendUint32(row, uint32(len(v)))
+			row = append(row, v...)
+		}
+		pgSend(conn, 'D', row)
+		pgSend(conn, 'C', []byte("SELECT 1\x00"))
+		pgSend(conn, 'Z', []byte{'I'})
+	}
+}
+
+func pgSend(conn net.Conn, msgType byte, payload []byte) {
+	(&pgConn{conn: conn}).send(msgType, payload)
+}
+
+func pgReceive(r *bufio.Reader) (byte, []byte, error) {
+	return (&pgConn{r: r}).receive()
+}
+
+func pgErrorFields(severity, code, message string) []byte {
+	return []byte("S" + severity + "\x00C" + code + "\x00M" + message + "\x00\x00")
+}
+
+func newPostgresTestChecker(t *testing.T, cfg *config.DatabaseCheck) *PostgresChecker {
+	t.Helper()
+	if cfg.Username == "" {
+		cfg.Username = "watchdog"
+	}
+	checker, err := NewPostgresChecker(cfg)
+	if err != nil {
+		t.Fatalf("NewPostgresChecker: %v", err)
+	}
+	return checker
+}
+
+func TestPostgresProbe(t *testing.T) {
+	addr := fakeServer(t, (&fakePostgres{rows: map[string][]string{"SELECT 1": {"1"}}}).serve)
+	expectHealthy(t, runCheck(t, newPostgresTestChecker(t, &config.DatabaseCheck{Address: addr})))
+
+	// Una consulta que falla en el servidor es fallo con su SQLSTATE
+	result := runCheck(t, newPostgresTestChecker(t, &config.DatabaseCheck{Address: addr, Query: "SELEC 1"}))
+	expectFailure(t, result, "SQLSTATE 42601")
+}
+
+func TestPostgresAuth(t *testing.T) {
+	addr := fakeServer(t, (&fakePostgres{password: "secret", rows: map[string][]string{"SELECT 1": {"1"}}}).serve)
+
+	t.Setenv("PG_TEST_PASSWORD", "secret")
+	expectHealthy(t, runCheck(t, newPostgresTestChecker(t, &config.DatabaseCheck{Address: addr, PasswordEnv: "PG_TEST_PASSWORD"})))
+
+	t.Setenv("PG_TEST_PASSWORD", "wrong")
+	result := runCheck(t, newPostgresTestChecker(t, &config.DatabaseCheck{Address: addr, PasswordEnv: "PG_TEST_PASSWORD"}))
+	expectFailure(t, result, "password authentication failed")
+}
+
+func TestPostgresReplication(t *testing.T) {
+	replica := &fakePostgres{rows: map[string][]string{
+		"SELECT 1":  {"1"},
+		pgRoleQuery: {"t"},
+		pgLagQuery:  {"30.5"},
+	}}
+	addr := fakeServer(t, replica.serve)
+
+	result := runCheck(t, newPostgresTestChecker(t, &config.DatabaseCheck{Address: addr, ExpectedRole: "replica", MaxReplicationLagSeconds: 10}))
+	expectDegraded(t, result, "replication lag 30.5s > 10.0s")
+
+	result = runCheck(t, newPostgresTestChecker(t, &config.DatabaseCheck{Address: addr, ExpectedRole: "primary"}))
+	expectFailure(t, result, "is replica, expected primary")
+
+	primary := &fakePostgres{rows: map[string][]string{"SELECT 1": {"1"}, pgRoleQuery: {"f"}}}
+	addr = fakeServer(t, primary.serve)
+	result = runCheck(t, newPostgresTestChecker(t, &config.DatabaseCheck{Address: addr, ExpectedRole: "primary"}))
+	expectHealthy(t, result)
+	if result.Details["role"] != "primary" {
+		t.Fatalf("expected role primary, got %v", result.Details["role"])
+	}
+}
+
+func TestPostgresConnections(t *testing.T) {
+	addr := fakeServer(t, (&fakePostgres{rows: map[string][]string{
+		"SELECT 1":         {"1"},
+		pgConnectionsQuery: {"92", "100"},
+	}}).serve)
+
+	result := runCheck(t, newPostgresTestChecker(t, &config.DatabaseCheck{Address: addr, MaxConnectionsPercent: 90}))
+	expectDegraded(t, result, "connections 92/100")
+
+	result = runCheck(t, newPostgresTestChecker(t, &config.DatabaseCheck{Address: addr, MaxConnectionsPercent: 95}))
+	expectHealthy(t, result)
+}
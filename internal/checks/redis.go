@@ -0,0 +1,264 @@
+package checks
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// RedisChecker verifica Redis con el protocolo RESP: AUTH, comando de prueba e INFO
+type RedisChecker struct {
+	cfg *dbConfig
+}
+
+// NewRedisChecker crea un nuevo redis checker
+func NewRedisChecker(cfg *config.DatabaseCheck) (*RedisChecker, error) {
+	dbCfg, err := newDBConfig("redis", cfg, "6379")
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Database != "" {
+		if _, err := strconv.Atoi(cfg.Database); err != nil {
+			return nil, fmt.Errorf("redis database must be a number: %s", cfg.Database)
+		}
+	}
+	return &RedisChecker{cfg: dbCfg}, nil
+}
+
+func (c *RedisChecker) Name() string {
+	return fmt.Sprintf("redis:%s", c.cfg.address)
+}
+
+func (c *RedisChecker) Check(ctx context.Context) Result {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.timeout)
+	defer cancel()
+
+	expect := c.cfg.Expect
+	if c.cfg.Query == "" && expect == "" {
+		expect = "PONG"
+	}
+
+	st, err := c.probe(ctx)
+	if err != nil {
+		return Result{
+			Success:   false,
+			Message:   fmt.Sprintf("redis %s: %v", c.cfg.address, err),
+			Latency:   time.Since(start),
+			CheckType: "redis",
+		}
+	}
+	return evaluateDatabase("redis", "redis "+c.cfg.address, c.cfg, expect, st, time.Since(start))
+}
+
+// probe se autentica, ejecuta el comando de prueba y lee INFO si hace falta
+func (c *RedisChecker) probe(ctx context.Context) (*dbStatus, error) {
+	if err := c.cfg.resolvePassword(); err != nil {
+		return nil, err
+	}
+
+	conn, err := c.cfg.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if c.cfg.TLS {
+		if conn, err = c.cfg.startTLS(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	rc := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+
+	if c.cfg.password != "" {
+		args := []string{"AUTH", c.cfg.password}
+		if c.cfg.Username != "" {
+			args = []string{"AUTH", c.cfg.Username, c.cfg.password}
+		}
+		if _, err := rc.do(args...); err != nil {
+			return nil, fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+	if c.cfg.Database != "" {
+		if _, err := rc.do("SELECT", c.cfg.Database); err != nil {
+			return nil, fmt.Errorf("cannot select database %s: %w", c.cfg.Database, err)
+		}
+	}
+
+	query := c.cfg.Query
+	if query == "" {
+		query = "PING"
+	}
+	reply, err := rc.do(strings.Fields(query)...)
+	if err != nil {
+		return nil, fmt.Errorf("probe '%s' failed: %w", query, err)
+	}
+	st := &dbStatus{Value: redisString(reply)}
+
+	if c.cfg.wantsRole() {
+		info, err := rc.info("replication")
+		if err != nil {
+			return nil, err
+		}
+		st.Role = "primary"
+		if info["role"] == "slave" {
+			st.Role = "replica"
+			if info["master_link_status"] != "up" {
+				return nil, fmt.Errorf("replication link is %s", info["master_link_status"])
+			}
+			if lag, err := strconv.ParseFloat(info["master_last_io_seconds_ago"], 64); err == nil {
+				st.Lag = &lag
+			}
+		}
+	}
+
+	if c.cfg.MaxConnectionsPercent > 0 {
+		info, err := rc.info("clients")
+		if err != nil {
+			return nil, err
+		}
+		st.Connections, _ = strconv.Atoi(info["connected_clients"])
+		// maxclients aparece en INFO desde Redis 7; antes hay que pedirlo con CONFIG GET
+		if max, err := strconv.Atoi(info["maxclients"]); err == nil {
+			st.MaxConnections = max
+		} else if reply, err := rc.do("CONFIG", "GET", "maxclients"); err == nil {
+			if values, ok := reply.([]interface{}); ok && len(values) == 2 {
+				st.MaxConnections, _ = strconv.Atoi(redisString(values[1]))
+			}
+		}
+	}
+
+	rc.do("QUIT")
+	return st, nil
+}
+
+// redisConn cliente RESP mínimo
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// redisError respuesta de error del servidor ("-ERR ...")
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+// do envía un comando como array de bulk strings y lee la respuesta
+func (rc *redisConn) do(args ...string) (interface{}, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(rc.conn, b.String()); err != nil {
+		return nil, err
+	}
+
+	reply, err := rc.readReply()
+	if err != nil {
+		return nil, err
+	}
+	if e, ok := reply.(redisError); ok {
+		return nil, e
+	}
+	return reply, nil
+}
+
+// readReply lee una respuesta RESP2/RESP3 básica
+func (rc *redisConn) readReply() (interface{}, error) {
+	line, err := rc.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return redisError(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid bulk length: %s", line)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(rc.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid array length: %s", line)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, 0, count)
+		for i := 0; i < count; i++ {
+			item, err := rc.readReply()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unsupported redis reply: %q", line)
+}
+
+// info ejecuta INFO <section> y retorna los pares clave:valor
+func (rc *redisConn) info(section string) (map[string]string, error) {
+	reply, err := rc.do("INFO", section)
+	if err != nil {
+		return nil, fmt.Errorf("INFO %s failed: %w", section, err)
+	}
+
+	info := make(map[string]string)
+	for _, line := range strings.Split(redisString(reply), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if ok && !strings.HasPrefix(key, "#") {
+			info[key] = value
+		}
+	}
+	return info, nil
+}
+
+// redisString convierte una respuesta a texto para compararla con 'expect'
+func redisString(reply interface{}) string {
+	switch v := reply.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, redisString(item))
+		}
+		return strings.Join(parts, "\n")
+	}
+	return fmt.Sprint(reply)
+}
@@ -0,0 +1,150 @@
+package checks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// fakeRedis servidor RESP mínimo con AUTH, PING, INFO y CONFIG GET
+type fakeRedis struct {
+	password    string
+	replication string // cuerpo de INFO replication
+	clients     string // cuerpo de INFO clients
+	maxclients  string // respuesta de CONFIG GET maxclients
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	authed := f.password == ""
+	for {
+		args, err := readRESPCommand(r)
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(args[0])
+		if !authed && cmd != "AUTH" && cmd != "QUIT" {
+			io.WriteString(conn, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+		switch cmd {
+		case "AUTH":
+			if args[len(args)-1] != f.password {
+				io.WriteString(conn, "-WRONGPASS invalid username-password pair or user is disabled.\r\n")
+				continue
+			}
+			authed = true
+			io.WriteString(conn, "+OK\r\n")
+		case "PING":
+			io.WriteString(conn, "+PONG\r\n")
+		case "INFO":
+			body := f.replication
+			if strings.EqualFold(args[1], "clients") {
+				body = f.clients
+			}
+			fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(body), body)
+		case "CONFIG":
+			fmt.Fprintf(conn, "*2\r\n$10\r\nmaxclients\r\n$%d\r\n%s\r\n", len(f.maxclients), f.maxclients)
+		case "QUIT":
+			io.WriteString(conn, "+OK\r\n")
+			return
+		default:
+			fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+		}
+	}
+}
+
+// readRESPCommand lee un comando enviado como array de bulk strings
+func readRESPCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || count < 1 {
+		return nil, fmt.Errorf("invalid command header %q", line)
+	}
+	args := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+func newRedisTestChecker(t *testing.T, cfg *config.DatabaseCheck) *RedisChecker {
+	t.Helper()
+	checker, err := NewRedisChecker(cfg)
+	if err != nil {
+		t.Fatalf("NewRedisChecker: %v", err)
+	}
+	return checker
+}
+
+func TestRedisProbe(t *testing.T) {
+	addr := fakeServer(t, (&fakeRedis{}).serve)
+	result := runCheck(t, newRedisTestChecker(t, &config.DatabaseCheck{Address: addr}))
+	expectHealthy(t, result)
+	if result.Details["value"] != "PONG" {
+		t.Fatalf("expected PONG, got %v", result.Details["value"])
+	}
+}
+
+func TestRedisAuth(t *testing.T) {
+	addr := fakeServer(t, (&fakeRedis{password: "secret"}).serve)
+
+	t.Setenv("REDIS_TEST_PASSWORD", "secret")
+	expectHealthy(t, runCheck(t, newRedisTestChecker(t, &config.DatabaseCheck{Address: addr, PasswordEnv: "REDIS_TEST_PASSWORD"})))
+
+	t.Setenv("REDIS_TEST_PASSWORD", "wrong")
+	result := runCheck(t, newRedisTestChecker(t, &config.DatabaseCheck{Address: addr, PasswordEnv: "REDIS_TEST_PASSWORD"}))
+	expectFailure(t, result, "authentication failed: WRONGPASS")
+}
+
+func TestRedisReplication(t *testing.T) {
+	replica := &fakeRedis{replication: "# Replication\r\nrole:slave\r\nmaster_link_status:up\r\nmaster_last_io_seconds_ago:42\r\n"}
+	addr := fakeServer(t, replica.serve)
+
+	result := runCheck(t, newRedisTestChecker(t, &config.DatabaseCheck{Address: addr, ExpectedRole: "replica", MaxReplicationLagSeconds: 10}))
+	expectDegraded(t, result, "replication lag 42.0s > 10.0s")
+
+	result = runCheck(t, newRedisTestChecker(t, &config.DatabaseCheck{Address: addr, ExpectedRole: "primary"}))
+	expectFailure(t, result, "is replica, expected primary")
+
+	down := &fakeRedis{replication: "# Replication\r\nrole:slave\r\nmaster_link_status:down\r\n"}
+	addr = fakeServer(t, down.serve)
+	result = runCheck(t, newRedisTestChecker(t, &config.DatabaseCheck{Address: addr, ExpectedRole: "replica"}))
+	expectFailure(t, result, "replication link is down")
+}
+
+func TestRedisConnections(t *testing.T) {
+	// Redis 7 informa maxclients en INFO clients
+	addr := fakeServer(t, (&fakeRedis{clients: "# Clients\r\nconnected_clients:95\r\nmaxclients:100\r\n"}).serve)
+	result := runCheck(t, newRedisTestChecker(t, &config.DatabaseCheck{Address: addr, MaxConnectionsPercent: 90}))
+	expectDegraded(t, result, "connections 95/100")
+
+	// Versiones anteriores requieren CONFIG GET maxclients
+	addr = fakeServer(t, (&fakeRedis{clients: "connected_clients:10\r\n", maxclients: "100"}).serve)
+	result = runCheck(t, newRedisTestChecker(t, &config.DatabaseCheck{Address: addr, MaxConnectionsPercent: 90}))
+	expectHealthy(t, result)
+	if result.Details["max_connections"] != 100 {
+		t.Fatalf("expected max_connections 100, got %v", result.Details["max_connections"])
+	}
+}
@@ -0,0 +1,104 @@
+package checks
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// Factory construye un checker a partir de su configuración
+type Factory func(check config.Check) (Checker, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+func init() {
+	Register("process_name", func(check config.Check) (Checker, error) {
+		return &ProcessNameChecker{
+			ProcessName:     check.ProcessName,
+			IgnoreExitCodes: check.IgnoreExitCodes,
+		}, nil
+	})
+	Register("pid_file", func(check config.Check) (Checker, error) {
+		return &PidFileChecker{PidFile: check.PidFile}, nil
+	})
+	Register("tcp_port", func(check config.Check) (Checker, error) {
+		return &TcpPortChecker{Address: check.TcpPort}, nil
+	})
+	Register("command", func(check config.Check) (Checker, error) {
+		return &CommandChecker{Command: check.Command, Options: check.ExecOptions}, nil
+	})
+	Register("http", func(check config.Check) (Checker, error) {
+		return NewHTTPChecker(check.HTTP)
+	})
+	Register("script", func(check config.Check) (Checker, error) {
+		checker, err := NewScriptChecker(check.Script)
+		if err != nil {
+			return nil, err
+		}
+		if checker.Options.MaxOutputBytes == 0 {
+			checker.Options.MaxOutputBytes = check.MaxOutputBytes
+		}
+		return checker, nil
+	})
+	Register("logic", func(check config.Check) (Checker, error) {
+		return NewLogicChecker(check.Logic, check.Checks, check.ShortCircuit == nil || *check.ShortCircuit)
+	})
+	Register("heartbeat", func(check config.Check) (Checker, error) {
+		return NewHeartbeatChecker(check.Heartbeat)
+	})
+	Register("log_pattern", func(check config.Check) (Checker, error) {
+		return NewLogPatternChecker(check.LogPattern)
+	})
+	Register("kernel_events", func(check config.Check) (Checker, error) {
+		return NewKernelEventsChecker(check.KernelEvents)
+	})
+	Register("host", func(check config.Check) (Checker, error) {
+		return NewHostChecker(check.Host)
+	})
+	Register("listening", func(check config.Check) (Checker, error) {
+		return NewListeningChecker(check.Listening)
+	})
+	Register("nagios_plugin", func(check config.Check) (Checker, error) {
+		return NewNagiosPluginChecker(check.NagiosPlugin)
+	})
+	Register("plugin", func(check config.Check) (Checker, error) {
+		return NewPluginChecker(check.Plugin)
+	})
+	Register("grpc", func(check config.Check) (Checker, error) {
+		return NewGRPCChecker(check.GRPC)
+	})
+	Register("postgres", func(check config.Check) (Checker, error) {
+		return NewPostgresChecker(check.Postgres)
+	})
+	Register("mysql", func(check config.Check) (Checker, error) {
+		return NewMySQLChecker(check.MySQL)
+	})
+	Register("redis", func(check config.Check) (Checker, error) {
+		return NewRedisChecker(check.Redis)
+	})
+	Register("container", func(check config.Check) (Checker, error) {
+		return NewContainerChecker(check.Container)
+	})
+}
+
+// Register da de alta la factory de un tipo de check. Falla si el nombre ya existe
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if _, exists := factories[name]; exists {
+		panic(fmt.Sprintf("checks: type %q already registered", name))
+	}
+	factories[name] = factory
+}
+
+func lookupFactory(name string) (Factory, bool) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	factory, ok := factories[name]
+	return factory, ok
+}
@@ -0,0 +1,114 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DetailAttempts clave de Details con el resultado de cada intento de un check con retries
+const DetailAttempts = "attempts"
+
+// RetryChecker repite un check dentro de la misma pasada y exige minSuccess intentos correctos
+// de 1+retries. Con minSuccess 1 se detiene en el primer éxito
+type RetryChecker struct {
+	Checker    Checker
+	Attempts   int
+	Delay      time.Duration
+	MinSuccess int
+	CheckType  string // tipo del check envuelto, para el resultado si no se completa ningún intento
+}
+
+// NewRetryChecker crea un nuevo retry checker alrededor de checker
+func NewRetryChecker(checker Checker, retries int, delay time.Duration, minSuccess int) *RetryChecker {
+	if minSuccess <= 0 {
+		minSuccess = 1
+	}
+	return &RetryChecker{
+		Checker:    checker,
+		Attempts:   retries + 1,
+		Delay:      delay,
+		MinSuccess: minSuccess,
+	}
+}
+
+func (c *RetryChecker) Name() string {
+	return c.Checker.Name()
+}
+
+func (c *RetryChecker) Check(ctx context.Context) Result {
+	start := time.Now()
+
+	attempts := make([]map[string]interface{}, 0, c.Attempts)
+	var lastOK, lastFail *Result
+	successes := 0
+
+	for i := 0; i < c.Attempts; i++ {
+		if i > 0 && c.Delay > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(c.Delay):
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		result := c.Checker.Check(ctx)
+		result.Attempt = i + 1
+		attempts = append(attempts, map[string]interface{}{
+			"attempt":    i + 1,
+			"success":    result.Success,
+			"degraded":   result.Degraded,
+			"message":    result.Message,
+			"latency_ms": result.Latency.Milliseconds(),
+		})
+		if result.Success {
+			successes++
+			lastOK = &result
+		} else {
+			lastFail = &result
+		}
+
+		// Parar en cuanto el resultado está decidido
+		remaining := c.Attempts - i - 1
+		if successes >= c.MinSuccess || successes+remaining < c.MinSuccess {
+			break
+		}
+	}
+
+	var result Result
+	expired := false
+	switch {
+	case successes >= c.MinSuccess:
+		result = *lastOK
+	case lastFail != nil:
+		result = *lastFail
+	default:
+		// El contexto expiró sin fallos pero antes de reunir min_success intentos correctos
+		// (o antes del primero)
+		expired = true
+		result = Result{
+			Success:   false,
+			Message:   fmt.Sprintf("%s: %v", c.Checker.Name(), ctx.Err()),
+			CheckType: c.CheckType,
+			Attempt:   len(attempts),
+		}
+		if lastOK != nil {
+			result.CheckType = lastOK.CheckType
+		}
+	}
+
+	details := make(map[string]interface{}, len(result.Details)+1)
+	for k, v := range result.Details {
+		details[k] = v
+	}
+	details[DetailAttempts] = attempts
+	result.Details = details
+	result.Latency = time.Since(start)
+
+	if len(attempts) > 1 || expired {
+		result.Message = fmt.Sprintf("%s (%d/%d attempts succeeded, %d required)", result.Message, successes, len(attempts), c.MinSuccess)
+	}
+	return result
+}
@@ -0,0 +1,55 @@
+package checks
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubChecker devuelve siempre el mismo resultado
+type stubChecker struct {
+	result Result
+}
+
+func (c *stubChecker) Name() string { return "stub" }
+
+func (c *stubChecker) Check(ctx context.Context) Result { return c.result }
+
+func TestRetryDeadlineBeforeMinSuccess(t *testing.T) {
+	checker := NewRetryChecker(&stubChecker{Result{Success: true, Message: "ok", CheckType: "tcp_port"}}, 2, time.Second, 3)
+	checker.CheckType = "tcp_port"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	result := checker.Check(ctx)
+
+	if result.Success {
+		t.Fatalf("expected failure when min_success is not reached, got %s", result.Message)
+	}
+	if result.CheckType != "tcp_port" {
+		t.Fatalf("expected check type tcp_port, got %q", result.CheckType)
+	}
+	if !strings.Contains(result.Message, "1/1 attempts succeeded, 3 required") {
+		t.Fatalf("expected attempt counts in message, got %q", result.Message)
+	}
+	if attempts, ok := result.Details[DetailAttempts].([]map[string]interface{}); !ok || len(attempts) != 1 {
+		t.Fatalf("expected 1 attempt in details, got %v", result.Details[DetailAttempts])
+	}
+}
+
+func TestRetryDeadlineBeforeFirstAttempt(t *testing.T) {
+	checker := NewRetryChecker(&stubChecker{Result{Success: true}}, 1, 0, 1)
+	checker.CheckType = "http"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := checker.Check(ctx)
+
+	if result.Success || result.CheckType != "http" {
+		t.Fatalf("expected failed http result, got success=%v type=%q", result.Success, result.CheckType)
+	}
+	if !strings.Contains(result.Message, "0/0 attempts succeeded, 1 required") {
+		t.Fatalf("expected attempt counts in message, got %q", result.Message)
+	}
+}
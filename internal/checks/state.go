@@ -0,0 +1,69 @@
+package checks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/tgextreme/neon-watchdog/internal/atomicfile"
+)
+
+var (
+	stateDirMu sync.RWMutex
+	stateDir   string
+)
+
+// SetStateDir configura dónde guardan su posición los checks que leen logs de forma
+// incremental (log_pattern, kernel_events) cuando no indican offset_file. Con "" la posición
+// solo se mantiene en memoria y un check puntual del timer no ve nada entre ejecuciones
+func SetStateDir(dir string) {
+	stateDirMu.Lock()
+	defer stateDirMu.Unlock()
+	stateDir = dir
+}
+
+// defaultStateFile archivo de estado de un check en el directorio de estado: el nombre
+// incluye un hash de la clave (fuente, patrón, ventana...) para no mezclar checks
+func defaultStateFile(prefix, key string) string {
+	stateDirMu.RLock()
+	defer stateDirMu.RUnlock()
+	if stateDir == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(stateDir, fmt.Sprintf("%s-%s.json", prefix, hex.EncodeToString(sum[:8])))
+}
+
+// loadCheckState lee el estado guardado en path. Retorna false si no hay estado
+func loadCheckState(path string, v interface{}) (bool, error) {
+	if path == "" {
+		return false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("invalid state file %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// saveCheckState guarda el estado en path (sin efecto si path está vacío)
+func saveCheckState(path string, v interface{}) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(path, data, 0644)
+}
@@ -3,10 +3,17 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"os/user"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/tgextreme/neon-watchdog/internal/rules"
 	"gopkg.in/yaml.v3"
 )
 
@@ -22,8 +29,46 @@ type Config struct {
 	Metrics         *MetricsConfig   `yaml:"metrics,omitempty" json:"metrics,omitempty"`
 	Dashboard       *DashboardConfig `yaml:"dashboard,omitempty" json:"dashboard,omitempty"`
 	History         *HistoryConfig   `yaml:"history,omitempty" json:"history,omitempty"`
+	Heartbeat       *HeartbeatConfig `yaml:"heartbeat,omitempty" json:"heartbeat,omitempty"`
+	PluginsDir      string           `yaml:"plugins_dir,omitempty" json:"plugins_dir,omitempty"` // default: /usr/lib/neon-watchdog/plugins
+
+	// Mode modo de las acciones de recuperación: enforce (default), dry_run o approval.
+	// Cada target puede sobrescribirlo
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+
+	// Maintenance ventanas de mantenimiento en las que se suprimen acciones y/o notificaciones
+	Maintenance *MaintenanceConfig `yaml:"maintenance,omitempty" json:"maintenance,omitempty"`
+
+	// Instance bloqueo de instancia única entre el check del timer y el daemon
+	Instance *InstanceConfig `yaml:"instance,omitempty" json:"instance,omitempty"`
 }
 
+// InstanceConfig bloqueo de instancia única y socket de control del daemon
+type InstanceConfig struct {
+	LockFile      string `yaml:"lock_file,omitempty" json:"lock_file,omitempty"`           // default: <dir de state_file>/neon-watchdog.lock
+	ControlSocket string `yaml:"control_socket,omitempty" json:"control_socket,omitempty"` // default: <dir de state_file>/neon-watchdog.sock
+	OnLocked      string `yaml:"on_locked,omitempty" json:"on_locked,omitempty"`           // check con un daemon activo: delegate (default) o skip
+}
+
+// Qué hace un check puntual cuando el daemon tiene el bloqueo
+const (
+	OnLockedDelegate = "delegate" // pedir al daemon una pasada por el socket de control
+	OnLockedSkip     = "skip"     // terminar sin hacer nada
+)
+
+// DefaultStateDir directorio de estado si no hay state_file
+const DefaultStateDir = "/var/lib/neon-watchdog"
+
+// DefaultPluginsDir directorio por defecto donde se buscan los plugins
+const DefaultPluginsDir = "/usr/lib/neon-watchdog/plugins"
+
+// Modos de ejecución de las acciones de recuperación
+const (
+	ModeEnforce  = "enforce"  // ejecutar la acción
+	ModeDryRun   = "dry_run"  // registrar y notificar la acción sin ejecutarla
+	ModeApproval = "approval" // encolar la acción hasta que un operador la apruebe
+)
+
 // Policy define la política de reintentos y rate limiting
 type Policy struct {
 	FailThreshold          int    `yaml:"fail_threshold" json:"fail_threshold"`
@@ -31,6 +76,11 @@ type Policy struct {
 	MaxRestartsPerHour     int    `yaml:"max_restarts_per_hour" json:"max_restarts_per_hour"`
 	BackoffStrategy        string `yaml:"backoff_strategy,omitempty" json:"backoff_strategy,omitempty"` // linear, exponential
 	MaxBackoffSeconds      int    `yaml:"max_backoff_seconds,omitempty" json:"max_backoff_seconds,omitempty"`
+	// SkipRestartUnderPressure omite reinicios mientras alguna métrica del host supere su límite
+	// (ej: memory_full_avg10: 10). Mismos nombres que el check 'host'
+	SkipRestartUnderPressure map[string]float64 `yaml:"skip_restart_under_pressure,omitempty" json:"skip_restart_under_pressure,omitempty"`
+	// ApprovalTimeoutSeconds plazo para aprobar una acción en mode approval (default: 900)
+	ApprovalTimeoutSeconds int `yaml:"approval_timeout_seconds,omitempty" json:"approval_timeout_seconds,omitempty"`
 }
 
 // Notification define configuración de notificaciones
@@ -83,8 +133,15 @@ type DashboardConfig struct {
 
 // HistoryConfig configuración del historial persistente
 type HistoryConfig struct {
-	MaxEntries     int `yaml:"max_entries" json:"max_entries"`
-	RetentionHours int `yaml:"retention_hours" json:"retention_hours"`
+	MaxEntries              int `yaml:"max_entries" json:"max_entries"`
+	RetentionHours          int `yaml:"retention_hours" json:"retention_hours"`
+	PerfdataIntervalSeconds int `yaml:"perfdata_interval_seconds,omitempty" json:"perfdata_interval_seconds,omitempty"` // muestreo de perfdata, default: 300
+}
+
+// HeartbeatConfig configuración de la recepción de pings de heartbeat
+type HeartbeatConfig struct {
+	SocketPath string `yaml:"socket_path,omitempty" json:"socket_path,omitempty"` // socket Unix local para pings
+	StateFile  string `yaml:"state_file,omitempty" json:"state_file,omitempty"`   // persistencia de los últimos pings
 }
 
 // Target representa un servicio/proceso a monitorizar
@@ -95,20 +152,75 @@ type Target struct {
 	Checks    []Check  `yaml:"checks" json:"checks"`
 	Action    Action   `yaml:"action" json:"action"`
 	Policy    *Policy  `yaml:"policy,omitempty" json:"policy,omitempty"`
+
+	// Labels etiquetas libres para seleccionar targets en ventanas de mantenimiento y silencios
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+
+	// HealthyWhen expresión sobre los checks con nombre que decide si el target está sano.
+	// Si está vacía el target está sano cuando pasan todos los checks
+	HealthyWhen string `yaml:"healthy_when,omitempty" json:"healthy_when,omitempty"`
+
+	// Mode sobrescribe el modo global de las acciones de recuperación (enforce, dry_run, approval)
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+
+	// Diagnostics recoge un bundle de diagnóstico antes de cada acción de recuperación
+	Diagnostics *DiagnosticsConfig `yaml:"diagnostics,omitempty" json:"diagnostics,omitempty"`
+}
+
+// DiagnosticsConfig contenido y retención de los bundles de diagnóstico de un target.
+// Sin pid_file, process_name ni systemd_unit el PID se deduce de los checks
+// pid_file/process_name del target o del unit de su acción systemd
+type DiagnosticsConfig struct {
+	Dir            string   `yaml:"dir,omitempty" json:"dir,omitempty"` // default: /var/lib/neon-watchdog/diagnostics
+	PidFile        string   `yaml:"pid_file,omitempty" json:"pid_file,omitempty"`
+	ProcessName    string   `yaml:"process_name,omitempty" json:"process_name,omitempty"`
+	SystemdUnit    string   `yaml:"systemd_unit,omitempty" json:"systemd_unit,omitempty"`
+	LogFiles       []string `yaml:"log_files,omitempty" json:"log_files,omitempty"`
+	LogLines       int      `yaml:"log_lines,omitempty" json:"log_lines,omitempty"`             // default: 200
+	Commands       []Hook   `yaml:"commands,omitempty" json:"commands,omitempty"`               // plantillas: {{.Target}}, {{.PID}}
+	TimeoutSeconds int      `yaml:"timeout_seconds,omitempty" json:"timeout_seconds,omitempty"` // por comando, default: 10
+	MaxBundles     int      `yaml:"max_bundles,omitempty" json:"max_bundles,omitempty"`         // default: 10
+	MaxSizeMB      int      `yaml:"max_size_mb,omitempty" json:"max_size_mb,omitempty"`         // total por target, default: 100
+
+	ExecOptions `yaml:",inline"`
 }
 
 // Check representa un tipo de verificación
 type Check struct {
-	Type            string       `yaml:"type" json:"type"` // process_name, pid_file, tcp_port, command, http, script, logic
-	ProcessName     string       `yaml:"process_name,omitempty" json:"process_name,omitempty"`
-	IgnoreExitCodes []int        `yaml:"ignore_exit_codes,omitempty" json:"ignore_exit_codes,omitempty"`
-	PidFile         string       `yaml:"pid_file,omitempty" json:"pid_file,omitempty"`
-	TcpPort         string       `yaml:"tcp_port,omitempty" json:"tcp_port,omitempty"`
-	Command         []string     `yaml:"command,omitempty" json:"command,omitempty"`
-	HTTP            *HTTPCheck   `yaml:"http,omitempty" json:"http,omitempty"`
-	Script          *ScriptCheck `yaml:"script,omitempty" json:"script,omitempty"`
-	Logic           string       `yaml:"logic,omitempty" json:"logic,omitempty"`   // AND, OR
-	Checks          []Check      `yaml:"checks,omitempty" json:"checks,omitempty"` // For logic groups
+	Name            string                 `yaml:"name,omitempty" json:"name,omitempty"` // referencia del check en healthy_when
+	Type            string                 `yaml:"type" json:"type"`                     // process_name, pid_file, tcp_port, command, http, script, logic, heartbeat
+	ProcessName     string                 `yaml:"process_name,omitempty" json:"process_name,omitempty"`
+	IgnoreExitCodes []int                  `yaml:"ignore_exit_codes,omitempty" json:"ignore_exit_codes,omitempty"`
+	PidFile         string                 `yaml:"pid_file,omitempty" json:"pid_file,omitempty"`
+	TcpPort         string                 `yaml:"tcp_port,omitempty" json:"tcp_port,omitempty"`
+	Command         []string               `yaml:"command,omitempty" json:"command,omitempty"`
+	HTTP            *HTTPCheck             `yaml:"http,omitempty" json:"http,omitempty"`
+	Script          *ScriptCheck           `yaml:"script,omitempty" json:"script,omitempty"`
+	Heartbeat       *HeartbeatCheck        `yaml:"heartbeat,omitempty" json:"heartbeat,omitempty"`
+	LogPattern      *LogPatternCheck       `yaml:"log_pattern,omitempty" json:"log_pattern,omitempty"`
+	KernelEvents    *KernelEventsCheck     `yaml:"kernel_events,omitempty" json:"kernel_events,omitempty"`
+	Host            *HostCheck             `yaml:"host,omitempty" json:"host,omitempty"`
+	Listening       *ListeningCheck        `yaml:"listening,omitempty" json:"listening,omitempty"`
+	NagiosPlugin    *NagiosPluginCheck     `yaml:"nagios_plugin,omitempty" json:"nagios_plugin,omitempty"`
+	Plugin          *PluginConfig          `yaml:"plugin,omitempty" json:"plugin,omitempty"`
+	GRPC            *GRPCCheck             `yaml:"grpc,omitempty" json:"grpc,omitempty"`
+	Postgres        *DatabaseCheck         `yaml:"postgres,omitempty" json:"postgres,omitempty"`
+	MySQL           *DatabaseCheck         `yaml:"mysql,omitempty" json:"mysql,omitempty"`
+	Redis           *DatabaseCheck         `yaml:"redis,omitempty" json:"redis,omitempty"`
+	Container       *ContainerCheck        `yaml:"container,omitempty" json:"container,omitempty"`
+	Options         map[string]interface{} `yaml:"options,omitempty" json:"options,omitempty"`             // tipos registrados externamente
+	Logic           string                 `yaml:"logic,omitempty" json:"logic,omitempty"`                 // AND, OR, QUORUM, AT_LEAST n
+	Checks          []Check                `yaml:"checks,omitempty" json:"checks,omitempty"`               // For logic groups
+	ShortCircuit    *bool                  `yaml:"short_circuit,omitempty" json:"short_circuit,omitempty"` // cancelar el resto al decidirse (default: true)
+
+	// Reintentos dentro de una misma pasada, aplicables a cualquier tipo
+	Retries      int `yaml:"retries,omitempty" json:"retries,omitempty"`               // intentos adicionales
+	RetryDelayMs int `yaml:"retry_delay_ms,omitempty" json:"retry_delay_ms,omitempty"` // espera entre intentos
+	MinSuccess   int `yaml:"min_success,omitempty" json:"min_success,omitempty"`       // intentos OK necesarios (default: 1)
+
+	// Opciones de ejecución de checks command (max_output_bytes también aplica a script); el
+	// resto de tipos las rechaza
+	ExecOptions `yaml:",inline"`
 }
 
 // HTTPCheck configuración para health checks HTTP
@@ -119,6 +231,27 @@ type HTTPCheck struct {
 	Headers        map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
 	Body           string            `yaml:"body,omitempty" json:"body,omitempty"`
 	TimeoutSeconds int               `yaml:"timeout_seconds,omitempty" json:"timeout_seconds,omitempty"`
+	Auth           *HTTPAuth         `yaml:"auth,omitempty" json:"auth,omitempty"`
+	TLS            *HTTPTLS          `yaml:"tls,omitempty" json:"tls,omitempty"`
+}
+
+// HTTPAuth autenticación de las peticiones del check y la acción http: basic (username y
+// password) o bearer token. Los secretos pueden leerse de un archivo
+type HTTPAuth struct {
+	Username        string `yaml:"username,omitempty" json:"username,omitempty"`
+	Password        string `yaml:"password,omitempty" json:"password,omitempty"`
+	PasswordFile    string `yaml:"password_file,omitempty" json:"password_file,omitempty"`
+	BearerToken     string `yaml:"bearer_token,omitempty" json:"bearer_token,omitempty"`
+	BearerTokenFile string `yaml:"bearer_token_file,omitempty" json:"bearer_token_file,omitempty"`
+}
+
+// HTTPTLS configuración TLS del cliente del check y la acción http
+type HTTPTLS struct {
+	CACert             string `yaml:"ca_cert,omitempty" json:"ca_cert,omitempty"`         // CA para verificar al servidor
+	ClientCert         string `yaml:"client_cert,omitempty" json:"client_cert,omitempty"` // mTLS
+	ClientKey          string `yaml:"client_key,omitempty" json:"client_key,omitempty"`   // mTLS
+	ServerName         string `yaml:"server_name,omitempty" json:"server_name,omitempty"` // SNI / verificación del certificado
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty" json:"insecure_skip_verify,omitempty"`
 }
 
 // ScriptCheck configuración para scripts personalizados
@@ -127,27 +260,193 @@ type ScriptCheck struct {
 	Args             []string `yaml:"args,omitempty" json:"args,omitempty"`
 	SuccessExitCodes []int    `yaml:"success_exit_codes,omitempty" json:"success_exit_codes,omitempty"`
 	WarningExitCodes []int    `yaml:"warning_exit_codes,omitempty" json:"warning_exit_codes,omitempty"`
+
+	ExecOptions `yaml:",inline"`
+}
+
+// ExecOptions opciones de ejecución de procesos compartidas por checks command, script y
+// nagios_plugin, acciones exec y hooks
+type ExecOptions struct {
+	Env            map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+	EnvFile        string            `yaml:"env_file,omitempty" json:"env_file,omitempty"`   // líneas KEY=VALUE
+	ClearEnv       bool              `yaml:"clear_env,omitempty" json:"clear_env,omitempty"` // no heredar el entorno del watchdog
+	WorkingDir     string            `yaml:"working_dir,omitempty" json:"working_dir,omitempty"`
+	User           string            `yaml:"user,omitempty" json:"user,omitempty"` // nombre o UID
+	Group          string            `yaml:"group,omitempty" json:"group,omitempty"`
+	Umask          string            `yaml:"umask,omitempty" json:"umask,omitempty"` // octal, ej: "0027"
+	Nice           int               `yaml:"nice,omitempty" json:"nice,omitempty"`   // -20..19
+	Limits         map[string]uint64 `yaml:"limits,omitempty" json:"limits,omitempty"`
+	MaxOutputBytes int               `yaml:"max_output_bytes,omitempty" json:"max_output_bytes,omitempty"` // por stream, default: 65536
+}
+
+// ResourceLimits nombres de rlimit admitidos en ExecOptions.Limits
+var ResourceLimits = []string{"as", "core", "cpu", "data", "fsize", "memlock", "nofile", "nproc", "stack"}
+
+// HeartbeatCheck configuración para checks pasivos alimentados por pings
+type HeartbeatCheck struct {
+	Token              string `yaml:"token" json:"token"`
+	PeriodSeconds      int    `yaml:"period_seconds" json:"period_seconds"`
+	GraceSeconds       int    `yaml:"grace_seconds,omitempty" json:"grace_seconds,omitempty"`
+	MaxDurationSeconds int    `yaml:"max_duration_seconds,omitempty" json:"max_duration_seconds,omitempty"` // 0 = sin límite
+}
+
+// LogPatternCheck configuración para buscar patrones en logs (fichero o journald)
+type LogPatternCheck struct {
+	Path          string `yaml:"path,omitempty" json:"path,omitempty"`                     // fichero a seguir (tail -F)
+	JournalUnit   string `yaml:"journal_unit,omitempty" json:"journal_unit,omitempty"`     // unidad systemd a leer del journal
+	Pattern       string `yaml:"pattern" json:"pattern"`                                   // expresión regular
+	WindowSeconds int    `yaml:"window_seconds,omitempty" json:"window_seconds,omitempty"` // default: 300
+	Threshold     int    `yaml:"threshold,omitempty" json:"threshold,omitempty"`           // falla si matches > threshold
+	MaxSamples    int    `yaml:"max_samples,omitempty" json:"max_samples,omitempty"`       // default: 5
+	OffsetFile    string `yaml:"offset_file,omitempty" json:"offset_file,omitempty"`       // posición y coincidencias, default: en el directorio del state_file
+}
+
+// KernelEventsCheck configuración para detectar OOM-kills, segfaults y hung tasks del kernel
+type KernelEventsCheck struct {
+	ProcessNames  []string `yaml:"process_names,omitempty" json:"process_names,omitempty"`   // atribuir por nombre de proceso
+	Cgroup        string   `yaml:"cgroup,omitempty" json:"cgroup,omitempty"`                 // atribuir por cgroup (ej: myapp.service)
+	Events        []string `yaml:"events,omitempty" json:"events,omitempty"`                 // oom, segfault, hung_task (default: todos)
+	WindowSeconds int      `yaml:"window_seconds,omitempty" json:"window_seconds,omitempty"` // tiempo en degraded tras un evento, default: 3600
+	OffsetFile    string   `yaml:"offset_file,omitempty" json:"offset_file,omitempty"`       // posición, boot_id y eventos, default: en el directorio del state_file
+}
+
+// HostCheck configuración para checks de presión (PSI), carga y memoria del host
+type HostCheck struct {
+	// Thresholds por métrica: cpu_some_avg10, memory_full_avg60, io_some_avg300, load1_per_cpu,
+	// load5_per_cpu, load15_per_cpu, mem_available_percent, mem_available_mb
+	Thresholds map[string]Threshold `yaml:"thresholds" json:"thresholds"`
+}
+
+// Threshold límites de warning y critical de una métrica (0 = no evaluar)
+type Threshold struct {
+	Warning  float64 `yaml:"warning,omitempty" json:"warning,omitempty"`
+	Critical float64 `yaml:"critical,omitempty" json:"critical,omitempty"`
+}
+
+// ValidHostMetric indica si el nombre corresponde a una métrica del host soportada
+func ValidHostMetric(name string) bool {
+	switch name {
+	case "load1_per_cpu", "load5_per_cpu", "load15_per_cpu", "mem_available_percent", "mem_available_mb":
+		return true
+	}
+
+	parts := strings.Split(name, "_")
+	if len(parts) != 3 {
+		return false
+	}
+	resources := map[string]bool{"cpu": true, "memory": true, "io": true}
+	kinds := map[string]bool{"some": true, "full": true}
+	avgs := map[string]bool{"avg10": true, "avg60": true, "avg300": true}
+	return resources[parts[0]] && kinds[parts[1]] && avgs[parts[2]]
+}
+
+// ListeningCheck configuración para verificar qué proceso tiene un socket en escucha
+type ListeningCheck struct {
+	Protocol    string `yaml:"protocol,omitempty" json:"protocol,omitempty"` // tcp (default), udp, unix
+	Port        int    `yaml:"port,omitempty" json:"port,omitempty"`
+	Address     string `yaml:"address,omitempty" json:"address,omitempty"`         // IP local opcional
+	Path        string `yaml:"path,omitempty" json:"path,omitempty"`               // ruta del socket unix
+	ProcessName string `yaml:"process_name" json:"process_name"`                   // proceso esperado
+	MaxBacklog  int    `yaml:"max_backlog,omitempty" json:"max_backlog,omitempty"` // Recv-Q máximo en LISTEN (0 = no verificar)
+}
+
+// NagiosPluginCheck configuración para plugins compatibles con Nagios/Monitoring-Plugins
+type NagiosPluginCheck struct {
+	Path           string   `yaml:"path" json:"path"`
+	Args           []string `yaml:"args,omitempty" json:"args,omitempty"`
+	TreatUnknownAs string   `yaml:"treat_unknown_as,omitempty" json:"treat_unknown_as,omitempty"` // critical (default), warning
+
+	ExecOptions `yaml:",inline"`
+}
+
+// GRPCCheck configuración para el protocolo estándar grpc.health.v1.Health
+type GRPCCheck struct {
+	Address            string            `yaml:"address" json:"address"`                             // host:port
+	Service            string            `yaml:"service,omitempty" json:"service,omitempty"`         // vacío = estado global del servidor
+	TLS                bool              `yaml:"tls,omitempty" json:"tls,omitempty"`                 // default: plaintext (h2c)
+	CACert             string            `yaml:"ca_cert,omitempty" json:"ca_cert,omitempty"`         // CA para verificar al servidor
+	ClientCert         string            `yaml:"client_cert,omitempty" json:"client_cert,omitempty"` // mTLS
+	ClientKey          string            `yaml:"client_key,omitempty" json:"client_key,omitempty"`   // mTLS
+	ServerName         string            `yaml:"server_name,omitempty" json:"server_name,omitempty"` // SNI / verificación del certificado
+	InsecureSkipVerify bool              `yaml:"insecure_skip_verify,omitempty" json:"insecure_skip_verify,omitempty"`
+	Metadata           map[string]string `yaml:"metadata,omitempty" json:"metadata,omitempty"`                 // cabeceras enviadas con la llamada
+	TimeoutSeconds     int               `yaml:"timeout_seconds,omitempty" json:"timeout_seconds,omitempty"`   // deadline de la llamada, default: 5
+	TreatUnknownAs     string            `yaml:"treat_unknown_as,omitempty" json:"treat_unknown_as,omitempty"` // critical (default), warning
+}
+
+// DatabaseCheck configuración común de los checks postgres, mysql y redis
+type DatabaseCheck struct {
+	Address                  string  `yaml:"address" json:"address"`                                 // host[:port] o ruta de socket unix
+	Username                 string  `yaml:"username,omitempty" json:"username,omitempty"`           // redis: usuario ACL opcional
+	PasswordFile             string  `yaml:"password_file,omitempty" json:"password_file,omitempty"` // archivo con la contraseña
+	PasswordEnv              string  `yaml:"password_env,omitempty" json:"password_env,omitempty"`   // variable de entorno con la contraseña
+	Database                 string  `yaml:"database,omitempty" json:"database,omitempty"`           // redis: número de DB
+	TLS                      bool    `yaml:"tls,omitempty" json:"tls,omitempty"`
+	CACert                   string  `yaml:"ca_cert,omitempty" json:"ca_cert,omitempty"`
+	InsecureSkipVerify       bool    `yaml:"insecure_skip_verify,omitempty" json:"insecure_skip_verify,omitempty"`
+	Query                    string  `yaml:"query,omitempty" json:"query,omitempty"`                 // SQL o comando redis; default: SELECT 1 / PING
+	Expect                   string  `yaml:"expect,omitempty" json:"expect,omitempty"`               // valor esperado de la primera columna de la primera fila
+	ExpectedRole             string  `yaml:"expected_role,omitempty" json:"expected_role,omitempty"` // primary, replica
+	MaxReplicationLagSeconds float64 `yaml:"max_replication_lag_seconds,omitempty" json:"max_replication_lag_seconds,omitempty"`
+	MaxConnectionsPercent    float64 `yaml:"max_connections_percent,omitempty" json:"max_connections_percent,omitempty"`
+	TimeoutSeconds           int     `yaml:"timeout_seconds,omitempty" json:"timeout_seconds,omitempty"` // default: 5
+}
+
+// ContainerCheck configuración para contenedores Docker/Podman vía Engine API
+type ContainerCheck struct {
+	Socket          string            `yaml:"socket,omitempty" json:"socket,omitempty"`                       // default: docker.sock o podman.sock
+	Name            string            `yaml:"name,omitempty" json:"name,omitempty"`                           // nombre exacto del contenedor
+	Labels          map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`                       // todos los contenedores con estas labels
+	IgnoreHealth    bool              `yaml:"ignore_health,omitempty" json:"ignore_health,omitempty"`         // no evaluar el HEALTHCHECK
+	MaxRestartCount int               `yaml:"max_restart_count,omitempty" json:"max_restart_count,omitempty"` // degradado si se supera (0 = no verificar)
+}
+
+// PluginConfig configuración de un check o acción implementado por un plugin externo
+type PluginConfig struct {
+	Name   string                 `yaml:"name" json:"name"`                         // ejecutable dentro de plugins_dir
+	Config map[string]interface{} `yaml:"config,omitempty" json:"config,omitempty"` // validado contra el esquema del plugin
+
+	// Completados por SetDefaults
+	Dir    string `yaml:"-" json:"-"`
+	Target string `yaml:"-" json:"-"`
 }
 
 // Action representa la acción a ejecutar cuando falla un target
 type Action struct {
-	Type    string         `yaml:"type" json:"type"` // exec, systemd
-	Exec    *ExecAction    `yaml:"exec,omitempty" json:"exec,omitempty"`
-	Systemd *SystemdAction `yaml:"systemd,omitempty" json:"systemd,omitempty"`
-	Hooks   *ActionHooks   `yaml:"hooks,omitempty" json:"hooks,omitempty"`
+	Type      string                 `yaml:"type" json:"type"` // exec, systemd, plugin, container, http
+	Exec      *ExecAction            `yaml:"exec,omitempty" json:"exec,omitempty"`
+	Systemd   *SystemdAction         `yaml:"systemd,omitempty" json:"systemd,omitempty"`
+	Plugin    *PluginConfig          `yaml:"plugin,omitempty" json:"plugin,omitempty"`
+	Container *ContainerAction       `yaml:"container,omitempty" json:"container,omitempty"`
+	HTTP      *HTTPAction            `yaml:"http,omitempty" json:"http,omitempty"`
+	Options   map[string]interface{} `yaml:"options,omitempty" json:"options,omitempty"` // tipos registrados externamente
+	Hooks     *ActionHooks           `yaml:"hooks,omitempty" json:"hooks,omitempty"`
 }
 
 // ActionHooks define hooks para ejecutar antes/después de acciones
 type ActionHooks struct {
-	BeforeRestart []string `yaml:"before_restart,omitempty" json:"before_restart,omitempty"`
-	AfterRestart  []string `yaml:"after_restart,omitempty" json:"after_restart,omitempty"`
-	OnFailure     []string `yaml:"on_failure,omitempty" json:"on_failure,omitempty"`
+	BeforeRestart []Hook `yaml:"before_restart,omitempty" json:"before_restart,omitempty"`
+	AfterRestart  []Hook `yaml:"after_restart,omitempty" json:"after_restart,omitempty"`
+	OnFailure     []Hook `yaml:"on_failure,omitempty" json:"on_failure,omitempty"`
+
+	ExecOptions `yaml:",inline"`
 }
 
 // ExecAction define comandos a ejecutar
 type ExecAction struct {
 	Start   []string `yaml:"start,omitempty" json:"start,omitempty"`
 	Restart []string `yaml:"restart,omitempty" json:"restart,omitempty"`
+
+	ExecOptions `yaml:",inline"`
+}
+
+// ContainerAction define una acción sobre contenedores Docker/Podman
+type ContainerAction struct {
+	Socket             string            `yaml:"socket,omitempty" json:"socket,omitempty"`
+	Name               string            `yaml:"name,omitempty" json:"name,omitempty"`
+	Labels             map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Method             string            `yaml:"method,omitempty" json:"method,omitempty"`                             // restart (default), start, recreate
+	StopTimeoutSeconds int               `yaml:"stop_timeout_seconds,omitempty" json:"stop_timeout_seconds,omitempty"` // default: 10
 }
 
 // SystemdAction define una acción sobre una unidad systemd
@@ -156,6 +455,19 @@ type SystemdAction struct {
 	Method string `yaml:"method" json:"method"` // restart, start
 }
 
+// HTTPAction configuración de la acción http: llamada a un endpoint de administración
+// (POST /admin/reload) o a la API de un orquestador
+type HTTPAction struct {
+	URL            string            `yaml:"url" json:"url"`
+	Method         string            `yaml:"method,omitempty" json:"method,omitempty"` // default: POST
+	Headers        map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Body           string            `yaml:"body,omitempty" json:"body,omitempty"` // plantilla Go con los campos de los hooks
+	Auth           *HTTPAuth         `yaml:"auth,omitempty" json:"auth,omitempty"`
+	TLS            *HTTPTLS          `yaml:"tls,omitempty" json:"tls,omitempty"`
+	ExpectedStatus []int             `yaml:"expected_status,omitempty" json:"expected_status,omitempty"` // default: cualquier 2xx
+	TimeoutSeconds int               `yaml:"timeout_seconds,omitempty" json:"timeout_seconds,omitempty"` // default: 10
+}
+
 // Load carga y parsea el archivo de configuración
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
@@ -211,10 +523,37 @@ func (c *Config) Validate() error {
 		c.DefaultPolicy.FailThreshold = 1
 	}
 
+	for name := range c.DefaultPolicy.SkipRestartUnderPressure {
+		if !ValidHostMetric(name) {
+			return fmt.Errorf("default_policy: invalid host metric '%s' in skip_restart_under_pressure", name)
+		}
+	}
+	if c.DefaultPolicy.ApprovalTimeoutSeconds < 0 {
+		return fmt.Errorf("default_policy: approval_timeout_seconds must be >= 0")
+	}
+
+	if err := validateMode(c.Mode); err != nil {
+		return err
+	}
+
 	if len(c.Targets) == 0 {
 		return fmt.Errorf("no targets defined")
 	}
 
+	if c.Maintenance != nil {
+		if err := validateMaintenance(c.Maintenance, c.Targets); err != nil {
+			return fmt.Errorf("maintenance.%w", err)
+		}
+	}
+
+	if c.Instance != nil {
+		switch c.Instance.OnLocked {
+		case "", OnLockedDelegate, OnLockedSkip:
+		default:
+			return fmt.Errorf("instance: invalid on_locked '%s' (must be: delegate, skip)", c.Instance.OnLocked)
+		}
+	}
+
 	// Validar cada target
 	for i, target := range c.Targets {
 		if target.Name == "" {
@@ -227,73 +566,333 @@ func (c *Config) Validate() error {
 
 		// Validar checks
 		for j, check := range target.Checks {
-			if err := validateCheck(check, target.Name, j); err != nil {
+			if err := validateCheck(check, target.Name, j, c); err != nil {
 				return err
 			}
 		}
 
+		if err := validateHealthyWhen(target); err != nil {
+			return err
+		}
+
 		// Validar action
-		if err := validateAction(target.Action, target.Name); err != nil {
+		if err := validateAction(target.Action, target.Name, c); err != nil {
 			return err
 		}
+
+		if err := validateMode(target.Mode); err != nil {
+			return fmt.Errorf("target[%s]: %w", target.Name, err)
+		}
+		// Las acciones se aprueban desde la API del dashboard (también neon.sh)
+		if c.ModeFor(target) == ModeApproval && (c.Dashboard == nil || !c.Dashboard.Enabled) {
+			return fmt.Errorf("target[%s]: mode 'approval' requires the dashboard to be enabled: it is the only way to approve actions", target.Name)
+		}
+
+		if target.Diagnostics != nil {
+			if err := validateDiagnostics(target.Diagnostics); err != nil {
+				return fmt.Errorf("target[%s].diagnostics: %w", target.Name, err)
+			}
+		}
+
+		if target.Policy != nil {
+			for name := range target.Policy.SkipRestartUnderPressure {
+				if !ValidHostMetric(name) {
+					return fmt.Errorf("target[%s].policy: invalid host metric '%s' in skip_restart_under_pressure", target.Name, name)
+				}
+			}
+			if target.Policy.ApprovalTimeoutSeconds < 0 {
+				return fmt.Errorf("target[%s].policy: approval_timeout_seconds must be >= 0", target.Name)
+			}
+		}
 	}
 
 	return nil
 }
 
+// checkNamePattern nombres de check utilizables como identificador en healthy_when
+var checkNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateHealthyWhen valida los nombres de los checks y compila la expresión healthy_when
+func validateHealthyWhen(target Target) error {
+	names := make(map[string]bool)
+	for j, check := range target.Checks {
+		if check.Name == "" {
+			continue
+		}
+		if !checkNamePattern.MatchString(check.Name) {
+			return fmt.Errorf("target[%s].checks[%d]: invalid name '%s' (letters, digits and _ only)", target.Name, j, check.Name)
+		}
+		if names[check.Name] {
+			return fmt.Errorf("target[%s].checks[%d]: duplicate check name '%s'", target.Name, j, check.Name)
+		}
+		names[check.Name] = true
+	}
+
+	if target.HealthyWhen == "" {
+		return nil
+	}
+	expr, err := rules.Compile(target.HealthyWhen)
+	if err != nil {
+		return fmt.Errorf("target[%s].healthy_when: %w", target.Name, err)
+	}
+	for _, ref := range expr.Refs() {
+		if !names[ref] {
+			return fmt.Errorf("target[%s].healthy_when: unknown check '%s'", target.Name, ref)
+		}
+	}
+	return nil
+}
+
 // validateCheck valida un check individual
-func validateCheck(check Check, targetName string, index int) error {
-	validTypes := map[string]bool{
-		"process_name": true,
-		"pid_file":     true,
-		"tcp_port":     true,
-		"command":      true,
-		"http":         true,
-		"script":       true,
-		"logic":        true,
+func validateCheck(check Check, targetName string, index int, cfg *Config) error {
+	if err := validateCheckConfig(check, cfg); err != nil {
+		return fmt.Errorf("target[%s].checks[%d]: %w", targetName, index, err)
 	}
+	return nil
+}
 
-	if !validTypes[check.Type] {
-		return fmt.Errorf("target[%s].checks[%d]: invalid type '%s' (must be: process_name, pid_file, tcp_port, command, http, script, logic)",
-			targetName, index, check.Type)
+// validateCheckConfig valida un check con el validador registrado para su tipo
+func validateCheckConfig(check Check, cfg *Config) error {
+	validate, ok := lookupCheckType(check.Type)
+	if !ok {
+		return fmt.Errorf("invalid type '%s' (must be: %s)", check.Type, strings.Join(CheckTypes(), ", "))
+	}
+	if check.Retries < 0 {
+		return fmt.Errorf("retries must be >= 0")
+	}
+	if err := validateCheckExecOptions(check); err != nil {
+		return err
 	}
+	if check.RetryDelayMs < 0 {
+		return fmt.Errorf("retry_delay_ms must be >= 0")
+	}
+	if check.MinSuccess < 0 || check.MinSuccess > check.Retries+1 {
+		return fmt.Errorf("min_success must be between 1 and retries+1 (%d)", check.Retries+1)
+	}
+	if validate == nil {
+		return nil
+	}
+	return validate(check, cfg)
+}
 
+// validateCheckExecOptions valida las opciones de ejecución al nivel del check. Solo las usa
+// command; script (salvo max_output_bytes) y nagios_plugin las llevan en su bloque y los demás
+// tipos no lanzan procesos. Así no se resuelven usuarios ni se leen env_file de opciones que
+// nunca se aplican
+func validateCheckExecOptions(check Check) error {
+	opts := check.ExecOptions
+	switch check.Type {
+	case "command":
+		return ValidateExecOptions(opts)
+	case "script":
+		if opts.MaxOutputBytes < 0 {
+			return fmt.Errorf("max_output_bytes must be >= 0")
+		}
+		opts.MaxOutputBytes = 0
+	}
+	if set := execOptionNames(opts); len(set) > 0 {
+		if check.Type == "script" || check.Type == "nagios_plugin" {
+			return fmt.Errorf("%s must be set inside '%s'", strings.Join(set, ", "), check.Type)
+		}
+		return fmt.Errorf("%s not supported for type '%s'", strings.Join(set, ", "), check.Type)
+	}
+	return nil
+}
+
+// execOptionNames nombres de las opciones de ejecución configuradas
+func execOptionNames(opts ExecOptions) []string {
+	names := []string{}
+	if len(opts.Env) > 0 {
+		names = append(names, "env")
+	}
+	if opts.EnvFile != "" {
+		names = append(names, "env_file")
+	}
+	if opts.ClearEnv {
+		names = append(names, "clear_env")
+	}
+	if opts.WorkingDir != "" {
+		names = append(names, "working_dir")
+	}
+	if opts.User != "" {
+		names = append(names, "user")
+	}
+	if opts.Group != "" {
+		names = append(names, "group")
+	}
+	if opts.Umask != "" {
+		names = append(names, "umask")
+	}
+	if opts.Nice != 0 {
+		names = append(names, "nice")
+	}
+	if len(opts.Limits) > 0 {
+		names = append(names, "limits")
+	}
+	if opts.MaxOutputBytes != 0 {
+		names = append(names, "max_output_bytes")
+	}
+	return names
+}
+
+// validateBuiltinCheck valida los tipos de check incluidos en el watchdog
+func validateBuiltinCheck(check Check, cfg *Config) error {
 	switch check.Type {
 	case "process_name":
 		if check.ProcessName == "" {
-			return fmt.Errorf("target[%s].checks[%d]: process_name is required for type 'process_name'", targetName, index)
+			return fmt.Errorf("process_name is required for type 'process_name'")
 		}
 	case "pid_file":
 		if check.PidFile == "" {
-			return fmt.Errorf("target[%s].checks[%d]: pid_file is required for type 'pid_file'", targetName, index)
+			return fmt.Errorf("pid_file is required for type 'pid_file'")
 		}
 	case "tcp_port":
 		if check.TcpPort == "" {
-			return fmt.Errorf("target[%s].checks[%d]: tcp_port is required for type 'tcp_port'", targetName, index)
+			return fmt.Errorf("tcp_port is required for type 'tcp_port'")
 		}
 	case "command":
 		if len(check.Command) == 0 {
-			return fmt.Errorf("target[%s].checks[%d]: command is required for type 'command'", targetName, index)
+			return fmt.Errorf("command is required for type 'command'")
 		}
 	case "http":
 		if check.HTTP == nil || check.HTTP.URL == "" {
-			return fmt.Errorf("target[%s].checks[%d]: http.url is required for type 'http'", targetName, index)
+			return fmt.Errorf("http.url is required for type 'http'")
+		}
+		if err := validateHTTPClient(check.HTTP.URL, check.HTTP.Auth, check.HTTP.TLS); err != nil {
+			return fmt.Errorf("http: %w", err)
 		}
 	case "script":
 		if check.Script == nil || check.Script.Path == "" {
-			return fmt.Errorf("target[%s].checks[%d]: script.path is required for type 'script'", targetName, index)
+			return fmt.Errorf("script.path is required for type 'script'")
 		}
-	case "logic":
-		if check.Logic != "AND" && check.Logic != "OR" {
-			return fmt.Errorf("target[%s].checks[%d]: logic must be 'AND' or 'OR'", targetName, index)
+		if err := ValidateExecOptions(check.Script.ExecOptions); err != nil {
+			return fmt.Errorf("script: %w", err)
+		}
+	case "heartbeat":
+		if check.Heartbeat == nil || check.Heartbeat.Token == "" {
+			return fmt.Errorf("heartbeat.token is required for type 'heartbeat'")
+		}
+		if check.Heartbeat.PeriodSeconds <= 0 {
+			return fmt.Errorf("heartbeat.period_seconds must be > 0")
+		}
+		if check.Heartbeat.GraceSeconds < 0 || check.Heartbeat.MaxDurationSeconds < 0 {
+			return fmt.Errorf("heartbeat.grace_seconds and max_duration_seconds must be >= 0")
+		}
+	case "log_pattern":
+		lp := check.LogPattern
+		if lp == nil || lp.Pattern == "" {
+			return fmt.Errorf("log_pattern.pattern is required for type 'log_pattern'")
+		}
+		if (lp.Path == "") == (lp.JournalUnit == "") {
+			return fmt.Errorf("exactly one of log_pattern.path or log_pattern.journal_unit is required")
+		}
+		if _, err := regexp.Compile(lp.Pattern); err != nil {
+			return fmt.Errorf("invalid log_pattern.pattern: %w", err)
+		}
+		if lp.WindowSeconds < 0 || lp.Threshold < 0 {
+			return fmt.Errorf("log_pattern.window_seconds and threshold must be >= 0")
+		}
+	case "kernel_events":
+		ke := check.KernelEvents
+		if ke == nil || (len(ke.ProcessNames) == 0 && ke.Cgroup == "") {
+			return fmt.Errorf("kernel_events.process_names or kernel_events.cgroup is required for type 'kernel_events'")
+		}
+		validEvents := map[string]bool{"oom": true, "segfault": true, "hung_task": true}
+		for _, ev := range ke.Events {
+			if !validEvents[ev] {
+				return fmt.Errorf("invalid kernel event '%s' (must be: oom, segfault, hung_task)", ev)
+			}
+		}
+		if ke.WindowSeconds < 0 {
+			return fmt.Errorf("kernel_events.window_seconds must be >= 0")
+		}
+	case "host":
+		if check.Host == nil || len(check.Host.Thresholds) == 0 {
+			return fmt.Errorf("host.thresholds is required for type 'host'")
+		}
+		for name := range check.Host.Thresholds {
+			if !ValidHostMetric(name) {
+				return fmt.Errorf("invalid host metric '%s'", name)
+			}
+		}
+	case "listening":
+		l := check.Listening
+		if l == nil || l.ProcessName == "" {
+			return fmt.Errorf("listening.process_name is required for type 'listening'")
+		}
+		switch l.Protocol {
+		case "", "tcp", "udp":
+			if l.Port <= 0 || l.Port > 65535 {
+				return fmt.Errorf("listening.port must be between 1 and 65535")
+			}
+		case "unix":
+			if l.Path == "" {
+				return fmt.Errorf("listening.path is required for protocol 'unix'")
+			}
+		default:
+			return fmt.Errorf("invalid listening.protocol '%s' (must be: tcp, udp, unix)", l.Protocol)
+		}
+	case "nagios_plugin":
+		np := check.NagiosPlugin
+		if np == nil || np.Path == "" {
+			return fmt.Errorf("nagios_plugin.path is required for type 'nagios_plugin'")
+		}
+		if np.TreatUnknownAs != "" && np.TreatUnknownAs != "critical" && np.TreatUnknownAs != "warning" {
+			return fmt.Errorf("nagios_plugin.treat_unknown_as must be 'critical' or 'warning'")
+		}
+		if err := ValidateExecOptions(np.ExecOptions); err != nil {
+			return fmt.Errorf("nagios_plugin: %w", err)
+		}
+	case "plugin":
+		if check.Plugin == nil || check.Plugin.Name == "" {
+			return fmt.Errorf("plugin.name is required for type 'plugin'")
+		}
+		return validatePlugin(cfg.pluginsDir(), check.Plugin.Name, "check", check.Plugin.Config)
+	case "grpc":
+		g := check.GRPC
+		if g == nil || g.Address == "" {
+			return fmt.Errorf("grpc.address is required for type 'grpc'")
+		}
+		if (g.ClientCert == "") != (g.ClientKey == "") {
+			return fmt.Errorf("grpc.client_cert and grpc.client_key must be set together")
+		}
+		if !g.TLS && (g.CACert != "" || g.ClientCert != "" || g.InsecureSkipVerify) {
+			return fmt.Errorf("grpc TLS options require grpc.tls: true")
+		}
+		if g.TreatUnknownAs != "" && g.TreatUnknownAs != "critical" && g.TreatUnknownAs != "warning" {
+			return fmt.Errorf("grpc.treat_unknown_as must be 'critical' or 'warning'")
+		}
+		for key := range g.Metadata {
+			lower := strings.ToLower(key)
+			if key == "" || strings.HasPrefix(lower, "grpc-") || strings.HasPrefix(lower, ":") {
+				return fmt.Errorf("invalid grpc.metadata key '%s' (reserved)", key)
+			}
 		}
+	case "postgres":
+		return validateDatabaseCheck("postgres", check.Postgres)
+	case "mysql":
+		return validateDatabaseCheck("mysql", check.MySQL)
+	case "redis":
+		return validateDatabaseCheck("redis", check.Redis)
+	case "container":
+		ct := check.Container
+		if ct == nil || (ct.Name == "" && len(ct.Labels) == 0) {
+			return fmt.Errorf("container.name or container.labels is required for type 'container'")
+		}
+		if ct.MaxRestartCount < 0 {
+			return fmt.Errorf("container.max_restart_count must be >= 0")
+		}
+	case "logic":
 		if len(check.Checks) == 0 {
-			return fmt.Errorf("target[%s].checks[%d]: logic groups must have at least one check", targetName, index)
+			return fmt.Errorf("logic groups must have at least one check")
+		}
+		if _, err := LogicMinPassing(check.Logic, len(check.Checks)); err != nil {
+			return err
 		}
 		// Validar checks anidados
 		for j, subCheck := range check.Checks {
-			if err := validateCheck(subCheck, targetName, j); err != nil {
-				return err
+			if err := validateCheckConfig(subCheck, cfg); err != nil {
+				return fmt.Errorf("checks[%d]: %w", j, err)
 			}
 		}
 	}
@@ -301,37 +900,345 @@ func validateCheck(check Check, targetName string, index int) error {
 	return nil
 }
 
-// validateAction valida una acción
-func validateAction(action Action, targetName string) error {
-	validTypes := map[string]bool{"exec": true, "systemd": true}
+// LogicMinPassing número de checks que deben pasar en un grupo logic de total checks:
+// AND todos, OR uno, QUORUM la mayoría y "AT_LEAST n" al menos n
+func LogicMinPassing(logic string, total int) (int, error) {
+	switch logic {
+	case "AND":
+		return total, nil
+	case "OR":
+		return 1, nil
+	case "QUORUM":
+		return total/2 + 1, nil
+	}
+
+	if rest, ok := strings.CutPrefix(logic, "AT_LEAST"); ok {
+		n, err := strconv.Atoi(strings.TrimSpace(rest))
+		if err != nil || rest == "" || rest[0] != ' ' {
+			return 0, fmt.Errorf("invalid logic '%s' (must be: AT_LEAST <n>)", logic)
+		}
+		if n < 1 || n > total {
+			return 0, fmt.Errorf("logic '%s' requires n between 1 and %d", logic, total)
+		}
+		return n, nil
+	}
+	return 0, fmt.Errorf("logic must be 'AND', 'OR', 'QUORUM' or 'AT_LEAST <n>'")
+}
+
+// validateDatabaseCheck valida la configuración común de postgres, mysql y redis
+func validateDatabaseCheck(checkType string, db *DatabaseCheck) error {
+	if db == nil || db.Address == "" {
+		return fmt.Errorf("%s.address is required for type '%s'", checkType, checkType)
+	}
+	if db.PasswordFile != "" && db.PasswordEnv != "" {
+		return fmt.Errorf("%s: only one of password_file or password_env can be set", checkType)
+	}
+	if db.ExpectedRole != "" && db.ExpectedRole != "primary" && db.ExpectedRole != "replica" {
+		return fmt.Errorf("%s.expected_role must be 'primary' or 'replica'", checkType)
+	}
+	if db.MaxReplicationLagSeconds < 0 || db.MaxConnectionsPercent < 0 || db.MaxConnectionsPercent > 100 {
+		return fmt.Errorf("%s: max_replication_lag_seconds must be >= 0 and max_connections_percent between 0 and 100", checkType)
+	}
+	if !db.TLS && (db.CACert != "" || db.InsecureSkipVerify) {
+		return fmt.Errorf("%s TLS options require %s.tls: true", checkType, checkType)
+	}
+	return nil
+}
+
+// validateAction valida una acción con el validador registrado para su tipo
+func validateAction(action Action, targetName string, cfg *Config) error {
+	validate, ok := lookupActionType(action.Type)
+	if !ok {
+		return fmt.Errorf("target[%s].action: invalid type '%s' (must be: %s)", targetName, action.Type, strings.Join(ActionTypes(), ", "))
+	}
+	if action.Hooks != nil {
+		if err := validateHooks(action.Hooks); err != nil {
+			return fmt.Errorf("target[%s].action.hooks: %w", targetName, err)
+		}
+	}
+	if validate == nil {
+		return nil
+	}
+	if err := validate(action, cfg); err != nil {
+		return fmt.Errorf("target[%s].action: %w", targetName, err)
+	}
+	return nil
+}
+
+// validateMode valida el modo de las acciones (vacío = heredado/enforce)
+func validateMode(mode string) error {
+	switch mode {
+	case "", ModeEnforce, ModeDryRun, ModeApproval:
+		return nil
+	}
+	return fmt.Errorf("invalid mode '%s' (must be: enforce, dry_run, approval)", mode)
+}
+
+// ModeFor retorna el modo efectivo del target: el suyo, el global o enforce
+func (c *Config) ModeFor(target Target) string {
+	switch {
+	case target.Mode != "":
+		return target.Mode
+	case c.Mode != "":
+		return c.Mode
+	}
+	return ModeEnforce
+}
+
+// ValidateOneshot comprueba que la configuración sirve para una pasada sin daemon (timer):
+// en modo approval nadie podría aprobar las acciones encoladas
+func (c *Config) ValidateOneshot() error {
+	for _, target := range c.GetActiveTargets() {
+		if c.ModeFor(target) == ModeApproval {
+			return fmt.Errorf("target[%s]: mode 'approval' requires daemon mode (run); the timer cannot wait for a decision", target.Name)
+		}
+	}
+	return nil
+}
+
+// validateDiagnostics valida los límites y los comandos de diagnóstico
+func validateDiagnostics(diag *DiagnosticsConfig) error {
+	if diag.LogLines < 0 || diag.TimeoutSeconds < 0 || diag.MaxBundles < 0 || diag.MaxSizeMB < 0 {
+		return fmt.Errorf("log_lines, timeout_seconds, max_bundles and max_size_mb must be >= 0")
+	}
+	if err := ValidateExecOptions(diag.ExecOptions); err != nil {
+		return err
+	}
+	for i, cmd := range diag.Commands {
+		if cmd.OnError != "" {
+			return fmt.Errorf("commands[%d]: on_error is not supported in diagnostics", i)
+		}
+		if err := cmd.validate(); err != nil {
+			return fmt.Errorf("commands[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateHooks valida las opciones comunes y cada hook de las tres fases
+func validateHooks(hooks *ActionHooks) error {
+	if err := ValidateExecOptions(hooks.ExecOptions); err != nil {
+		return err
+	}
+	phases := []struct {
+		name  string
+		hooks []Hook
+	}{
+		{"before_restart", hooks.BeforeRestart},
+		{"after_restart", hooks.AfterRestart},
+		{"on_failure", hooks.OnFailure},
+	}
+	for _, phase := range phases {
+		for i, hook := range phase.hooks {
+			if err := hook.validate(); err != nil {
+				return fmt.Errorf("%s[%d]: %w", phase.name, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateExecOptions valida las opciones de ejecución de un proceso
+func ValidateExecOptions(opts ExecOptions) error {
+	if opts.MaxOutputBytes < 0 {
+		return fmt.Errorf("max_output_bytes must be >= 0")
+	}
+	if opts.Nice < -20 || opts.Nice > 19 {
+		return fmt.Errorf("nice must be between -20 and 19")
+	}
+	if opts.Umask != "" {
+		if _, err := ParseUmask(opts.Umask); err != nil {
+			return err
+		}
+	}
+	for name := range opts.Limits {
+		if !slices.Contains(ResourceLimits, name) {
+			return fmt.Errorf("invalid limit '%s' (must be: %s)", name, strings.Join(ResourceLimits, ", "))
+		}
+	}
+	for key := range opts.Env {
+		if key == "" || strings.ContainsAny(key, "=\x00") {
+			return fmt.Errorf("invalid env variable name '%s'", key)
+		}
+	}
+	if opts.EnvFile != "" {
+		if _, err := ReadEnvFile(opts.EnvFile); err != nil {
+			return err
+		}
+	}
+	if opts.WorkingDir != "" && !filepath.IsAbs(opts.WorkingDir) {
+		return fmt.Errorf("working_dir must be an absolute path")
+	}
+	if opts.User != "" {
+		if _, err := LookupUser(opts.User); err != nil {
+			return err
+		}
+	}
+	if opts.Group != "" {
+		if _, err := LookupGroup(opts.Group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseUmask interpreta un umask en octal ("027" o "0027")
+func ParseUmask(value string) (int, error) {
+	umask, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || umask > 0o777 {
+		return 0, fmt.Errorf("invalid umask '%s' (must be octal, e.g. 0027)", value)
+	}
+	return int(umask), nil
+}
+
+// ReadEnvFile lee un archivo de variables KEY=VALUE. Admite comentarios con #, líneas
+// vacías, el prefijo "export " y valores entre comillas simples o dobles
+func ReadEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read env_file: %w", err)
+	}
+
+	env := make(map[string]string)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, i+1)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env[key] = value
+	}
+	return env, nil
+}
+
+// LookupUser busca un usuario por nombre o UID
+func LookupUser(name string) (*user.User, error) {
+	u, err := user.Lookup(name)
+	if err == nil {
+		return u, nil
+	}
+	if _, convErr := strconv.Atoi(name); convErr == nil {
+		if u, err := user.LookupId(name); err == nil {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown user '%s'", name)
+}
 
-	if !validTypes[action.Type] {
-		return fmt.Errorf("target[%s].action: invalid type '%s' (must be: exec, systemd)", targetName, action.Type)
+// LookupGroup busca un grupo por nombre o GID
+func LookupGroup(name string) (*user.Group, error) {
+	g, err := user.LookupGroup(name)
+	if err == nil {
+		return g, nil
+	}
+	if _, convErr := strconv.Atoi(name); convErr == nil {
+		if g, err := user.LookupGroupId(name); err == nil {
+			return g, nil
+		}
 	}
+	return nil, fmt.Errorf("unknown group '%s'", name)
+}
 
+// validateBuiltinAction valida los tipos de acción incluidos en el watchdog
+func validateBuiltinAction(action Action, cfg *Config) error {
 	switch action.Type {
 	case "exec":
 		if action.Exec == nil {
-			return fmt.Errorf("target[%s].action: exec configuration is required for type 'exec'", targetName)
+			return fmt.Errorf("exec configuration is required for type 'exec'")
 		}
 		if len(action.Exec.Start) == 0 && len(action.Exec.Restart) == 0 {
-			return fmt.Errorf("target[%s].action: at least one of 'start' or 'restart' must be defined", targetName)
+			return fmt.Errorf("at least one of 'start' or 'restart' must be defined")
+		}
+		if err := ValidateExecOptions(action.Exec.ExecOptions); err != nil {
+			return fmt.Errorf("exec: %w", err)
 		}
 	case "systemd":
 		if action.Systemd == nil {
-			return fmt.Errorf("target[%s].action: systemd configuration is required for type 'systemd'", targetName)
+			return fmt.Errorf("systemd configuration is required for type 'systemd'")
 		}
 		if action.Systemd.Unit == "" {
-			return fmt.Errorf("target[%s].action: systemd.unit is required", targetName)
+			return fmt.Errorf("systemd.unit is required")
 		}
 		if action.Systemd.Method == "" {
 			action.Systemd.Method = "restart"
 		}
+	case "container":
+		ct := action.Container
+		if ct == nil || (ct.Name == "" && len(ct.Labels) == 0) {
+			return fmt.Errorf("container.name or container.labels is required for type 'container'")
+		}
+		if ct.Method != "" && ct.Method != "restart" && ct.Method != "start" && ct.Method != "recreate" {
+			return fmt.Errorf("invalid container.method '%s' (must be: restart, start, recreate)", ct.Method)
+		}
+		if ct.StopTimeoutSeconds < 0 {
+			return fmt.Errorf("container.stop_timeout_seconds must be >= 0")
+		}
+	case "http":
+		h := action.HTTP
+		if h == nil || h.URL == "" {
+			return fmt.Errorf("http.url is required for type 'http'")
+		}
+		if err := validateHTTPClient(h.URL, h.Auth, h.TLS); err != nil {
+			return fmt.Errorf("http: %w", err)
+		}
+		for _, status := range h.ExpectedStatus {
+			if status < 100 || status > 599 {
+				return fmt.Errorf("invalid http.expected_status %d", status)
+			}
+		}
+		if h.TimeoutSeconds < 0 {
+			return fmt.Errorf("http.timeout_seconds must be >= 0")
+		}
+		if _, err := ParseHookTemplate(h.Body); err != nil {
+			return fmt.Errorf("http.body: %w", err)
+		}
+	case "plugin":
+		if action.Plugin == nil || action.Plugin.Name == "" {
+			return fmt.Errorf("plugin.name is required for type 'plugin'")
+		}
+		return validatePlugin(cfg.pluginsDir(), action.Plugin.Name, "action", action.Plugin.Config)
 	}
 
 	return nil
 }
 
+// validateHTTPClient valida la URL, la autenticación y el TLS comunes al check y la acción http
+func validateHTTPClient(rawURL string, auth *HTTPAuth, tlsCfg *HTTPTLS) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url '%s' (must be http:// or https://)", rawURL)
+	}
+
+	if auth != nil {
+		basic := auth.Username != "" || auth.Password != "" || auth.PasswordFile != ""
+		bearer := auth.BearerToken != "" || auth.BearerTokenFile != ""
+		switch {
+		case basic && bearer:
+			return fmt.Errorf("auth: use either username/password or bearer_token")
+		case auth.Password != "" && auth.PasswordFile != "":
+			return fmt.Errorf("auth: password and password_file are mutually exclusive")
+		case auth.BearerToken != "" && auth.BearerTokenFile != "":
+			return fmt.Errorf("auth: bearer_token and bearer_token_file are mutually exclusive")
+		case basic && auth.Username == "":
+			return fmt.Errorf("auth: username is required for basic auth")
+		}
+	}
+
+	if tlsCfg != nil && (tlsCfg.ClientCert == "") != (tlsCfg.ClientKey == "") {
+		return fmt.Errorf("tls.client_cert and tls.client_key must be set together")
+	}
+	return nil
+}
+
 // SetDefaults establece valores por defecto
 func (c *Config) SetDefaults() {
 	if c.LogLevel == "" {
@@ -354,8 +1261,24 @@ func (c *Config) SetDefaults() {
 		c.DefaultPolicy.MaxRestartsPerHour = 10
 	}
 
+	if c.DefaultPolicy.ApprovalTimeoutSeconds <= 0 {
+		c.DefaultPolicy.ApprovalTimeoutSeconds = 900
+	}
+
+	if c.PluginsDir == "" {
+		c.PluginsDir = DefaultPluginsDir
+	}
+
 	// Aplicar política por defecto a targets que no la tienen
 	for i := range c.Targets {
+		for j := range c.Targets[i].Checks {
+			c.Targets[i].Checks[j].setPluginDefaults(c.PluginsDir, c.Targets[i].Name)
+		}
+		if p := c.Targets[i].Action.Plugin; p != nil {
+			p.Dir = c.PluginsDir
+			p.Target = c.Targets[i].Name
+		}
+
 		if c.Targets[i].Policy == nil {
 			c.Targets[i].Policy = &c.DefaultPolicy
 		} else {
@@ -369,10 +1292,122 @@ func (c *Config) SetDefaults() {
 			if c.Targets[i].Policy.MaxRestartsPerHour <= 0 {
 				c.Targets[i].Policy.MaxRestartsPerHour = c.DefaultPolicy.MaxRestartsPerHour
 			}
+			if c.Targets[i].Policy.SkipRestartUnderPressure == nil {
+				c.Targets[i].Policy.SkipRestartUnderPressure = c.DefaultPolicy.SkipRestartUnderPressure
+			}
+			if c.Targets[i].Policy.ApprovalTimeoutSeconds <= 0 {
+				c.Targets[i].Policy.ApprovalTimeoutSeconds = c.DefaultPolicy.ApprovalTimeoutSeconds
+			}
 		}
 	}
 }
 
+// pluginsDir retorna el directorio de plugins configurado o el de por defecto
+func (c *Config) pluginsDir() string {
+	if c.PluginsDir == "" {
+		return DefaultPluginsDir
+	}
+	return c.PluginsDir
+}
+
+// setPluginDefaults completa directorio y target en los checks de tipo plugin, incluidos los anidados
+func (c *Check) setPluginDefaults(dir, target string) {
+	if c.Plugin != nil {
+		c.Plugin.Dir = dir
+		c.Plugin.Target = target
+	}
+	for i := range c.Checks {
+		c.Checks[i].setPluginDefaults(dir, target)
+	}
+}
+
+// stateDir directorio del state_file, donde viven el bloqueo y el socket de control
+func (c *Config) stateDir() string {
+	if c.StateFile == "" {
+		return DefaultStateDir
+	}
+	return filepath.Dir(c.StateFile)
+}
+
+// LockFile retorna la ruta del bloqueo de instancia única
+func (c *Config) LockFile() string {
+	if c.Instance != nil && c.Instance.LockFile != "" {
+		return c.Instance.LockFile
+	}
+	return filepath.Join(c.stateDir(), "neon-watchdog.lock")
+}
+
+// ControlSocket retorna la ruta del socket de control del daemon
+func (c *Config) ControlSocket() string {
+	if c.Instance != nil && c.Instance.ControlSocket != "" {
+		return c.Instance.ControlSocket
+	}
+	return filepath.Join(c.stateDir(), "neon-watchdog.sock")
+}
+
+// OnLocked retorna qué hace un check puntual cuando el daemon tiene el bloqueo
+func (c *Config) OnLocked() string {
+	if c.Instance != nil && c.Instance.OnLocked != "" {
+		return c.Instance.OnLocked
+	}
+	return OnLockedDelegate
+}
+
+// PerfdataInterval retorna cada cuánto se guarda en el historial la perfdata de un check
+func (c *Config) PerfdataInterval() time.Duration {
+	if c.History != nil && c.History.PerfdataIntervalSeconds > 0 {
+		return time.Duration(c.History.PerfdataIntervalSeconds) * time.Second
+	}
+	return 5 * time.Minute
+}
+
+// HeartbeatStateFile retorna dónde se guardan los pings de heartbeat. Por defecto junto al
+// state_file, para que los checks del timer vean los pings recibidos entre ejecuciones
+func (c *Config) HeartbeatStateFile() string {
+	if c.Heartbeat != nil && c.Heartbeat.StateFile != "" {
+		return c.Heartbeat.StateFile
+	}
+	if c.StateFile == "" {
+		return ""
+	}
+	return filepath.Join(c.stateDir(), "heartbeat.json")
+}
+
+// CheckStateDir retorna dónde guardan su posición los checks log_pattern y kernel_events sin
+// offset_file: junto al state_file, o "" (solo en memoria) si no hay state_file
+func (c *Config) CheckStateDir() string {
+	if c.StateFile == "" {
+		return ""
+	}
+	return c.stateDir()
+}
+
+// ApprovalsFile retorna dónde se guarda la cola de aprobaciones (junto al state_file; ""
+// la mantiene solo en memoria)
+func (c *Config) ApprovalsFile() string {
+	if c.StateFile == "" {
+		return ""
+	}
+	return filepath.Join(c.stateDir(), "approvals.json")
+}
+
+// SilencesFile retorna dónde se guardan los silencios de mantenimiento (default: junto al
+// state_file)
+func (c *Config) SilencesFile() string {
+	if c.Maintenance != nil && c.Maintenance.SilencesFile != "" {
+		return c.Maintenance.SilencesFile
+	}
+	return filepath.Join(c.stateDir(), "silences.json")
+}
+
+// HeartbeatSocket retorna el socket Unix de pings de heartbeat ("" si no está configurado)
+func (c *Config) HeartbeatSocket() string {
+	if c.Heartbeat == nil {
+		return ""
+	}
+	return c.Heartbeat.SocketPath
+}
+
 // GetActiveTargets retorna solo los targets habilitados
 func (c *Config) GetActiveTargets() []Target {
 	active := []Target{}
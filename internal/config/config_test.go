@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadYAML carga una configuración escrita en un archivo temporal
+func loadYAML(t *testing.T, text string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(text), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return Load(path)
+}
+
+func TestApprovalModeRequiresDashboard(t *testing.T) {
+	const target = `
+targets:
+  - name: api
+    enabled: true
+    mode: approval
+    checks:
+      - type: tcp_port
+        tcp_port: "127.0.0.1:8080"
+    action:
+      type: exec
+      exec: {restart: [/bin/true]}
+`
+	_, err := loadYAML(t, target)
+	if err == nil || !strings.Contains(err.Error(), "requires the dashboard") {
+		t.Fatalf("expected approval without dashboard to be rejected, got %v", err)
+	}
+
+	if _, err := loadYAML(t, "dashboard: {enabled: true, port: 8080}\n"+target); err != nil {
+		t.Fatalf("approval with dashboard rejected: %v", err)
+	}
+}
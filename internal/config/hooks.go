@@ -0,0 +1,188 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Hook comando ejecutado antes o después de una acción. En la configuración puede
+// escribirse como string (se ejecuta con /bin/sh -c), como lista argv o como objeto
+// con timeout y política de error. Command admite plantillas Go en cada argumento; Shell
+// no: el script recibe los datos del evento en variables NEON_*
+type Hook struct {
+	Shell          string   `yaml:"shell,omitempty" json:"shell,omitempty"`
+	Command        []string `yaml:"command,omitempty" json:"command,omitempty"`
+	TimeoutSeconds int      `yaml:"timeout_seconds,omitempty" json:"timeout_seconds,omitempty"` // 0 = timeout de la acción
+	OnError        string   `yaml:"on_error,omitempty" json:"on_error,omitempty"`               // ignore (default), abort
+}
+
+// hookFields alias sin métodos para decodificar la forma objeto
+type hookFields Hook
+
+// UnmarshalYAML acepta string, lista argv u objeto
+func (h *Hook) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		*h = Hook{Shell: value.Value}
+		return nil
+	case yaml.SequenceNode:
+		var argv []string
+		if err := value.Decode(&argv); err != nil {
+			return err
+		}
+		*h = Hook{Command: argv}
+		return nil
+	}
+	var fields hookFields
+	if err := value.Decode(&fields); err != nil {
+		return err
+	}
+	*h = Hook(fields)
+	return nil
+}
+
+// MarshalYAML conserva la forma corta cuando el hook no tiene opciones
+func (h Hook) MarshalYAML() (interface{}, error) {
+	if short := h.short(); short != nil {
+		return short, nil
+	}
+	return hookFields(h), nil
+}
+
+// UnmarshalJSON acepta string, lista argv u objeto
+func (h *Hook) UnmarshalJSON(data []byte) error {
+	var shell string
+	if err := json.Unmarshal(data, &shell); err == nil {
+		*h = Hook{Shell: shell}
+		return nil
+	}
+	var argv []string
+	if err := json.Unmarshal(data, &argv); err == nil {
+		*h = Hook{Command: argv}
+		return nil
+	}
+	var fields hookFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*h = Hook(fields)
+	return nil
+}
+
+// MarshalJSON conserva la forma corta cuando el hook no tiene opciones
+func (h Hook) MarshalJSON() ([]byte, error) {
+	if short := h.short(); short != nil {
+		return json.Marshal(short)
+	}
+	return json.Marshal(hookFields(h))
+}
+
+func (h Hook) short() interface{} {
+	if h.TimeoutSeconds != 0 || h.OnError != "" {
+		return nil
+	}
+	if h.Shell != "" && len(h.Command) == 0 {
+		return h.Shell
+	}
+	if h.Shell == "" && len(h.Command) > 0 {
+		return h.Command
+	}
+	return nil
+}
+
+// String representación del hook para logs
+func (h Hook) String() string {
+	if h.Shell != "" {
+		return h.Shell
+	}
+	return strings.Join(h.Command, " ")
+}
+
+func (h Hook) validate() error {
+	if (h.Shell == "") == (len(h.Command) == 0) {
+		return fmt.Errorf("exactly one of shell or command is required")
+	}
+	if h.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeout_seconds must be >= 0")
+	}
+	if h.OnError != "" && h.OnError != "ignore" && h.OnError != "abort" {
+		return fmt.Errorf("invalid on_error '%s' (must be: ignore, abort)", h.OnError)
+	}
+	if strings.Contains(h.Shell, "{{") {
+		return fmt.Errorf("template actions are not supported in shell hooks: use the NEON_* variables or the argv form")
+	}
+	for _, text := range h.Command {
+		if _, err := ParseHookTemplate(text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Argv expande las plantillas del hook con data y retorna el comando a ejecutar. Los
+// hooks shell se ejecutan tal cual con /bin/sh -c: el contenido de .Reason o de los
+// mensajes (salida de checks y acciones) nunca forma parte del script
+func (h Hook) Argv(data interface{}) ([]string, error) {
+	if h.Shell != "" {
+		return []string{"/bin/sh", "-c", h.Shell}, nil
+	}
+
+	argv := make([]string, 0, len(h.Command))
+	for _, arg := range h.Command {
+		expanded, err := ExpandHookTemplate(arg, data)
+		if err != nil {
+			return nil, err
+		}
+		argv = append(argv, expanded)
+	}
+	if len(argv) == 0 || argv[0] == "" {
+		return nil, fmt.Errorf("empty hook command")
+	}
+	return argv, nil
+}
+
+// ExpandHookTemplate aplica una plantilla de hook con los datos indicados
+func ExpandHookTemplate(text string, data interface{}) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := ParseHookTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// ParseHookTemplate compila una plantilla de hook. Además de las funciones estándar
+// incluye quote, que escapa un valor para usarlo como argumento de /bin/sh, y json,
+// que lo codifica como valor JSON
+func ParseHookTemplate(text string) (*template.Template, error) {
+	funcs := template.FuncMap{"quote": shellQuote, "json": jsonValue}
+	tmpl, err := template.New("hook").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// shellQuote encierra el valor en comillas simples para sh
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// jsonValue codifica el valor como JSON (strings entre comillas y escapados)
+func jsonValue(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
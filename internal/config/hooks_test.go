@@ -0,0 +1,50 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShellHookRejectsTemplates(t *testing.T) {
+	for _, script := range []string{
+		`echo {{.Reason}}`,
+		`echo '{{.Reason}}'`,
+		`echo "$(echo "{{.Reason}}")"`,
+		"echo \"`echo {{.Reason}}`\"",
+		`{{if .Result}}echo done{{end}}`,
+	} {
+		err := Hook{Shell: script}.validate()
+		if err == nil || !strings.Contains(err.Error(), "not supported in shell hooks") {
+			t.Errorf("%s: expected template to be rejected, got %v", script, err)
+		}
+	}
+
+	if err := (Hook{Shell: `logger -t neon "$NEON_REASON" | tee -a /tmp/x`}).validate(); err != nil {
+		t.Fatalf("plain shell hook rejected: %v", err)
+	}
+}
+
+func TestHookArgv(t *testing.T) {
+	data := struct{ Target, Reason string }{"api", `$(id -u); rm -rf / "x"`}
+
+	argv, err := Hook{Shell: `echo "$NEON_REASON"`}.Argv(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(argv) != 3 || argv[0] != "/bin/sh" || argv[1] != "-c" || argv[2] != `echo "$NEON_REASON"` {
+		t.Fatalf("shell hook must run the script unchanged, got %q", argv)
+	}
+
+	argv, err = Hook{Command: []string{"/usr/local/bin/notify", "--target", "{{.Target}}", "--reason={{.Reason}}"}}.Argv(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/usr/local/bin/notify", "--target", "api", `--reason=$(id -u); rm -rf / "x"`}
+	if strings.Join(argv, "\x00") != strings.Join(want, "\x00") {
+		t.Fatalf("unexpected argv %q", argv)
+	}
+
+	if _, err := (Hook{Command: []string{"{{.Target}}"}}).Argv(struct{ Target string }{}); err == nil {
+		t.Fatal("expected an error for an empty command")
+	}
+}
@@ -0,0 +1,262 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaintenanceConfig ventanas de mantenimiento y persistencia de los silencios creados por API/CLI
+type MaintenanceConfig struct {
+	SilencesFile string              `yaml:"silences_file,omitempty" json:"silences_file,omitempty"` // default: silences.json junto al state_file
+	Windows      []MaintenanceWindow `yaml:"windows,omitempty" json:"windows,omitempty"`
+}
+
+// MaintenanceWindow ventana puntual (start/end) o recurrente (schedule cron + duration_minutes).
+// Aplica a los targets listados por nombre ("*" para todos) o que tengan todas las labels
+type MaintenanceWindow struct {
+	Name    string            `yaml:"name" json:"name"`
+	Targets []string          `yaml:"targets,omitempty" json:"targets,omitempty"`
+	Labels  map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+
+	Start string `yaml:"start,omitempty" json:"start,omitempty"` // RFC3339
+	End   string `yaml:"end,omitempty" json:"end,omitempty"`     // RFC3339
+
+	Schedule        string `yaml:"schedule,omitempty" json:"schedule,omitempty"` // cron: minuto hora día mes día_semana
+	DurationMinutes int    `yaml:"duration_minutes,omitempty" json:"duration_minutes,omitempty"`
+	Timezone        string `yaml:"timezone,omitempty" json:"timezone,omitempty"` // default: hora local
+
+	// Suppress qué se suprime durante la ventana: actions, notifications (default: ambos)
+	Suppress []string `yaml:"suppress,omitempty" json:"suppress,omitempty"`
+}
+
+// Qué puede suprimir una ventana de mantenimiento o un silencio
+const (
+	SuppressActions       = "actions"
+	SuppressNotifications = "notifications"
+)
+
+// maxWindowMinutes duración máxima de una ventana recurrente (7 días)
+const maxWindowMinutes = 7 * 24 * 60
+
+// ValidateSuppress valida la lista suppress (vacía = actions y notifications)
+func ValidateSuppress(suppress []string) error {
+	for _, s := range suppress {
+		if s != SuppressActions && s != SuppressNotifications {
+			return fmt.Errorf("invalid suppress '%s' (must be: actions, notifications)", s)
+		}
+	}
+	return nil
+}
+
+// Suppresses indica si la lista suppress incluye what (una lista vacía lo suprime todo)
+func Suppresses(suppress []string, what string) bool {
+	return len(suppress) == 0 || slices.Contains(suppress, what)
+}
+
+// ScopeMatches indica si el target está en el ámbito: listado por nombre ("*" = todos)
+// o con todas las labels indicadas
+func ScopeMatches(targets []string, labels map[string]string, target Target) bool {
+	if slices.Contains(targets, "*") || slices.Contains(targets, target.Name) {
+		return true
+	}
+	if len(labels) == 0 {
+		return false
+	}
+	for k, v := range labels {
+		if target.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Location retorna la zona horaria de la ventana
+func (w *MaintenanceWindow) Location() (*time.Location, error) {
+	if w.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(w.Timezone)
+}
+
+// validateMaintenance valida las ventanas de mantenimiento y que los targets existan
+func validateMaintenance(m *MaintenanceConfig, targets []Target) error {
+	names := make(map[string]bool)
+	for i, w := range m.Windows {
+		if w.Name == "" {
+			return fmt.Errorf("windows[%d]: name is required", i)
+		}
+		if names[w.Name] {
+			return fmt.Errorf("windows[%d]: duplicate name '%s'", i, w.Name)
+		}
+		names[w.Name] = true
+
+		if err := validateWindow(w); err != nil {
+			return fmt.Errorf("windows[%s]: %w", w.Name, err)
+		}
+		for _, name := range w.Targets {
+			if name != "*" && !slices.ContainsFunc(targets, func(t Target) bool { return t.Name == name }) {
+				return fmt.Errorf("windows[%s]: unknown target '%s'", w.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+func validateWindow(w MaintenanceWindow) error {
+	if len(w.Targets) == 0 && len(w.Labels) == 0 {
+		return fmt.Errorf("targets or labels is required (use targets: [\"*\"] for all)")
+	}
+	if err := ValidateSuppress(w.Suppress); err != nil {
+		return err
+	}
+	loc, err := w.Location()
+	if err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	oneOff := w.Start != "" || w.End != ""
+	if oneOff == (w.Schedule != "") {
+		return fmt.Errorf("exactly one of start/end or schedule is required")
+	}
+
+	if oneOff {
+		start, err := time.ParseInLocation(time.RFC3339, w.Start, loc)
+		if err != nil {
+			return fmt.Errorf("invalid start: %w", err)
+		}
+		end, err := time.ParseInLocation(time.RFC3339, w.End, loc)
+		if err != nil {
+			return fmt.Errorf("invalid end: %w", err)
+		}
+		if !end.After(start) {
+			return fmt.Errorf("end must be after start")
+		}
+		if w.DurationMinutes != 0 {
+			return fmt.Errorf("duration_minutes is only valid with schedule")
+		}
+		return nil
+	}
+
+	if _, err := ParseSchedule(w.Schedule); err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+	if w.DurationMinutes <= 0 || w.DurationMinutes > maxWindowMinutes {
+		return fmt.Errorf("duration_minutes must be between 1 and %d", maxWindowMinutes)
+	}
+	return nil
+}
+
+// Schedule expresión cron de cinco campos: minuto hora día mes día_semana
+type Schedule struct {
+	minute, hour, dom, month, dow uint64 // bit i = valor i permitido
+	domAny, dowAny                bool
+}
+
+var (
+	monthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	dowNames   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// ParseSchedule interpreta una expresión cron estándar: *, listas (1,15), rangos (1-5),
+// pasos (*/10, 8-18/2) y nombres de mes y día de la semana (jan, mon). 0 y 7 son domingo
+func ParseSchedule(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields (minute hour day month weekday), got %d", len(fields))
+	}
+
+	s := &Schedule{}
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("day: %w", err)
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7, dowNames); err != nil {
+		return nil, fmt.Errorf("weekday: %w", err)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny = strings.HasPrefix(fields[2], "*")
+	s.dowAny = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// Matches indica si el minuto de t cumple la expresión. Como en cron, si se restringen
+// el día del mes y el de la semana basta con que se cumpla uno de los dos
+func (s *Schedule) Matches(t time.Time) bool {
+	if s.minute&(1<<uint(t.Minute())) == 0 || s.hour&(1<<uint(t.Hour())) == 0 ||
+		s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domOK := s.dom&(1<<uint(t.Day())) != 0
+	dowOK := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.domAny || s.dowAny:
+		return domOK && dowOK
+	default:
+		return domOK || dowOK
+	}
+}
+
+func parseCronField(field string, min, max int, names []string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step '%s'", stepStr)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = cronValue(loStr, min, max, names); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = cronValue(hiStr, min, max, names); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = max
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("invalid range '%s'", rng)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(s string, min, max int, names []string) (int, error) {
+	if i := slices.Index(names, strings.ToLower(s)); i >= 0 {
+		return i + min, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("invalid value '%s' (must be %d-%d)", s, min, max)
+	}
+	return v, nil
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CheckValidator valida la configuración de un tipo de check
+type CheckValidator func(check Check, cfg *Config) error
+
+// ActionValidator valida la configuración de un tipo de acción
+type ActionValidator func(action Action, cfg *Config) error
+
+// PluginValidator valida que el plugin name de dir soporta kind ("check" o "action") y que
+// cfg cumple su esquema
+type PluginValidator func(dir, name, kind string, cfg map[string]interface{}) error
+
+// typeRegistry tipos conocidos en orden de registro (para los mensajes de error)
+var (
+	registryMu       sync.RWMutex
+	checkValidators  = make(map[string]CheckValidator)
+	checkTypeOrder   []string
+	actionValidators = make(map[string]ActionValidator)
+	actionTypeOrder  []string
+	pluginValidator  PluginValidator
+)
+
+func init() {
+	for _, name := range []string{
+		"process_name", "pid_file", "tcp_port", "command", "http", "script", "logic",
+		"heartbeat", "log_pattern", "kernel_events", "host", "listening", "nagios_plugin", "plugin", "grpc",
+		"postgres", "mysql", "redis", "container",
+	} {
+		RegisterCheckType(name, validateBuiltinCheck)
+	}
+	for _, name := range []string{"exec", "systemd", "plugin", "container", "http"} {
+		RegisterActionType(name, validateBuiltinAction)
+	}
+}
+
+// RegisterCheckType da de alta un tipo de check; validate puede ser nil. Falla si el nombre ya existe
+func RegisterCheckType(name string, validate CheckValidator) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := checkValidators[name]; exists {
+		panic(fmt.Sprintf("config: check type %q already registered", name))
+	}
+	checkValidators[name] = validate
+	checkTypeOrder = append(checkTypeOrder, name)
+}
+
+// RegisterActionType da de alta un tipo de acción; validate puede ser nil. Falla si el nombre ya existe
+func RegisterActionType(name string, validate ActionValidator) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := actionValidators[name]; exists {
+		panic(fmt.Sprintf("config: action type %q already registered", name))
+	}
+	actionValidators[name] = validate
+	actionTypeOrder = append(actionTypeOrder, name)
+}
+
+// SetPluginValidator instala la validación de plugins. La registra el paquete plugins, que
+// ejecuta los binarios con internal/process y por eso no puede importarse desde config
+func SetPluginValidator(validate PluginValidator) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	pluginValidator = validate
+}
+
+// validatePlugin valida un plugin si hay validador instalado
+func validatePlugin(dir, name, kind string, cfg map[string]interface{}) error {
+	registryMu.RLock()
+	validate := pluginValidator
+	registryMu.RUnlock()
+	if validate == nil {
+		return nil
+	}
+	return validate(dir, name, kind, cfg)
+}
+
+// CheckTypes retorna los tipos de check registrados
+func CheckTypes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]string(nil), checkTypeOrder...)
+}
+
+// ActionTypes retorna los tipos de acción registrados
+func ActionTypes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]string(nil), actionTypeOrder...)
+}
+
+func lookupCheckType(name string) (CheckValidator, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	validate, ok := checkValidators[name]
+	return validate, ok
+}
+
+func lookupActionType(name string) (ActionValidator, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	validate, ok := actionValidators[name]
+	return validate, ok
+}
+
+// DecodeOptions decodifica el bloque 'options' de un tipo registrado en su propia estructura
+// (campos con tags yaml). Las claves desconocidas se rechazan
+func DecodeOptions(options map[string]interface{}, out interface{}) error {
+	data, err := yaml.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("error encoding options: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(out); err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package containers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultSockets sockets del Engine API que se prueban en orden si no se configura uno
+var DefaultSockets = []string{
+	"/var/run/docker.sock",
+	"/run/podman/podman.sock",
+}
+
+// Container resumen de un contenedor (GET /containers/json)
+type Container struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Labels map[string]string `json:"Labels"`
+	State  string            `json:"State"`
+}
+
+// Name nombre del contenedor sin la barra inicial
+func (c Container) Name() string {
+	if len(c.Names) == 0 {
+		return c.ID
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// Health estado del HEALTHCHECK
+type Health struct {
+	Status        string `json:"Status"` // starting, healthy, unhealthy
+	FailingStreak int    `json:"FailingStreak"`
+	Log           []struct {
+		ExitCode int    `json:"ExitCode"`
+		Output   string `json:"Output"`
+	} `json:"Log"`
+}
+
+// State estado de un contenedor (GET /containers/{id}/json)
+type State struct {
+	Status     string  `json:"Status"` // created, running, paused, restarting, removing, exited, dead
+	Running    bool    `json:"Running"`
+	Restarting bool    `json:"Restarting"`
+	OOMKilled  bool    `json:"OOMKilled"`
+	ExitCode   int     `json:"ExitCode"`
+	Error      string  `json:"Error"`
+	Health     *Health `json:"Health"`
+}
+
+// Inspect datos del contenedor necesarios para evaluarlo y recrearlo
+type Inspect struct {
+	ID           string `json:"Id"`
+	Name         string `json:"Name"`
+	RestartCount int    `json:"RestartCount"`
+	State        State  `json:"State"`
+
+	Config          json.RawMessage `json:"Config"`
+	HostConfig      json.RawMessage `json:"HostConfig"`
+	NetworkSettings struct {
+		Networks map[string]json.RawMessage `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// Client cliente mínimo del Engine API de Docker/Podman sobre socket Unix
+type Client struct {
+	socket string
+	http   *http.Client
+}
+
+// NewClient crea un cliente para el socket indicado o el primero de DefaultSockets que exista
+func NewClient(socket string) (*Client, error) {
+	if socket == "" {
+		for _, candidate := range DefaultSockets {
+			if _, err := os.Stat(candidate); err == nil {
+				socket = candidate
+				break
+			}
+		}
+		if socket == "" {
+			return nil, fmt.Errorf("no container engine socket found (tried %s)", strings.Join(DefaultSockets, ", "))
+		}
+	}
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+
+	return &Client{
+		socket: socket,
+		http:   &http.Client{Transport: transport},
+	}, nil
+}
+
+// Socket ruta del socket en uso
+func (c *Client) Socket() string {
+	return c.socket
+}
+
+// Close libera las conexiones abiertas
+func (c *Client) Close() {
+	c.http.CloseIdleConnections()
+}
+
+// do realiza una petición al API y decodifica la respuesta en out (si no es nil)
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	u := url.URL{Scheme: "http", Host: "engine", Path: path, RawQuery: query.Encode()}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("engine API %s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	// 304: el contenedor ya estaba en el estado pedido
+	if resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return fmt.Errorf("engine API %s %s returned %d: %s", method, path, resp.StatusCode, apiErr.Message)
+	}
+
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid engine API response: %w", err)
+	}
+	return nil
+}
+
+// Find busca contenedores (incluidos los parados) por nombre exacto y/o labels
+func (c *Client) Find(ctx context.Context, name string, labels map[string]string) ([]Container, error) {
+	filters := map[string][]string{}
+	if name != "" {
+		filters["name"] = []string{"^/?" + regexp.QuoteMeta(name) + "$"}
+	}
+	if len(labels) > 0 {
+		keys := make([]string, 0, len(labels))
+		for k := range labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			filters["label"] = append(filters["label"], k+"="+labels[k])
+		}
+	}
+	encoded, err := json.Marshal(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Container
+	query := url.Values{"all": {"1"}, "filters": {string(encoded)}}
+	if err := c.do(ctx, http.MethodGet, "/containers/json", query, nil, &list); err != nil {
+		return nil, err
+	}
+
+	// El filtro "name" admite coincidencias parciales en algunos motores: se confirma aquí
+	matched := []Container{}
+	for _, ct := range list {
+		if name != "" && ct.Name() != name {
+			continue
+		}
+		ok := true
+		for k, v := range labels {
+			if ct.Labels[k] != v {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, ct)
+		}
+	}
+	return matched, nil
+}
+
+// Inspect obtiene el estado completo de un contenedor
+func (c *Client) Inspect(ctx context.Context, id string) (*Inspect, error) {
+	var info Inspect
+	if err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(id)+"/json", nil, nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Start arranca un contenedor parado
+func (c *Client) Start(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/start", nil, nil, nil)
+}
+
+// Stop para un contenedor esperando stopTimeout segundos antes de matarlo
+func (c *Client) Stop(ctx context.Context, id string, stopTimeout int) error {
+	query := url.Values{"t": {strconv.Itoa(stopTimeout)}}
+	return c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/stop", query, nil, nil)
+}
+
+// Restart reinicia un contenedor esperando stopTimeout segundos antes de matarlo
+func (c *Client) Restart(ctx context.Context, id string, stopTimeout int) error {
+	query := url.Values{"t": {strconv.Itoa(stopTimeout)}}
+	return c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/restart", query, nil, nil)
+}
+
+// Recreate para y elimina el contenedor y crea uno nuevo con la misma configuración, imagen,
+// HostConfig y redes; retorna el ID del nuevo contenedor ya arrancado
+func (c *Client) Recreate(ctx context.Context, id string, stopTimeout int) (string, error) {
+	info, err := c.Inspect(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	body := map[string]interface{}{}
+	if err := json.Unmarshal(info.Config, &body); err != nil {
+		return "", fmt.Errorf("cannot decode container config: %w", err)
+	}
+	body["HostConfig"] = info.HostConfig
+
+	endpoints := map[string]interface{}{}
+	for network, raw := range info.NetworkSettings.Networks {
+		// Solo la configuración del usuario; IPs y MACs asignadas son del contenedor anterior
+		var settings struct {
+			IPAMConfig json.RawMessage `json:"IPAMConfig,omitempty"`
+			Links      []string        `json:"Links,omitempty"`
+			Aliases    []string        `json:"Aliases,omitempty"`
+			DriverOpts json.RawMessage `json:"DriverOpts,omitempty"`
+		}
+		if err := json.Unmarshal(raw, &settings); err != nil {
+			return "", fmt.Errorf("cannot decode network %s: %w", network, err)
+		}
+		if string(settings.IPAMConfig) == "null" {
+			settings.IPAMConfig = nil
+		}
+		if string(settings.DriverOpts) == "null" {
+			settings.DriverOpts = nil
+		}
+		endpoints[network] = settings
+	}
+	body["NetworkingConfig"] = map[string]interface{}{"EndpointsConfig": endpoints}
+
+	name := strings.TrimPrefix(info.Name, "/")
+
+	if info.State.Running {
+		if err := c.Stop(ctx, id, stopTimeout); err != nil {
+			return "", err
+		}
+	}
+	if err := c.do(ctx, http.MethodDelete, "/containers/"+url.PathEscape(id), url.Values{"force": {"1"}}, nil, nil); err != nil {
+		return "", err
+	}
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/containers/create", url.Values{"name": {name}}, body, &created); err != nil {
+		return "", fmt.Errorf("container %s removed but could not be recreated: %w", name, err)
+	}
+	if err := c.Start(ctx, created.ID); err != nil {
+		return created.ID, err
+	}
+	return created.ID, nil
+}
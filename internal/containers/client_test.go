@@ -0,0 +1,154 @@
+package containers_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tgextreme/neon-watchdog/internal/containers"
+	"github.com/tgextreme/neon-watchdog/internal/containers/containerstest"
+)
+
+func newClient(t *testing.T, srv *containerstest.Server) *containers.Client {
+	t.Helper()
+	client, err := containers.NewClient(srv.Socket)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestFind(t *testing.T) {
+	srv := containerstest.NewServer(t,
+		&containerstest.Container{ID: "aaa", Name: "web", Labels: map[string]string{"app": "shop", "tier": "front"}},
+		&containerstest.Container{ID: "bbb", Name: "web-2", Labels: map[string]string{"app": "shop", "tier": "front"}},
+		&containerstest.Container{ID: "ccc", Name: "db", Labels: map[string]string{"app": "shop", "tier": "back"}},
+	)
+	client := newClient(t, srv)
+	ctx := context.Background()
+
+	found, err := client.Find(ctx, "web", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].ID != "aaa" || found[0].Name() != "web" {
+		t.Fatalf("expected only container web, got %+v", found)
+	}
+
+	found, err = client.Find(ctx, "", map[string]string{"app": "shop", "tier": "front"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("expected 2 containers for the labels, got %+v", found)
+	}
+
+	found, err = client.Find(ctx, "missing", nil)
+	if err != nil || len(found) != 0 {
+		t.Fatalf("expected no containers, got %+v (err %v)", found, err)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	ct := &containerstest.Container{ID: "aaa", Name: "web", RestartCount: 7}
+	ct.State.Status = "running"
+	ct.State.Running = true
+	ct.State.Health = &containers.Health{Status: "unhealthy", FailingStreak: 3}
+	srv := containerstest.NewServer(t, ct)
+	client := newClient(t, srv)
+
+	info, err := client.Inspect(context.Background(), "aaa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "/web" || info.RestartCount != 7 || !info.State.Running {
+		t.Fatalf("unexpected inspect data: %+v", info)
+	}
+	if info.State.Health == nil || info.State.Health.Status != "unhealthy" || info.State.Health.FailingStreak != 3 {
+		t.Fatalf("unexpected health: %+v", info.State.Health)
+	}
+
+	_, err = client.Inspect(context.Background(), "zzz")
+	if err == nil || !strings.Contains(err.Error(), "returned 404: No such container: zzz") {
+		t.Fatalf("expected engine error message, got %v", err)
+	}
+}
+
+func TestStartStopRestart(t *testing.T) {
+	ct := &containerstest.Container{ID: "aaa", Name: "web"}
+	ct.State.Status = "exited"
+	srv := containerstest.NewServer(t, ct)
+	client := newClient(t, srv)
+	ctx := context.Background()
+
+	if err := client.Start(ctx, "aaa"); err != nil {
+		t.Fatal(err)
+	}
+	// 304: ya estaba arrancado
+	if err := client.Start(ctx, "aaa"); err != nil {
+		t.Fatalf("starting a running container should not fail: %v", err)
+	}
+	if err := client.Restart(ctx, "aaa", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Stop(ctx, "aaa", 5); err != nil {
+		t.Fatal(err)
+	}
+	if srv.Container("aaa").State.Running {
+		t.Fatal("container should be stopped")
+	}
+
+	want := []string{
+		"POST /containers/aaa/start",
+		"POST /containers/aaa/start",
+		"POST /containers/aaa/restart?t=3",
+		"POST /containers/aaa/stop?t=5",
+	}
+	if got := srv.Calls(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected calls:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestRecreate(t *testing.T) {
+	ct := &containerstest.Container{ID: "aaa", Name: "web", Config: map[string]interface{}{"Image": "nginx:1.27", "Env": []string{"A=1"}}}
+	ct.State.Status = "running"
+	ct.State.Running = true
+	srv := containerstest.NewServer(t, ct)
+	client := newClient(t, srv)
+
+	id, err := client.Recreate(context.Background(), "aaa", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srv.Container("aaa") != nil {
+		t.Fatal("old container should have been removed")
+	}
+	recreated := srv.Container(id)
+	if recreated == nil || recreated.Name != "web" || !recreated.State.Running {
+		t.Fatalf("recreated container missing or not running: %+v", recreated)
+	}
+
+	body := srv.Created()
+	if body["Image"] != "nginx:1.27" || body["HostConfig"] == nil {
+		t.Fatalf("create request lost the container config: %+v", body)
+	}
+	endpoints := body["NetworkingConfig"].(map[string]interface{})["EndpointsConfig"].(map[string]interface{})
+	bridge, ok := endpoints["bridge"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("create request lost the networks: %+v", endpoints)
+	}
+	if _, ok := bridge["IPAddress"]; ok {
+		t.Fatalf("assigned IP address must not be copied to the new container: %+v", bridge)
+	}
+
+	want := []string{
+		"POST /containers/aaa/stop?t=10",
+		"DELETE /containers/aaa?force=1",
+		"POST /containers/create?name=web",
+		"POST /containers/" + id + "/start",
+	}
+	if got := srv.Calls(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected calls:\n%s", strings.Join(got, "\n"))
+	}
+}
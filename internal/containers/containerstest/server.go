@@ -0,0 +1,279 @@
+// Package containerstest implementa un Engine API falso sobre socket Unix para probar el
+// cliente de contenedores, el check container y la acción container sin Docker ni Podman.
+package containerstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tgextreme/neon-watchdog/internal/containers"
+)
+
+// Container contenedor simulado por el servidor
+type Container struct {
+	ID           string
+	Name         string
+	Labels       map[string]string
+	State        containers.State
+	RestartCount int
+	Config       map[string]interface{} // Config del inspect; default: {"Image": "busybox"}
+	StartError   string                 // si no está vacío, start y restart responden 500 con este mensaje
+}
+
+// Server Engine API falso. Calls registra "MÉTODO ruta" de cada petición de acción
+type Server struct {
+	Socket string
+
+	mu         sync.Mutex
+	containers map[string]*Container
+	calls      []string
+	created    map[string]interface{}
+	nextID     int
+}
+
+// NewServer arranca el servidor en un socket temporal con los contenedores indicados
+func NewServer(t testing.TB, list ...*Container) *Server {
+	t.Helper()
+
+	// Los sockets Unix admiten rutas cortas: t.TempDir() puede superar el límite
+	dir, err := os.MkdirTemp("", "engine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	s := &Server{
+		Socket:     filepath.Join(dir, "engine.sock"),
+		containers: make(map[string]*Container),
+	}
+	for _, ct := range list {
+		s.containers[ct.ID] = ct
+	}
+
+	ln, err := net.Listen("unix", s.Socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /containers/json", s.list)
+	mux.HandleFunc("GET /containers/{id}/json", s.inspect)
+	mux.HandleFunc("POST /containers/{id}/start", s.start)
+	mux.HandleFunc("POST /containers/{id}/stop", s.stop)
+	mux.HandleFunc("POST /containers/{id}/restart", s.restart)
+	mux.HandleFunc("DELETE /containers/{id}", s.remove)
+	mux.HandleFunc("POST /containers/create", s.create)
+
+	srv := httptest.NewUnstartedServer(mux)
+	srv.Listener.Close()
+	srv.Listener = ln
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return s
+}
+
+// Calls peticiones de acción recibidas, en orden
+func (s *Server) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+// Container estado actual de un contenedor (nil si no existe)
+func (s *Server) Container(id string) *Container {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.containers[id]
+}
+
+// Created cuerpo de la última petición /containers/create
+func (s *Server) Created() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.created
+}
+
+func (s *Server) record(r *http.Request) {
+	call := r.Method + " " + r.URL.Path
+	if r.URL.RawQuery != "" {
+		call += "?" + r.URL.RawQuery
+	}
+	s.calls = append(s.calls, call)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func notFound(w http.ResponseWriter, id string) {
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "No such container: " + id})
+}
+
+// list aplica los filtros name (regexp) y label (k=v) como el motor real
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	var filters map[string][]string
+	if raw := r.URL.Query().Get("filters"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &filters); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := []containers.Container{}
+	for _, ct := range s.containers {
+		if !matches(ct, filters) {
+			continue
+		}
+		out = append(out, containers.Container{
+			ID:     ct.ID,
+			Names:  []string{"/" + ct.Name},
+			Labels: ct.Labels,
+			State:  ct.State.Status,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func matches(ct *Container, filters map[string][]string) bool {
+	for _, pattern := range filters["name"] {
+		re, err := regexp.Compile(pattern)
+		if err != nil || !re.MatchString("/"+ct.Name) {
+			return false
+		}
+	}
+	for _, label := range filters["label"] {
+		key, value, _ := strings.Cut(label, "=")
+		if ct.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) inspect(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ct, ok := s.containers[r.PathValue("id")]
+	if !ok {
+		notFound(w, r.PathValue("id"))
+		return
+	}
+	config := ct.Config
+	if config == nil {
+		config = map[string]interface{}{"Image": "busybox"}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"Id":           ct.ID,
+		"Name":         "/" + ct.Name,
+		"RestartCount": ct.RestartCount,
+		"State":        ct.State,
+		"Config":       config,
+		"HostConfig":   map[string]interface{}{"RestartPolicy": map[string]string{"Name": "no"}},
+		"NetworkSettings": map[string]interface{}{
+			"Networks": map[string]interface{}{
+				"bridge": map[string]interface{}{"Aliases": nil, "IPAddress": "172.17.0.2"},
+			},
+		},
+	})
+}
+
+// transition aplica un cambio de estado; 304 si el contenedor ya estaba en él
+func (s *Server) transition(w http.ResponseWriter, r *http.Request, running bool, force bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record(r)
+
+	ct, ok := s.containers[r.PathValue("id")]
+	if !ok {
+		notFound(w, r.PathValue("id"))
+		return
+	}
+	if running && ct.StartError != "" {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"message": ct.StartError})
+		return
+	}
+	if ct.State.Running == running && !force {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	ct.State.Running = running
+	ct.State.Restarting = false
+	ct.State.Status = "exited"
+	if running {
+		ct.State.Status = "running"
+		ct.State.ExitCode = 0
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) start(w http.ResponseWriter, r *http.Request) {
+	s.transition(w, r, true, false)
+}
+
+func (s *Server) stop(w http.ResponseWriter, r *http.Request) {
+	s.transition(w, r, false, false)
+}
+
+func (s *Server) restart(w http.ResponseWriter, r *http.Request) {
+	s.transition(w, r, true, true)
+}
+
+func (s *Server) remove(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record(r)
+
+	id := r.PathValue("id")
+	if _, ok := s.containers[id]; !ok {
+		notFound(w, id)
+		return
+	}
+	delete(s.containers, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record(r)
+
+	name := r.URL.Query().Get("name")
+	for _, ct := range s.containers {
+		if ct.Name == name {
+			writeJSON(w, http.StatusConflict, map[string]string{"message": fmt.Sprintf("name %s is already in use", name)})
+			return
+		}
+	}
+
+	s.nextID++
+	id := fmt.Sprintf("recreated%055d", s.nextID)
+	s.created = body
+	s.containers[id] = &Container{
+		ID:    id,
+		Name:  name,
+		State: containers.State{Status: "created"},
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"Id": id})
+}
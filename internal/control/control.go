@@ -0,0 +1,51 @@
+// Package control define las operaciones de runtime sobre los targets (pausar, reanudar,
+// ejecutar los checks o la recuperación ahora). Las implementa el engine y las exponen la
+// API del dashboard y el CLI sin depender del engine.
+package control
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/actions"
+)
+
+// Scope qué se pausa de un target
+type Scope string
+
+const (
+	ScopeChecks   Scope = "checks"   // no se ejecutan los checks (ni la recuperación)
+	ScopeRecovery Scope = "recovery" // los checks siguen, la recuperación no
+)
+
+// Errores que la API traduce a códigos HTTP
+var (
+	ErrUnknownTarget = errors.New("unknown target")
+	ErrInvalidScope  = errors.New("invalid scope (must be: checks, recovery)")
+	ErrNotPaused     = errors.New("target is not paused")
+	ErrBlocked       = errors.New("recovery blocked")
+)
+
+// Pause pausa activa de un target: quién, por qué y desde cuándo
+type Pause struct {
+	Scope  Scope     `json:"scope"`
+	By     string    `json:"by"`
+	Reason string    `json:"reason,omitempty"`
+	Since  time.Time `json:"since"`
+}
+
+// Controller operaciones de runtime sobre los targets. by y reason quedan en el log y el historial
+type Controller interface {
+	// Pause pausa los checks o solo la recuperación del target
+	Pause(target string, scope Scope, by, reason string) (Pause, error)
+	// Resume quita la pausa del target
+	Resume(target, by, reason string) error
+	// Paused retorna las pausas activas por target
+	Paused() map[string]Pause
+	// CheckNow ejecuta ahora una pasada de checks del target (aunque esté pausado)
+	CheckNow(ctx context.Context, target, by, reason string) (bool, error)
+	// RecoverNow ejecuta ahora la acción de recuperación. Sin force respeta el cooldown,
+	// el límite por hora y la presión del host
+	RecoverNow(ctx context.Context, target string, force bool, by, reason string) (actions.Result, error)
+}
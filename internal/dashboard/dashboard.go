@@ -2,18 +2,29 @@ package dashboard
 
 import (
 	"bufio"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/tgextreme/neon-watchdog/internal/actions"
+	"github.com/tgextreme/neon-watchdog/internal/approval"
+	"github.com/tgextreme/neon-watchdog/internal/checks"
 	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/control"
+	"github.com/tgextreme/neon-watchdog/internal/heartbeat"
 	"github.com/tgextreme/neon-watchdog/internal/logger"
+	"github.com/tgextreme/neon-watchdog/internal/maintenance"
 	"golang.org/x/crypto/bcrypt"
 	"gopkg.in/yaml.v3"
 )
@@ -26,6 +37,10 @@ type Dashboard struct {
 	status     *Status
 	configPath string
 	fullConfig *config.Config
+	approvals  *approval.Queue
+	maint      *maintenance.Manager
+	control    control.Controller
+	server     *http.Server
 }
 
 // Status representa el estado actual del watchdog
@@ -45,6 +60,8 @@ type TargetStatus struct {
 	TotalRestarts       int       `json:"total_restarts"`
 	LastRestart         time.Time `json:"last_restart,omitempty"`
 	Message             string    `json:"message"`
+
+	Checks map[string]checks.Result `json:"checks,omitempty"` // último resultado por check
 }
 
 // NewDashboard crea un nuevo dashboard
@@ -175,9 +192,10 @@ func (d *Dashboard) requestAuth(w http.ResponseWriter) {
 	w.Write([]byte("401 - Autenticación Requerida\n\nUsuarios disponibles en users.txt"))
 }
 
-// Start inicia el servidor del dashboard
+// Start abre el puerto del dashboard y lo atiende en segundo plano. El puerto se abre antes
+// de retornar: un error (puerto ocupado) se devuelve en lugar de solo registrarse
 func (d *Dashboard) Start() error {
-	if !d.cfg.Enabled {
+	if !d.cfg.Enabled || d.server != nil {
 		return nil
 	}
 
@@ -185,6 +203,12 @@ func (d *Dashboard) Start() error {
 		d.cfg.Port = 8080
 	}
 
+	addr := fmt.Sprintf(":%d", d.cfg.Port)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("dashboard cannot listen on %s: %w", addr, err)
+	}
+
 	// Proteger todas las rutas con autenticación
 	http.HandleFunc(d.cfg.Path, d.authMiddleware(d.handleUI))
 	http.HandleFunc("/api/status", d.authMiddleware(d.handleAPIStatus))
@@ -192,15 +216,24 @@ func (d *Dashboard) Start() error {
 	http.HandleFunc("/api/targets", d.authMiddleware(d.handleAPITargets))
 	http.HandleFunc("/api/targets/", d.authMiddleware(d.handleAPITargetByName))
 	http.HandleFunc("/api/config", d.authMiddleware(d.handleAPIConfig))
-
-	addr := fmt.Sprintf(":%d", d.cfg.Port)
-	d.log.Info("dashboard starting", logger.Fields(
+	http.HandleFunc("/api/approvals", d.authMiddleware(d.handleAPIApprovals))
+	http.HandleFunc("/api/approvals/", d.authMiddleware(d.handleAPIApprovalByID))
+	http.HandleFunc("/api/paused", d.authMiddleware(d.handleAPIPaused))
+	http.HandleFunc("/api/maintenance", d.authMiddleware(d.handleAPIMaintenance))
+	http.HandleFunc("/api/silences", d.authMiddleware(d.handleAPISilences))
+	http.HandleFunc("/api/silences/", d.authMiddleware(d.handleAPISilenceByID))
+
+	// Los pings de heartbeat se autentican con el propio token (cron jobs sin credenciales)
+	http.HandleFunc("/api/heartbeat/", d.handleAPIHeartbeat)
+
+	d.server = &http.Server{}
+	d.log.Info("dashboard listening", logger.Fields(
 		"port", d.cfg.Port,
 		"path", d.cfg.Path,
 	))
 
 	go func() {
-		if err := http.ListenAndServe(addr, nil); err != nil {
+		if err := d.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			d.log.Error("dashboard server failed", logger.Fields("error", err.Error()))
 		}
 	}()
@@ -208,6 +241,35 @@ func (d *Dashboard) Start() error {
 	return nil
 }
 
+// Close cierra el puerto del dashboard
+func (d *Dashboard) Close() error {
+	if d.server == nil {
+		return nil
+	}
+	return d.server.Close()
+}
+
+// SetApprovals configura la cola de acciones pendientes de aprobación
+func (d *Dashboard) SetApprovals(q *approval.Queue) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.approvals = q
+}
+
+// SetMaintenance configura las ventanas de mantenimiento y los silencios
+func (d *Dashboard) SetMaintenance(m *maintenance.Manager) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.maint = m
+}
+
+// SetController configura las operaciones de runtime sobre los targets (pausa, check, recuperación)
+func (d *Dashboard) SetController(c control.Controller) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.control = c
+}
+
 // UpdateTarget actualiza el estado de un target
 func (d *Dashboard) UpdateTarget(name string, healthy bool, enabled bool, consecutiveFailures int, message string) {
 	if !d.cfg.Enabled {
@@ -229,6 +291,24 @@ func (d *Dashboard) UpdateTarget(name string, healthy bool, enabled bool, consec
 	d.status.Uptime = time.Since(d.status.StartTime)
 }
 
+// RecordCheck guarda el último resultado de un check del target
+func (d *Dashboard) RecordCheck(target, checkName string, result checks.Result) {
+	if !d.cfg.Enabled {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ts := d.status.Targets[target]
+	ts.Name = target
+	if ts.Checks == nil {
+		ts.Checks = make(map[string]checks.Result)
+	}
+	ts.Checks[checkName] = result
+	d.status.Targets[target] = ts
+}
+
 // RecordRestart registra un restart
 func (d *Dashboard) RecordRestart(name string) {
 	if !d.cfg.Enabled {
@@ -311,12 +391,16 @@ func (d *Dashboard) handleAPITargets(w http.ResponseWriter, r *http.Request) {
 
 // handleAPITargetByName maneja GET, PUT, DELETE de un target específico
 func (d *Dashboard) handleAPITargetByName(w http.ResponseWriter, r *http.Request) {
-	// Extraer nombre del path: /api/targets/{name}
-	name := r.URL.Path[len("/api/targets/"):]
+	// Extraer nombre del path: /api/targets/{name} o /api/targets/{name}/{operación}
+	name, operation, _ := strings.Cut(r.URL.Path[len("/api/targets/"):], "/")
 	if name == "" {
 		http.Error(w, "Target name required", http.StatusBadRequest)
 		return
 	}
+	if operation != "" {
+		d.handleTargetControl(w, r, name, operation)
+		return
+	}
 
 	switch r.Method {
 	case http.MethodGet:
@@ -349,6 +433,355 @@ func (d *Dashboard) handleAPIConfig(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(d.fullConfig)
 }
 
+// handleAPIApprovals lista las acciones pendientes y resueltas recientemente
+func (d *Dashboard) handleAPIApprovals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	d.mu.RLock()
+	q := d.approvals
+	d.mu.RUnlock()
+	if q == nil {
+		http.Error(w, "Approvals not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	list := q.List()
+	if status := r.URL.Query().Get("status"); status != "" {
+		filtered := list[:0]
+		for _, a := range list {
+			if string(a.Status) == status {
+				filtered = append(filtered, a)
+			}
+		}
+		list = filtered
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// handleAPIApprovalByID maneja GET /api/approvals/{id} y POST /api/approvals/{id}/approve|reject
+func (d *Dashboard) handleAPIApprovalByID(w http.ResponseWriter, r *http.Request) {
+	d.mu.RLock()
+	q := d.approvals
+	d.mu.RUnlock()
+	if q == nil {
+		http.Error(w, "Approvals not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	id, decision, _ := strings.Cut(r.URL.Path[len("/api/approvals/"):], "/")
+	if id == "" {
+		http.Error(w, "Approval id required", http.StatusBadRequest)
+		return
+	}
+
+	if decision == "" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		a, ok := q.Get(id)
+		if !ok {
+			http.Error(w, "Approval not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(a)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// El operador que decide queda registrado con su usuario del dashboard
+	username, _, _ := r.BasicAuth()
+
+	var a approval.Action
+	var err error
+	switch decision {
+	case "approve":
+		a, err = q.Approve(id, username)
+	case "reject":
+		a, err = q.Reject(id, username)
+	default:
+		http.Error(w, "Unknown decision (must be approve or reject)", http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		status := http.StatusConflict
+		if a.ID == "" {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	d.log.Info("approval decided via API", logger.Fields("id", id, "decision", decision, "user", username))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
+
+// controlRequest cuerpo de las operaciones de runtime sobre un target
+type controlRequest struct {
+	Reason string        `json:"reason"`
+	Scope  control.Scope `json:"scope"` // pause: checks o recovery (default: recovery)
+	Force  bool          `json:"force"` // recover: ignorar cooldown, límite por hora y presión del host
+}
+
+// handleTargetControl maneja POST /api/targets/{name}/pause|resume|check|recover
+func (d *Dashboard) handleTargetControl(w http.ResponseWriter, r *http.Request, name, operation string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	d.mu.RLock()
+	c := d.control
+	d.mu.RUnlock()
+	if c == nil {
+		http.Error(w, "Runtime control not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req controlRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Reason == "" {
+		http.Error(w, "reason is required", http.StatusBadRequest)
+		return
+	}
+
+	// El operador queda registrado con su usuario del dashboard
+	username, _, _ := r.BasicAuth()
+
+	// Las operaciones no se cancelan si el cliente corta la conexión a mitad de un reinicio
+	ctx := context.WithoutCancel(r.Context())
+
+	var response interface{}
+	var err error
+	switch operation {
+	case "pause":
+		scope := req.Scope
+		if scope == "" {
+			scope = control.ScopeRecovery
+		}
+		response, err = c.Pause(name, scope, username, req.Reason)
+	case "resume":
+		err = c.Resume(name, username, req.Reason)
+		response = map[string]interface{}{"target": name, "paused": false}
+	case "check":
+		var healthy bool
+		healthy, err = c.CheckNow(ctx, name, username, req.Reason)
+		response = map[string]interface{}{"target": name, "healthy": healthy}
+	case "recover":
+		var result actions.Result
+		result, err = c.RecoverNow(ctx, name, req.Force, username, req.Reason)
+		response = map[string]interface{}{
+			"target":     name,
+			"success":    result.Success,
+			"message":    result.Message,
+			"latency_ms": result.Latency.Milliseconds(),
+		}
+	default:
+		http.Error(w, "Unknown operation (must be pause, resume, check or recover)", http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, control.ErrUnknownTarget):
+			status = http.StatusNotFound
+		case errors.Is(err, control.ErrInvalidScope):
+			status = http.StatusBadRequest
+		case errors.Is(err, control.ErrNotPaused), errors.Is(err, control.ErrBlocked):
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleAPIPaused lista los targets pausados con quién, por qué y desde cuándo
+func (d *Dashboard) handleAPIPaused(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	d.mu.RLock()
+	c := d.control
+	d.mu.RUnlock()
+	if c == nil {
+		http.Error(w, "Runtime control not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c.Paused())
+}
+
+// handleAPIMaintenance muestra las ventanas, los silencios y qué targets están afectados ahora
+func (d *Dashboard) handleAPIMaintenance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	d.mu.RLock()
+	m := d.maint
+	var targets []config.Target
+	if d.fullConfig != nil {
+		targets = d.fullConfig.Targets
+	}
+	d.mu.RUnlock()
+	if m == nil {
+		http.Error(w, "Maintenance not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	now := time.Now()
+	active := make(map[string][]maintenance.Match)
+	for _, target := range targets {
+		if matches := m.Active(target, now); len(matches) > 0 {
+			active[target.Name] = matches
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"windows":  m.Windows(now),
+		"silences": m.Silences(),
+		"targets":  active,
+	})
+}
+
+// silenceRequest cuerpo de POST /api/silences: duration ("2h", "30m") o expires_at
+type silenceRequest struct {
+	Targets   []string          `json:"targets"`
+	Labels    map[string]string `json:"labels"`
+	Author    string            `json:"author"`
+	Reason    string            `json:"reason"`
+	Suppress  []string          `json:"suppress"`
+	Duration  string            `json:"duration"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// handleAPISilences lista los silencios vigentes (GET) o crea uno (POST)
+func (d *Dashboard) handleAPISilences(w http.ResponseWriter, r *http.Request) {
+	d.mu.RLock()
+	m := d.maint
+	d.mu.RUnlock()
+	if m == nil {
+		http.Error(w, "Maintenance not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m.Silences())
+		return
+	case http.MethodPost:
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req silenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	expiresAt := req.ExpiresAt
+	if req.Duration != "" {
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 {
+			http.Error(w, "Invalid duration (e.g. 30m, 2h)", http.StatusBadRequest)
+			return
+		}
+		expiresAt = time.Now().Add(duration)
+	}
+
+	// Sin author explícito queda registrado el usuario del dashboard
+	author := req.Author
+	if author == "" {
+		author, _, _ = r.BasicAuth()
+	}
+
+	s, err := m.AddSilence(maintenance.Silence{
+		Targets:   req.Targets,
+		Labels:    req.Labels,
+		Author:    author,
+		Reason:    req.Reason,
+		Suppress:  req.Suppress,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil && s.ID == "" {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		d.log.Warn("silence not persisted", logger.Fields("id", s.ID, "error", err))
+	}
+
+	d.log.Info("silence created via API", logger.Fields(
+		"id", s.ID,
+		"author", s.Author,
+		"reason", s.Reason,
+		"expires_at", s.ExpiresAt.Format(time.RFC3339),
+	))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(s)
+}
+
+// handleAPISilenceByID elimina un silencio antes de su vencimiento (DELETE /api/silences/{id})
+func (d *Dashboard) handleAPISilenceByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	d.mu.RLock()
+	m := d.maint
+	d.mu.RUnlock()
+	if m == nil {
+		http.Error(w, "Maintenance not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	id := r.URL.Path[len("/api/silences/"):]
+	s, err := m.RemoveSilence(id)
+	if err != nil && s.ID == "" {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		d.log.Warn("silence removal not persisted", logger.Fields("id", id, "error", err))
+	}
+
+	username, _, _ := r.BasicAuth()
+	d.log.Info("silence removed via API", logger.Fields("id", id, "user", username))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
 // listTargets lista todos los targets de la configuración
 func (d *Dashboard) listTargets(w http.ResponseWriter, r *http.Request) {
 	d.mu.RLock()
@@ -503,6 +936,60 @@ func (d *Dashboard) deleteTarget(w http.ResponseWriter, r *http.Request, name st
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handleAPIHeartbeat recibe pings: /api/heartbeat/{token}[/start|/success|/fail|/{exit_code}]
+func (d *Dashboard) handleAPIHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.Trim(r.URL.Path[len("/api/heartbeat/"):], "/")
+	parts := strings.Split(path, "/")
+	if parts[0] == "" || len(parts) > 2 {
+		http.Error(w, "Heartbeat token required", http.StatusBadRequest)
+		return
+	}
+
+	suffix := ""
+	if len(parts) == 2 {
+		suffix = parts[1]
+	}
+	kind, exitCode, err := heartbeat.ParsePing(suffix)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// exit_code por query string tiene prioridad
+	if code := r.URL.Query().Get("exit_code"); code != "" {
+		exitCode, err = strconv.Atoi(code)
+		if err != nil {
+			http.Error(w, "Invalid exit_code", http.StatusBadRequest)
+			return
+		}
+		if kind == heartbeat.KindFail && exitCode == 0 {
+			kind = heartbeat.KindSuccess
+		}
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(r.Body, heartbeat.MaxBodySize))
+
+	if err := heartbeat.Default().Record(parts[0], kind, exitCode, string(body)); err != nil {
+		if err == heartbeat.ErrUnknownToken {
+			http.Error(w, "Heartbeat not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "ok",
+		"kind":   kind,
+	})
+}
+
 // saveConfig guarda la configuración en disco (debe llamarse con lock activo)
 func (d *Dashboard) saveConfig() error {
 	data, err := yaml.Marshal(d.fullConfig)
@@ -707,6 +1194,14 @@ const htmlTemplate = `<!DOCTYPE html>
                     {{.Message}}
                 </div>
                 {{end}}
+                {{range $name, $r := .Checks}}
+                <details style="margin-top: 8px; font-size: 0.85em;">
+                    <summary>{{if eq $r.Status "ok"}}✓{{else if eq $r.Status "degraded"}}⚠{{else}}✗{{end}} {{$name}} ({{$r.Latency.Milliseconds}} ms{{with $r.ExitCode}}, exit {{.}}{{end}})</summary>
+                    <div style="padding: 6px 10px;">{{$r.Message}}</div>
+                    {{if $r.Stdout}}<pre style="background: #f3f4f6; padding: 8px; border-radius: 4px; overflow-x: auto;">{{$r.Stdout}}</pre>{{end}}
+                    {{if $r.Stderr}}<pre style="background: #fee2e2; padding: 8px; border-radius: 4px; overflow-x: auto;">{{$r.Stderr}}</pre>{{end}}
+                </details>
+                {{end}}
             </div>
             {{end}}
         </div>
@@ -0,0 +1,487 @@
+// Package diagnostics recoge la evidencia de un target caído antes de que la acción de
+// recuperación la borre: estado del proceso en /proc, últimas líneas de logs, salida de
+// comandos de diagnóstico y los resultados de los checks fallidos. Cada recogida se guarda
+// en un directorio con timestamp (bundle) y se aplica retención por número y tamaño.
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/checks"
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/process"
+)
+
+// Valores por defecto de DiagnosticsConfig
+const (
+	DefaultDir            = "/var/lib/neon-watchdog/diagnostics"
+	DefaultLogLines       = 200
+	DefaultTimeoutSeconds = 10
+	DefaultMaxBundles     = 10
+	DefaultMaxSizeMB      = 100
+
+	// defaultMaxOutputBytes salida guardada por comando (jstack y similares superan los 64KB)
+	defaultMaxOutputBytes = 1 << 20
+
+	// maxPIDs procesos inspeccionados como máximo cuando process_name encuentra varios
+	maxPIDs = 10
+)
+
+// bundleTimeFormat nombre de cada bundle; ordenar por nombre equivale a ordenar por fecha
+const bundleTimeFormat = "20060102-150405.000"
+
+// procFiles ficheros de /proc/<pid> copiados al bundle
+var procFiles = []string{"status", "stack", "limits", "cmdline", "wchan"}
+
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// CommandContext datos disponibles en las plantillas de los comandos y, en los comandos
+// shell, como variables NEON_TARGET, NEON_PID y NEON_PIDS
+type CommandContext struct {
+	Target string
+	PID    int // 0 si no se encontró el proceso
+	PIDs   []int
+}
+
+// env variables NEON_* del comando
+func (c CommandContext) env() map[string]string {
+	pids := make([]string, len(c.PIDs))
+	for i, pid := range c.PIDs {
+		pids[i] = strconv.Itoa(pid)
+	}
+	env := map[string]string{
+		"NEON_TARGET": c.Target,
+		"NEON_PIDS":   strings.Join(pids, " "),
+		"NEON_PID":    "",
+	}
+	if c.PID > 0 {
+		env["NEON_PID"] = strconv.Itoa(c.PID)
+	}
+	return env
+}
+
+// Collect recoge un bundle para el target y retorna su directorio. Los fallos parciales
+// (proceso inexistente, log ilegible, comando fallido) se anotan en errors.txt del bundle;
+// solo se retorna error si no se puede crear el directorio
+func Collect(ctx context.Context, cfg *config.DiagnosticsConfig, target config.Target, failed []checks.Result) (string, error) {
+	targetDir := filepath.Join(dirOrDefault(cfg.Dir), sanitize(target.Name))
+	bundle := filepath.Join(targetDir, time.Now().Format(bundleTimeFormat))
+	if err := os.MkdirAll(bundle, 0750); err != nil {
+		return "", fmt.Errorf("cannot create diagnostics bundle: %w", err)
+	}
+
+	c := &collector{bundle: bundle}
+
+	c.writeJSON("checks.json", failed)
+
+	pids, err := resolvePIDs(ctx, cfg, target)
+	if err != nil {
+		c.errorf("pid: %v", err)
+	}
+	for _, pid := range pids {
+		c.collectProc(pid)
+	}
+
+	logLines := cfg.LogLines
+	if logLines == 0 {
+		logLines = DefaultLogLines
+	}
+	for _, path := range cfg.LogFiles {
+		c.collectLog(path, logLines)
+	}
+
+	cmdCtx := CommandContext{Target: target.Name, PIDs: pids}
+	if len(pids) > 0 {
+		cmdCtx.PID = pids[0]
+	}
+	for i, cmd := range cfg.Commands {
+		c.collectCommand(ctx, cfg, i, cmd, cmdCtx)
+	}
+
+	c.flushErrors()
+
+	maxBundles := cfg.MaxBundles
+	if maxBundles == 0 {
+		maxBundles = DefaultMaxBundles
+	}
+	maxSizeMB := cfg.MaxSizeMB
+	if maxSizeMB == 0 {
+		maxSizeMB = DefaultMaxSizeMB
+	}
+	if err := prune(targetDir, maxBundles, int64(maxSizeMB)<<20); err != nil {
+		return bundle, fmt.Errorf("diagnostics retention: %w", err)
+	}
+
+	return bundle, nil
+}
+
+func dirOrDefault(dir string) string {
+	if dir == "" {
+		return DefaultDir
+	}
+	return dir
+}
+
+// sanitize convierte un nombre en un nombre de fichero seguro
+func sanitize(name string) string {
+	name = strings.Trim(unsafeName.ReplaceAllString(name, "_"), "._")
+	if name == "" {
+		return "unnamed"
+	}
+	return name
+}
+
+// collector escribe los ficheros de un bundle y acumula los fallos parciales
+type collector struct {
+	bundle string
+	errors []string
+}
+
+func (c *collector) errorf(format string, args ...interface{}) {
+	c.errors = append(c.errors, fmt.Sprintf(format, args...))
+}
+
+func (c *collector) writeFile(name string, data []byte) {
+	path := filepath.Join(c.bundle, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		c.errorf("%s: %v", name, err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0640); err != nil {
+		c.errorf("%s: %v", name, err)
+	}
+}
+
+func (c *collector) writeJSON(name string, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		c.errorf("%s: %v", name, err)
+		return
+	}
+	c.writeFile(name, data)
+}
+
+func (c *collector) flushErrors() {
+	if len(c.errors) > 0 {
+		c.writeFile("errors.txt", []byte(strings.Join(c.errors, "\n")+"\n"))
+	}
+}
+
+// collectProc copia status, stack, limits, cmdline y wchan y lista los descriptores abiertos
+func (c *collector) collectProc(pid int) {
+	procDir := fmt.Sprintf("/proc/%d", pid)
+	dest := filepath.Join("proc", strconv.Itoa(pid))
+
+	for _, name := range procFiles {
+		data, err := os.ReadFile(filepath.Join(procDir, name))
+		if err != nil {
+			c.errorf("%s/%s: %v", procDir, name, err)
+			continue
+		}
+		if name == "cmdline" {
+			data = bytes.TrimRight(bytes.ReplaceAll(data, []byte{0}, []byte{' '}), " ")
+			data = append(data, '\n')
+		}
+		c.writeFile(filepath.Join(dest, name), data)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(procDir, "fd"))
+	if err != nil {
+		c.errorf("%s/fd: %v", procDir, err)
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, _ := strconv.Atoi(entries[i].Name())
+		b, _ := strconv.Atoi(entries[j].Name())
+		return a < b
+	})
+	var sb strings.Builder
+	for _, entry := range entries {
+		link, err := os.Readlink(filepath.Join(procDir, "fd", entry.Name()))
+		if err != nil {
+			link = "? (" + err.Error() + ")"
+		}
+		fmt.Fprintf(&sb, "%s -> %s\n", entry.Name(), link)
+	}
+	c.writeFile(filepath.Join(dest, "fd"), []byte(sb.String()))
+}
+
+// collectLog guarda las últimas lines líneas del log
+func (c *collector) collectLog(path string, lines int) {
+	data, err := tailFile(path, lines)
+	if err != nil {
+		c.errorf("log %s: %v", path, err)
+		return
+	}
+	c.writeFile(filepath.Join("logs", sanitize(strings.TrimPrefix(path, "/"))), data)
+}
+
+// collectCommand ejecuta un comando de diagnóstico y guarda su salida con la línea de
+// comando y el código de salida
+func (c *collector) collectCommand(ctx context.Context, cfg *config.DiagnosticsConfig, index int, cmd config.Hook, cmdCtx CommandContext) {
+	argv, err := cmd.Argv(cmdCtx)
+	if err != nil {
+		c.errorf("commands[%d]: %v", index, err)
+		return
+	}
+
+	// El fichero se nombra por el programa (el primero del script en comandos shell)
+	commandLine, name := strings.Join(argv, " "), argv[0]
+	if cmd.Shell != "" {
+		commandLine = argv[2]
+		if fields := strings.Fields(commandLine); len(fields) > 0 {
+			name = fields[0]
+		}
+	}
+	file := filepath.Join("commands", fmt.Sprintf("%02d-%s.txt", index+1, sanitize(filepath.Base(name))))
+
+	timeout := commandTimeout(cfg, cmd)
+	cmdRunCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	maxOutput := cfg.MaxOutputBytes
+	if maxOutput == 0 {
+		maxOutput = defaultMaxOutputBytes
+	}
+	output := process.NewCappedBuffer(maxOutput)
+
+	// Las variables del comando se añaden a las de exec options sin modificarlas
+	opts := cfg.ExecOptions
+	opts.Env = cmdCtx.env()
+	for k, v := range cfg.ExecOptions.Env {
+		opts.Env[k] = v
+	}
+
+	proc, err := process.Command(cmdRunCtx, &opts, argv[0], argv[1:]...)
+	if err == nil {
+		proc.Stdout = output
+		proc.Stderr = output
+		err = proc.Run()
+	}
+
+	status := "exit: 0"
+	if err != nil {
+		exitErr, isExit := err.(*exec.ExitError)
+		switch {
+		case cmdRunCtx.Err() == context.DeadlineExceeded:
+			status = fmt.Sprintf("error: timeout after %ds", timeout)
+		case isExit:
+			status = fmt.Sprintf("exit: %d", exitErr.ExitCode())
+		default:
+			status = "error: " + err.Error()
+		}
+		c.errorf("commands[%d] %s: %s", index, commandLine, status)
+	}
+	if output.Truncated {
+		status += " (output truncated)"
+	}
+
+	content := fmt.Sprintf("$ %s\n\n%s\n%s\n", commandLine, strings.TrimRight(output.String(), "\n"), status)
+	c.writeFile(file, []byte(content))
+}
+
+// commandTimeout segundos que puede tardar un comando de diagnóstico
+func commandTimeout(cfg *config.DiagnosticsConfig, cmd config.Hook) int {
+	timeout := cmd.TimeoutSeconds
+	if timeout == 0 {
+		timeout = cfg.TimeoutSeconds
+	}
+	if timeout == 0 {
+		timeout = DefaultTimeoutSeconds
+	}
+	return timeout
+}
+
+// Timeout cota de lo que puede tardar Collect: la suma de los timeouts de los comandos más
+// DefaultTimeoutSeconds para resolver PIDs y leer /proc y los logs
+func Timeout(cfg *config.DiagnosticsConfig) time.Duration {
+	total := DefaultTimeoutSeconds
+	for _, cmd := range cfg.Commands {
+		total += commandTimeout(cfg, cmd)
+	}
+	return time.Duration(total) * time.Second
+}
+
+// resolvePIDs busca los PIDs a inspeccionar según la configuración o, si no se indica,
+// según los checks y la acción del target
+func resolvePIDs(ctx context.Context, cfg *config.DiagnosticsConfig, target config.Target) ([]int, error) {
+	switch {
+	case cfg.PidFile != "":
+		return pidFromFile(cfg.PidFile)
+	case cfg.ProcessName != "":
+		return pidsByName(ctx, cfg.ProcessName)
+	case cfg.SystemdUnit != "":
+		return pidFromUnit(ctx, cfg.SystemdUnit)
+	}
+
+	if check := findCheck(target.Checks, "pid_file"); check != nil && check.PidFile != "" {
+		return pidFromFile(check.PidFile)
+	}
+	if check := findCheck(target.Checks, "process_name"); check != nil && check.ProcessName != "" {
+		return pidsByName(ctx, check.ProcessName)
+	}
+	if target.Action.Type == "systemd" && target.Action.Systemd != nil {
+		return pidFromUnit(ctx, target.Action.Systemd.Unit)
+	}
+	return nil, nil
+}
+
+// findCheck busca el primer check del tipo indicado, también dentro de checks lógicos
+func findCheck(checkList []config.Check, checkType string) *config.Check {
+	for i := range checkList {
+		if checkList[i].Type == checkType {
+			return &checkList[i]
+		}
+		if found := findCheck(checkList[i].Checks, checkType); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func pidFromFile(path string) ([]int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return nil, fmt.Errorf("invalid PID in %s", path)
+	}
+	return []int{pid}, nil
+}
+
+func pidsByName(ctx context.Context, name string) ([]int, error) {
+	output, err := exec.CommandContext(ctx, "pgrep", "-x", name).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return nil, fmt.Errorf("process '%s' not found", name)
+		}
+		return nil, fmt.Errorf("pgrep error: %w", err)
+	}
+
+	var pids []int
+	for _, field := range strings.Fields(string(output)) {
+		if pid, err := strconv.Atoi(field); err == nil {
+			pids = append(pids, pid)
+		}
+		if len(pids) == maxPIDs {
+			break
+		}
+	}
+	return pids, nil
+}
+
+func pidFromUnit(ctx context.Context, unit string) ([]int, error) {
+	output, err := exec.CommandContext(ctx, "systemctl", "show", "--property=MainPID", "--value", unit).Output()
+	if err != nil {
+		return nil, fmt.Errorf("systemctl show %s: %w", unit, err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil || pid <= 0 {
+		return nil, fmt.Errorf("unit %s has no main PID", unit)
+	}
+	return []int{pid}, nil
+}
+
+// tailFile lee las últimas n líneas de un fichero leyendo hacia atrás por bloques
+func tailFile(path string, n int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	const blockSize = 64 * 1024
+	var data []byte
+	offset := size
+	for offset > 0 && bytes.Count(data, []byte{'\n'}) <= n {
+		readSize := int64(blockSize)
+		if offset < readSize {
+			readSize = offset
+		}
+		offset -= readSize
+		block := make([]byte, readSize)
+		if _, err := f.ReadAt(block, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		data = append(block, data...)
+	}
+
+	// Conservar solo las n últimas líneas (sin contar el salto final)
+	trimmed := bytes.TrimSuffix(data, []byte{'\n'})
+	for i, count := len(trimmed)-1, 0; i >= 0; i-- {
+		if trimmed[i] == '\n' {
+			count++
+			if count == n {
+				return data[i+1:], nil
+			}
+		}
+	}
+	return data, nil
+}
+
+// prune borra los bundles más antiguos hasta cumplir maxBundles y maxBytes.
+// El bundle más reciente se conserva siempre
+func prune(targetDir string, maxBundles int, maxBytes int64) error {
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		return err
+	}
+
+	type bundleInfo struct {
+		path string
+		size int64
+	}
+	var bundles []bundleInfo
+	var total int64
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(targetDir, entry.Name())
+		size := dirSize(path)
+		bundles = append(bundles, bundleInfo{path: path, size: size})
+		total += size
+	}
+	sort.Slice(bundles, func(i, j int) bool { return bundles[i].path < bundles[j].path })
+
+	for len(bundles) > 1 && (len(bundles) > maxBundles || total > maxBytes) {
+		if err := os.RemoveAll(bundles[0].path); err != nil {
+			return err
+		}
+		total -= bundles[0].size
+		bundles = bundles[1:]
+	}
+	return nil
+}
+
+func dirSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
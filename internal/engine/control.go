@@ -0,0 +1,205 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/actions"
+	"github.com/tgextreme/neon-watchdog/internal/checks"
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/control"
+	"github.com/tgextreme/neon-watchdog/internal/logger"
+	"github.com/tgextreme/neon-watchdog/internal/notifications"
+)
+
+var _ control.Controller = (*Engine)(nil)
+
+// Pause pausa los checks o solo la recuperación del target y lo guarda en el state file.
+// Espera a que termine la pasada en curso
+func (e *Engine) Pause(name string, scope control.Scope, by, reason string) (control.Pause, error) {
+	if scope != control.ScopeChecks && scope != control.ScopeRecovery {
+		return control.Pause{}, control.ErrInvalidScope
+	}
+	_, state, err := e.targetState(name)
+	if err != nil {
+		return control.Pause{}, err
+	}
+
+	e.runMu.Lock()
+	defer e.runMu.Unlock()
+
+	pause := control.Pause{Scope: scope, By: by, Reason: reason, Since: time.Now()}
+	e.state.mu.Lock()
+	state.Pause = &pause
+	e.state.mu.Unlock()
+
+	e.recordControl("target_paused", name, fmt.Sprintf("%s paused by %s", scope, by), by, reason,
+		map[string]interface{}{"scope": scope})
+	e.persistState()
+	return pause, nil
+}
+
+// Resume quita la pausa del target
+func (e *Engine) Resume(name, by, reason string) error {
+	_, state, err := e.targetState(name)
+	if err != nil {
+		return err
+	}
+
+	e.runMu.Lock()
+	defer e.runMu.Unlock()
+
+	e.state.mu.Lock()
+	previous := state.Pause
+	state.Pause = nil
+	e.state.mu.Unlock()
+	if previous == nil {
+		return fmt.Errorf("%w: %s", control.ErrNotPaused, name)
+	}
+
+	e.recordControl("target_resumed", name, fmt.Sprintf("%s resumed by %s", previous.Scope, by), by, reason,
+		map[string]interface{}{"scope": previous.Scope, "paused_by": previous.By, "paused_since": previous.Since})
+	e.persistState()
+	return nil
+}
+
+// Paused retorna las pausas activas por target
+func (e *Engine) Paused() map[string]control.Pause {
+	e.state.mu.RLock()
+	defer e.state.mu.RUnlock()
+
+	paused := make(map[string]control.Pause)
+	for name, state := range e.state.Targets {
+		if state.Pause != nil {
+			paused[name] = *state.Pause
+		}
+	}
+	return paused
+}
+
+// CheckNow ejecuta una pasada de checks del target sin esperar al intervalo, aunque esté
+// pausado. Si falla, la recuperación sigue las reglas habituales
+func (e *Engine) CheckNow(ctx context.Context, name, by, reason string) (bool, error) {
+	target, _, err := e.targetState(name)
+	if err != nil {
+		return false, err
+	}
+
+	e.runMu.Lock()
+	defer e.runMu.Unlock()
+
+	e.recordControl("manual_check", name, fmt.Sprintf("checks run by %s", by), by, reason, nil)
+	healthy := e.checkTarget(ctx, target)
+	e.persistState()
+	return healthy, nil
+}
+
+// RecoverNow ejecuta la acción de recuperación del target sin esperar a fail_threshold.
+// Ignora el modo, las pausas y las ventanas de mantenimiento: lo pide un operador. Sin
+// force respeta el cooldown, el límite por hora y la presión del host
+func (e *Engine) RecoverNow(ctx context.Context, name string, force bool, by, reason string) (actions.Result, error) {
+	target, state, err := e.targetState(name)
+	if err != nil {
+		return actions.Result{}, err
+	}
+
+	e.runMu.Lock()
+	defer e.runMu.Unlock()
+
+	if !force {
+		if err := e.recoveryBlocked(target, state); err != nil {
+			return actions.Result{}, err
+		}
+	}
+
+	action, err := actions.NewAction(target.Action, false, e.logger)
+	if err != nil {
+		return actions.Result{}, fmt.Errorf("failed to create action: %w", err)
+	}
+
+	e.recordControl("manual_recovery", name, fmt.Sprintf("recovery triggered by %s", by), by, reason,
+		map[string]interface{}{"action": action.Name(), "force": force})
+
+	// El motivo llega a hooks y diagnósticos como si fuera un check fallido
+	failed := []checks.Result{{
+		CheckType: "manual",
+		Message:   fmt.Sprintf("recovery triggered by %s: %s", by, reason),
+	}}
+	diagnosticsPath := e.collectDiagnostics(ctx, target, failed)
+	return e.runAction(ctx, target, state, action, failed, diagnosticsPath), nil
+}
+
+// targetState busca el target en la configuración y su estado (lo crea si no existe)
+func (e *Engine) targetState(name string) (config.Target, *TargetState, error) {
+	for _, target := range e.config.Targets {
+		if target.Name != name {
+			continue
+		}
+
+		e.state.mu.Lock()
+		defer e.state.mu.Unlock()
+		state := e.state.Targets[name]
+		if state == nil {
+			state = &TargetState{
+				Name:               name,
+				IsHealthy:          true,
+				RestartsInLastHour: []time.Time{},
+			}
+			e.state.Targets[name] = state
+		}
+		return target, state, nil
+	}
+	return config.Target{}, nil, fmt.Errorf("%w: %s", control.ErrUnknownTarget, name)
+}
+
+// checksPaused indica si un operador ha pausado los checks del target
+func (e *Engine) checksPaused(name string) bool {
+	e.state.mu.RLock()
+	defer e.state.mu.RUnlock()
+	state := e.state.Targets[name]
+	return state != nil && state.Pause != nil && state.Pause.Scope == control.ScopeChecks
+}
+
+// recoveryPaused indica si un operador ha pausado la recuperación del target
+func (e *Engine) recoveryPaused(target config.Target, state *TargetState) bool {
+	e.state.mu.RLock()
+	pause := state.Pause
+	e.state.mu.RUnlock()
+	if pause == nil {
+		return false
+	}
+
+	e.logger.Debug("recovery action skipped: target paused", logger.Fields(
+		"target", target.Name,
+		"scope", pause.Scope,
+		"by", pause.By,
+	))
+	return true
+}
+
+// recordControl registra una operación manual en el log y el historial y la publica
+func (e *Engine) recordControl(eventType, target, message, by, reason string, details map[string]interface{}) {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["by"] = by
+	details["reason"] = reason
+
+	e.logger.Info("operator action", logger.Fields(
+		"operation", eventType,
+		"target", target,
+		"by", by,
+		"reason", reason,
+	))
+	e.publish(notifications.Event{
+		Type:     "control",
+		Target:   target,
+		Message:  message,
+		Severity: "info",
+		Details:  details,
+	})
+	if e.history != nil {
+		e.history.RecordEvent(eventType, target, message, details)
+	}
+}
@@ -3,15 +3,28 @@ package engine
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/tgextreme/neon-watchdog/internal/actions"
+	"github.com/tgextreme/neon-watchdog/internal/approval"
 	"github.com/tgextreme/neon-watchdog/internal/checks"
 	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/control"
+	"github.com/tgextreme/neon-watchdog/internal/dashboard"
+	"github.com/tgextreme/neon-watchdog/internal/diagnostics"
+	"github.com/tgextreme/neon-watchdog/internal/heartbeat"
+	"github.com/tgextreme/neon-watchdog/internal/history"
 	"github.com/tgextreme/neon-watchdog/internal/logger"
+	"github.com/tgextreme/neon-watchdog/internal/maintenance"
+	"github.com/tgextreme/neon-watchdog/internal/metrics"
+	"github.com/tgextreme/neon-watchdog/internal/notifications"
+	"github.com/tgextreme/neon-watchdog/internal/rules"
+	"github.com/tgextreme/neon-watchdog/internal/sdnotify"
 )
 
 // TargetState mantiene el estado de un target
@@ -22,6 +35,16 @@ type TargetState struct {
 	LastRestartTime     time.Time   `json:"last_restart_time"`
 	RestartsInLastHour  []time.Time `json:"restarts_in_last_hour"`
 	IsHealthy           bool        `json:"is_healthy"`
+	Degraded            bool        `json:"degraded"`
+	DegradedReason      string      `json:"degraded_reason,omitempty"`
+	SuppressedBy        string      `json:"suppressed_by,omitempty"` // ventana o silencio que suprime la acción
+
+	// Pause pausa de checks o de recuperación pedida por un operador (se persiste)
+	Pause *control.Pause `json:"pause,omitempty"`
+
+	// dryRuns acciones simuladas en la última hora (mode: dry_run). No se persisten ni
+	// cuentan para el cooldown y el límite reales
+	dryRuns []time.Time
 }
 
 // State mantiene el estado global del watchdog
@@ -30,14 +53,60 @@ type State struct {
 	mu      sync.RWMutex
 }
 
+// EventHandler recibe los eventos del engine: failure, recovery, warning, check y action
+type EventHandler func(event notifications.Event)
+
 // Engine es el motor principal del watchdog
 type Engine struct {
-	config *config.Config
-	logger *logger.Logger
-	state  *State
+	config   *config.Config
+	logger   *logger.Logger
+	state    *State
+	notifier *notifications.Manager
+	metrics  *metrics.Collector
+	history  *history.History
+
+	dashboard *dashboard.Dashboard
+
+	// Último estado de cada check ("target/check") registrado en el historial; protegido por runMu
+	checkStatus map[string]checks.Status
+
+	// Última perfdata guardada en el historial por check ("target/check"); protegido por runMu
+	perfdataSamples map[string]perfdataSample
+
+	// Expresiones healthy_when compiladas por target; protegido por runMu
+	healthyWhen map[string]*rules.Expr
+
+	// Acciones en espera de aprobación (mode: approval)
+	approvals *approval.Queue
+	pendingMu sync.Mutex
+	pending   map[string]pendingAction
+
+	// Contexto de las acciones aprobadas en ejecución: se cancela al parar Run, que espera
+	// a que terminen
+	approvedCtx    context.Context
+	cancelApproved context.CancelFunc
+	approved       sync.WaitGroup
+
+	// Ventanas de mantenimiento y silencios
+	maintenance *maintenance.Manager
+
+	// runMu serializa las pasadas de checks y las operaciones manuales sobre los targets
+	runMu sync.Mutex
+
+	// Último estado escrito en disco, para no reescribir el state file si no ha cambiado
+	saveMu       sync.Mutex
+	savedPath    string
+	savedTargets json.RawMessage
+
+	// Último avance del bucle de scheduling (UnixNano), para el watchdog de systemd
+	progress atomic.Int64
+
+	subscribersMu sync.RWMutex
+	subscribers   []EventHandler
 }
 
-// New crea un nuevo engine
+// New crea un nuevo engine. No lee ni escribe nada en disco: el estado guardado se carga
+// con Restore una vez tomado el bloqueo de instancia
 func New(cfg *config.Config, log *logger.Logger) *Engine {
 	state := &State{
 		Targets: make(map[string]*TargetState),
@@ -53,76 +122,221 @@ func New(cfg *config.Config, log *logger.Logger) *Engine {
 		}
 	}
 
-	return &Engine{
-		config: cfg,
-		logger: log,
-		state:  state,
+	e := &Engine{
+		config:          cfg,
+		logger:          log,
+		state:           state,
+		approvals:       approval.NewQueue(cfg.ApprovalsFile(), log),
+		pending:         make(map[string]pendingAction),
+		checkStatus:     make(map[string]checks.Status),
+		healthyWhen:     make(map[string]*rules.Expr),
+		perfdataSamples: make(map[string]perfdataSample),
+		maintenance:     maintenance.NewManager(cfg.Maintenance, cfg.SilencesFile()),
 	}
+	e.approvedCtx, e.cancelApproved = context.WithCancel(context.Background())
+	e.approvals.SetHandler(e.handleApproval)
+	return e
 }
 
-// LoadState carga el estado desde un archivo
-func (e *Engine) LoadState(path string) error {
-	if path == "" {
-		return nil
+// Restore carga el estado guardado: state_file, heartbeats, silencios y posición de los
+// checks de logs y, en el daemon, la cola de aprobaciones. Puede escribir en esos archivos (altas de heartbeats, acciones
+// pendientes canceladas): solo se llama con el bloqueo de instancia tomado. Falla si el
+// state_file es de una versión posterior del watchdog
+func (e *Engine) Restore(daemon bool) error {
+	if err := e.LoadState(e.config.StateFile); err != nil {
+		if errors.Is(err, errStateTooNew) {
+			return err
+		}
+		e.logger.Warn("failed to load state", logger.Fields("error", err))
 	}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // No es un error si no existe
+	// Store de pings compartido por los checks heartbeat, el dashboard y el socket Unix
+	// (incluidos los pings guardados por `ping` sin daemon)
+	pings := heartbeat.Configure(&config.HeartbeatConfig{
+		SocketPath: e.config.HeartbeatSocket(),
+		StateFile:  e.config.HeartbeatStateFile(),
+	}, e.logger)
+	for _, target := range e.config.GetActiveTargets() {
+		registerHeartbeats(pings, target.Checks)
+	}
+
+	if err := e.maintenance.Load(); err != nil {
+		e.logger.Warn("failed to load silences", logger.Fields("error", err))
+	}
+
+	// Posición de lectura de log_pattern y kernel_events entre ejecuciones del timer
+	checks.SetStateDir(e.config.CheckStateDir())
+
+	// Un check puntual no usa la cola: sin daemon no hay quien apruebe
+	if daemon {
+		if err := e.approvals.Load(); err != nil {
+			e.logger.Warn("failed to load approval queue", logger.Fields("error", err))
 		}
-		return fmt.Errorf("error reading state file: %w", err)
+		e.approvals.FailInterrupted()
+		e.restorePending()
 	}
+	return nil
+}
 
-	e.state.mu.Lock()
-	defer e.state.mu.Unlock()
+// SetNotifier configura el manager de notificaciones para cambios de estado
+func (e *Engine) SetNotifier(n *notifications.Manager) {
+	e.notifier = n
+}
+
+// SetMetrics configura el collector de métricas para los valores reportados por checks
+func (e *Engine) SetMetrics(m *metrics.Collector) {
+	e.metrics = m
+}
+
+// SetHistory configura el historial donde se guardan los valores reportados por checks
+func (e *Engine) SetHistory(h *history.History) {
+	e.history = h
+}
 
-	if err := json.Unmarshal(data, e.state); err != nil {
-		return fmt.Errorf("error parsing state file: %w", err)
+// SetDashboard configura el dashboard que muestra el último resultado de cada check
+// y expone la cola de aprobaciones, los silencios y las operaciones de runtime
+func (e *Engine) SetDashboard(d *dashboard.Dashboard) {
+	e.dashboard = d
+	d.SetApprovals(e.approvals)
+	d.SetMaintenance(e.maintenance)
+	d.SetController(e)
+}
+
+// recordCheckMetrics exporta y guarda en el historial las métricas de un resultado
+func (e *Engine) recordCheckMetrics(targetName, checkName string, result checks.Result) {
+	if e.metrics != nil {
+		values := make([]metrics.Perfdata, 0, len(result.Metrics))
+		for _, m := range result.Metrics {
+			values = append(values, metrics.Perfdata{Label: m.Name, Unit: m.Unit, Value: m.Value})
+		}
+		e.metrics.SetPerfdata(targetName, checkName, values)
 	}
 
-	e.logger.Info("state loaded from file", logger.Fields("path", path))
-	return nil
+	if e.history == nil || len(result.Metrics) == 0 {
+		return
+	}
+
+	// Los valores cambian en casi cada pasada: al historial va una muestra por intervalo, o
+	// antes si el check reporta otras labels
+	key := targetName + "/" + checkName
+	labels := make([]string, 0, len(result.Metrics))
+	for _, m := range result.Metrics {
+		labels = append(labels, m.Name)
+	}
+	sample := perfdataSample{at: time.Now(), labels: strings.Join(labels, ",")}
+	if last, ok := e.perfdataSamples[key]; ok && last.labels == sample.labels && sample.at.Sub(last.at) < e.config.PerfdataInterval() {
+		return
+	}
+	e.perfdataSamples[key] = sample
+
+	e.history.RecordEvent("perfdata", targetName, result.Message, map[string]interface{}{
+		"check":    checkName,
+		"perfdata": result.Metrics,
+	})
 }
 
-// SaveState guarda el estado a un archivo
-func (e *Engine) SaveState(path string) error {
-	if path == "" {
-		return nil
+// perfdataSample última perfdata de un check guardada en el historial
+type perfdataSample struct {
+	at     time.Time
+	labels string
+}
+
+// recordCheckResult guarda el resultado en la vista del dashboard y, cuando cambia el estado
+// del check, en el historial. El historial no guarda stdout/stderr: el último resultado
+// completo está en el dashboard
+func (e *Engine) recordCheckResult(targetName, checkName string, result checks.Result) {
+	key := targetName + "/" + checkName
+	status := result.Status()
+	if previous, ok := e.checkStatus[key]; e.history != nil && (!ok || previous != status) {
+		eventType := "check_passed"
+		if !result.Success {
+			eventType = "check_failed"
+		}
+		summary := result
+		summary.Stdout, summary.Stderr = "", ""
+		e.history.RecordEvent(eventType, targetName, result.Message, map[string]interface{}{
+			"check":  checkName,
+			"result": summary,
+		})
 	}
+	e.checkStatus[key] = status
 
-	e.state.mu.RLock()
-	data, err := json.MarshalIndent(e.state, "", "  ")
-	e.state.mu.RUnlock()
+	if e.dashboard != nil {
+		e.dashboard.RecordCheck(targetName, checkName, result)
+	}
+}
 
-	if err != nil {
-		return fmt.Errorf("error marshaling state: %w", err)
+// Subscribe registra un handler que recibe todos los eventos del engine.
+// Los handlers se llaman de forma síncrona desde el loop de checks
+func (e *Engine) Subscribe(handler EventHandler) {
+	e.subscribersMu.Lock()
+	defer e.subscribersMu.Unlock()
+	e.subscribers = append(e.subscribers, handler)
+}
+
+// publish entrega un evento a los suscriptores
+func (e *Engine) publish(event notifications.Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("error writing state file: %w", err)
+	e.subscribersMu.RLock()
+	handlers := e.subscribers
+	e.subscribersMu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
 	}
+}
 
-	return nil
+// notify publica un evento y lo envía a las notificaciones configuradas, salvo que
+// el target esté en una ventana de mantenimiento o silencio que suprima notificaciones
+func (e *Engine) notify(event notifications.Event) {
+	e.publish(event)
+	if e.notifier == nil {
+		return
+	}
+	if match, ok := e.notificationSuppressed(event.Target); ok {
+		e.logger.Debug("notification suppressed by maintenance", logger.Fields(
+			"target", event.Target,
+			"type", event.Type,
+			match.Kind, match.ID,
+		))
+		return
+	}
+	e.notifier.Notify(event)
 }
 
 // CheckOnce ejecuta una pasada de checks sobre todos los targets
 func (e *Engine) CheckOnce(ctx context.Context) bool {
+	e.runMu.Lock()
+	defer e.runMu.Unlock()
+
 	allHealthy := true
+	e.expireApprovals()
 
-	for _, target := range e.config.GetActiveTargets() {
+	active := e.config.GetActiveTargets()
+	if e.metrics != nil {
+		names := make([]string, 0, len(active))
+		for _, target := range active {
+			names = append(names, target.Name)
+		}
+		e.metrics.RetainTargets(names)
+	}
+
+	for _, target := range active {
+		if e.checksPaused(target.Name) {
+			continue
+		}
 		healthy := e.checkTarget(ctx, target)
 		if !healthy {
 			allHealthy = false
 		}
+		e.markProgress()
 	}
 
 	// Guardar estado si está configurado
-	if e.config.StateFile != "" {
-		if err := e.SaveState(e.config.StateFile); err != nil {
-			e.logger.Error("failed to save state", logger.Fields("error", err))
-		}
-	}
+	e.persistState()
 
 	return allHealthy
 }
@@ -142,11 +356,17 @@ func (e *Engine) checkTarget(ctx context.Context, target config.Target) bool {
 	e.state.mu.Unlock()
 
 	// Crear contexto con timeout
-	checkCtx, cancel := context.WithTimeout(ctx, time.Duration(e.config.TimeoutSeconds)*time.Second)
+	checkTimeout := time.Duration(e.config.TimeoutSeconds) * time.Second
+	checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
 	defer cancel()
+	e.expectProgress(checkTimeout)
 
 	// Ejecutar todos los checks
 	allChecksPassed := true
+	buildFailed := false
+	failed := []checks.Result{}
+	degraded := []string{}
+	env := rules.Env{}
 	for _, checkCfg := range target.Checks {
 		checker, err := checks.NewChecker(checkCfg)
 		if err != nil {
@@ -154,11 +374,22 @@ func (e *Engine) checkTarget(ctx context.Context, target config.Target) bool {
 				"target", target.Name,
 				"error", err,
 			))
-			allChecksPassed = false
+			// La pasada falla aunque haya healthy_when: un check que no se puede crear no se ignora
+			buildFailed = true
+			failed = append(failed, checks.Result{
+				CheckType: checkCfg.Type,
+				Message:   fmt.Sprintf("cannot create check: %v", err),
+			})
 			continue
 		}
 
 		result := checker.Check(checkCtx)
+		if checkCfg.Name != "" {
+			env[checkCfg.Name] = ruleValues(result)
+		}
+		e.recordCheckMetrics(target.Name, checker.Name(), result)
+		e.recordCheckResult(target.Name, checker.Name(), result)
+		e.publish(checkEvent(target.Name, checker.Name(), result))
 
 		fields := logger.Fields(
 			"target", target.Name,
@@ -167,6 +398,25 @@ func (e *Engine) checkTarget(ctx context.Context, target config.Target) bool {
 			"latency_ms", result.Latency.Milliseconds(),
 		)
 
+		if result.Degraded {
+			degraded = append(degraded, result.Message)
+			e.logger.Warn("check degraded", logger.Fields(
+				"target", target.Name,
+				"check", result.CheckType,
+				"reason", result.Message,
+			))
+		}
+		if newEvents, ok := result.Details[checks.DetailNewEvents]; ok {
+			// Eventos discretos (ej: OOM-kill): se notifican aunque el servicio ya esté levantado
+			e.notify(notifications.Event{
+				Type:     "warning",
+				Target:   target.Name,
+				Message:  result.Message,
+				Severity: "warning",
+				Details:  map[string]interface{}{"check": result.CheckType, "events": newEvents},
+			})
+		}
+
 		if result.Success {
 			e.logger.Debug("check passed", fields)
 		} else {
@@ -177,20 +427,54 @@ func (e *Engine) checkTarget(ctx context.Context, target config.Target) bool {
 				"latency_ms", result.Latency.Milliseconds(),
 			))
 			allChecksPassed = false
+			failed = append(failed, result)
+		}
+	}
+
+	e.markProgress()
+
+	// healthy_when reemplaza el "todos los checks pasan" por la expresión del target
+	if target.HealthyWhen != "" {
+		healthy, err := e.evalHealthyWhen(target, env)
+		if err != nil {
+			e.logger.Error("healthy_when evaluation failed", logger.Fields(
+				"target", target.Name,
+				"error", err,
+			))
+		}
+		allChecksPassed = healthy && !buildFailed
+		if !healthy && len(failed) == 0 {
+			message := fmt.Sprintf("healthy_when not satisfied: %s", target.HealthyWhen)
+			if err != nil {
+				message = fmt.Sprintf("healthy_when: %v", err)
+			}
+			failed = append(failed, checks.Result{CheckType: "healthy_when", Message: message})
 		}
 	}
 
 	// Actualizar estado
 	e.state.mu.Lock()
 	state.LastCheckTime = time.Now()
+	state.Degraded = len(degraded) > 0
+	state.DegradedReason = strings.Join(degraded, "; ")
 
 	if allChecksPassed {
-		if !state.IsHealthy {
-			e.logger.Info("target recovered", logger.Fields("target", target.Name))
-		}
+		wasHealthy := state.IsHealthy
 		state.IsHealthy = true
 		state.ConsecutiveFailures = 0
+		state.SuppressedBy = ""
 		e.state.mu.Unlock()
+
+		if !wasHealthy {
+			e.cancelApproval(target.Name)
+			e.logger.Info("target recovered", logger.Fields("target", target.Name))
+			e.notify(notifications.Event{
+				Type:     "recovery",
+				Target:   target.Name,
+				Message:  "all checks passing again",
+				Severity: "info",
+			})
+		}
 		return true
 	}
 
@@ -206,16 +490,158 @@ func (e *Engine) checkTarget(ctx context.Context, target config.Target) bool {
 		"threshold", target.Policy.FailThreshold,
 	))
 
+	// Notificar solo la transición a unhealthy para no saturar
+	if consecutiveFailures == 1 {
+		e.notify(failureEvent(target.Name, failed))
+	}
+
 	// Decidir si ejecutar acción de recuperación
 	if consecutiveFailures >= target.Policy.FailThreshold {
-		e.executeRecoveryAction(ctx, target, state)
+		e.executeRecoveryAction(ctx, target, state, failed)
 	}
 
 	return false
 }
 
+// evalHealthyWhen evalúa la expresión healthy_when del target con los resultados de la
+// pasada. Se compila en la primera pasada del target y se reutiliza
+func (e *Engine) evalHealthyWhen(target config.Target, env rules.Env) (bool, error) {
+	expr, ok := e.healthyWhen[target.Name]
+	if !ok || expr.String() != target.HealthyWhen {
+		var err error
+		expr, err = rules.Compile(target.HealthyWhen)
+		if err != nil {
+			return false, err
+		}
+		e.healthyWhen[target.Name] = expr
+	}
+	return expr.Eval(env)
+}
+
+// ruleValues expone el resultado de un check a las expresiones de healthy_when
+func ruleValues(result checks.Result) rules.CheckValues {
+	metrics := make(map[string]float64, len(result.Metrics))
+	for _, m := range result.Metrics {
+		metrics[m.Name] = m.Value
+	}
+	return rules.CheckValues{
+		Success:   result.Success,
+		Degraded:  result.Degraded,
+		LatencyMs: float64(result.Latency.Microseconds()) / 1000,
+		Message:   result.Message,
+		Metrics:   metrics,
+	}
+}
+
+// checkEvent construye el evento con el resultado de un check (solo para suscriptores)
+func checkEvent(targetName, checkName string, result checks.Result) notifications.Event {
+	severity := "info"
+	switch {
+	case !result.Success:
+		severity = "critical"
+	case result.Degraded:
+		severity = "warning"
+	}
+
+	return notifications.Event{
+		Type:     "check",
+		Target:   targetName,
+		Message:  result.Message,
+		Severity: severity,
+		Details: map[string]interface{}{
+			"check":      checkName,
+			"check_type": result.CheckType,
+			"success":    result.Success,
+			"degraded":   result.Degraded,
+			"latency_ms": result.Latency.Milliseconds(),
+			"details":    result.Details,
+			"result":     result,
+		},
+	}
+}
+
+// failureEvent construye el evento de fallo con el detalle de cada check fallido
+func failureEvent(targetName string, failed []checks.Result) notifications.Event {
+	messages := make([]string, 0, len(failed))
+	checkDetails := make([]map[string]interface{}, 0, len(failed))
+	for _, r := range failed {
+		messages = append(messages, fmt.Sprintf("%s: %s", r.CheckType, r.Message))
+		entry := map[string]interface{}{
+			"check":   r.CheckType,
+			"message": r.Message,
+			"result":  r,
+		}
+		if len(r.Details) > 0 {
+			entry["details"] = r.Details
+		}
+		checkDetails = append(checkDetails, entry)
+	}
+
+	return notifications.Event{
+		Type:     "failure",
+		Target:   targetName,
+		Message:  strings.Join(messages, "\n"),
+		Severity: "critical",
+		Details:  map[string]interface{}{"checks": checkDetails},
+	}
+}
+
+// failureReason resume en una línea los checks fallidos (NEON_REASON de los hooks)
+func failureReason(failed []checks.Result) string {
+	messages := make([]string, 0, len(failed))
+	for _, r := range failed {
+		messages = append(messages, fmt.Sprintf("%s: %s", r.CheckType, r.Message))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// parseFailureReason reconstruye los checks fallidos a partir de failureReason
+func parseFailureReason(reason string) []checks.Result {
+	failed := []checks.Result{}
+	for _, part := range strings.Split(reason, "; ") {
+		checkType, message, _ := strings.Cut(part, ": ")
+		failed = append(failed, checks.Result{CheckType: checkType, Message: message})
+	}
+	return failed
+}
+
 // executeRecoveryAction ejecuta la acción de recuperación para un target
-func (e *Engine) executeRecoveryAction(ctx context.Context, target config.Target, state *TargetState) {
+func (e *Engine) executeRecoveryAction(ctx context.Context, target config.Target, state *TargetState, failed []checks.Result) {
+	// Durante una ventana de mantenimiento los checks siguen registrándose pero no se actúa
+	if e.recoveryPaused(target, state) || e.actionSuppressed(target, state) {
+		return
+	}
+
+	if e.recoveryBlocked(target, state) != nil {
+		return
+	}
+
+	// Crear acción
+	isFirstFailure := state.ConsecutiveFailures == target.Policy.FailThreshold
+	action, err := actions.NewAction(target.Action, isFirstFailure, e.logger)
+	if err != nil {
+		e.logger.Error("failed to create action", logger.Fields(
+			"target", target.Name,
+			"error", err,
+		))
+		return
+	}
+
+	switch e.config.ModeFor(target) {
+	case config.ModeDryRun:
+		e.dryRunAction(target, state, action, failed)
+		return
+	case config.ModeApproval:
+		e.queueAction(ctx, target, state, action, failed)
+		return
+	}
+
+	diagnosticsPath := e.collectDiagnostics(ctx, target, failed)
+	e.runAction(ctx, target, state, action, failed, diagnosticsPath)
+}
+
+// recoveryBlocked comprueba cooldown, límite de reinicios por hora y presión del host
+func (e *Engine) recoveryBlocked(target config.Target, state *TargetState) error {
 	e.state.mu.Lock()
 
 	// Verificar cooldown
@@ -224,11 +650,12 @@ func (e *Engine) executeRecoveryAction(ctx context.Context, target config.Target
 		timeSinceLastRestart := time.Since(state.LastRestartTime)
 		if timeSinceLastRestart < cooldown {
 			e.state.mu.Unlock()
+			remaining := cooldown - timeSinceLastRestart
 			e.logger.Warn("restart blocked by cooldown", logger.Fields(
 				"target", target.Name,
-				"cooldown_remaining_seconds", (cooldown-timeSinceLastRestart).Seconds(),
+				"cooldown_remaining_seconds", remaining.Seconds(),
 			))
-			return
+			return fmt.Errorf("%w by cooldown (%s remaining)", control.ErrBlocked, remaining.Round(time.Second))
 		}
 	}
 
@@ -252,37 +679,108 @@ func (e *Engine) executeRecoveryAction(ctx context.Context, target config.Target
 			"restarts_in_last_hour", len(state.RestartsInLastHour),
 			"max_restarts_per_hour", target.Policy.MaxRestartsPerHour,
 		))
-		return
+		return fmt.Errorf("%w by rate limit (%d restarts in the last hour)", control.ErrBlocked, len(state.RestartsInLastHour))
 	}
 
 	e.state.mu.Unlock()
 
-	// Crear acción
-	isFirstFailure := state.ConsecutiveFailures == target.Policy.FailThreshold
-	action, err := actions.NewAction(target.Action, isFirstFailure, e.logger)
-	if err != nil {
-		e.logger.Error("failed to create action", logger.Fields(
+	// No reiniciar mientras el host esté bajo presión (evita tormentas de reinicios)
+	if exceeded, err := checks.HostUnderPressure(target.Policy.SkipRestartUnderPressure); err != nil {
+		e.logger.Warn("cannot read host pressure", logger.Fields("target", target.Name, "error", err))
+	} else if len(exceeded) > 0 {
+		e.logger.Warn("restart skipped: host under pressure", logger.Fields(
 			"target", target.Name,
-			"error", err,
+			"metrics", strings.Join(exceeded, ", "),
 		))
-		return
+		return fmt.Errorf("%w: host under pressure (%s)", control.ErrBlocked, strings.Join(exceeded, ", "))
 	}
 
+	return nil
+}
+
+// collectDiagnostics recoge el bundle de diagnóstico antes de que el reinicio borre la evidencia
+func (e *Engine) collectDiagnostics(ctx context.Context, target config.Target, failed []checks.Result) string {
+	if target.Diagnostics == nil {
+		return ""
+	}
+	e.expectProgress(diagnostics.Timeout(target.Diagnostics))
+	defer e.markProgress()
+
+	path, err := diagnostics.Collect(ctx, target.Diagnostics, target, failed)
+	if err != nil {
+		e.logger.Warn("diagnostics collection failed", logger.Fields("target", target.Name, "error", err))
+	}
+	if path != "" {
+		e.logger.Info("diagnostics collected", logger.Fields("target", target.Name, "path", path))
+	}
+	return path
+}
+
+// runAction ejecuta la acción, publica el resultado y actualiza el estado del target
+func (e *Engine) runAction(ctx context.Context, target config.Target, state *TargetState, action actions.Action, failed []checks.Result, diagnosticsPath string) actions.Result {
+	e.state.mu.RLock()
+	consecutiveFailures := state.ConsecutiveFailures
+	e.state.mu.RUnlock()
+
 	e.logger.Info("executing recovery action", logger.Fields(
 		"target", target.Name,
 		"action", action.Name(),
-		"consecutive_failures", state.ConsecutiveFailures,
+		"consecutive_failures", consecutiveFailures,
 	))
 
-	// Ejecutar acción con timeout
-	actionCtx, cancel := context.WithTimeout(ctx, time.Duration(e.config.TimeoutSeconds)*time.Second)
+	// Ejecutar acción con timeout (incluye sus hooks)
+	actionTimeout := time.Duration(e.config.TimeoutSeconds) * time.Second
+	actionCtx, cancel := context.WithTimeout(ctx, actionTimeout)
 	defer cancel()
-
+	actionCtx = actions.WithHookContext(actionCtx, actions.HookContext{
+		Target:              target.Name,
+		Reason:              failureReason(failed),
+		ConsecutiveFailures: consecutiveFailures,
+		Diagnostics:         diagnosticsPath,
+	})
+
+	e.expectProgress(actionTimeout)
 	result := action.Execute(actionCtx)
+	e.markProgress()
+
+	severity := "info"
+	if !result.Success {
+		severity = "critical"
+	}
+	details := map[string]interface{}{
+		"action":     action.Name(),
+		"success":    result.Success,
+		"latency_ms": result.Latency.Milliseconds(),
+	}
+	if diagnosticsPath != "" {
+		details["diagnostics"] = diagnosticsPath
+	}
+	event := notifications.Event{
+		Type:     "action",
+		Target:   target.Name,
+		Message:  result.Message,
+		Severity: severity,
+		Details:  details,
+	}
+	// Con diagnósticos la acción también se notifica para indicar dónde está el bundle
+	if diagnosticsPath != "" {
+		e.notify(event)
+	} else {
+		e.publish(event)
+	}
+
+	if e.history != nil {
+		eventType := "recovery_success"
+		if !result.Success {
+			eventType = "recovery_failed"
+		}
+		e.history.RecordEvent(eventType, target.Name, result.Message, details)
+	}
 
 	// Actualizar estado
 	e.state.mu.Lock()
 	if result.Success {
+		now := time.Now()
 		state.LastRestartTime = now
 		state.RestartsInLastHour = append(state.RestartsInLastHour, now)
 		state.ConsecutiveFailures = 0 // Reset after successful restart
@@ -302,6 +800,10 @@ func (e *Engine) executeRecoveryAction(ctx context.Context, target config.Target
 			"latency_ms", result.Latency.Milliseconds(),
 		))
 	}
+
+	// El reinicio cuenta para cooldown y límite por hora: se guarda ya, no al final de la pasada
+	e.persistState()
+	return result
 }
 
 // Run ejecuta el engine en modo daemon (loop continuo)
@@ -315,19 +817,62 @@ func (e *Engine) Run(ctx context.Context) error {
 		"targets", len(e.config.GetActiveTargets()),
 	))
 
+	// Pings de heartbeat por socket Unix (heartbeat.socket_path)
+	pings := heartbeat.Default()
+	if err := pings.Start(); err != nil {
+		return err
+	}
+	defer pings.Close()
+
+	// Métricas y dashboard abren su puerto antes de retornar: un puerto ocupado hace fallar
+	// el arranque en lugar de dar el servicio por listo sin ellos
+	if e.metrics != nil {
+		if err := e.metrics.Start(); err != nil {
+			return err
+		}
+		defer e.metrics.Close()
+	}
+	if e.dashboard != nil {
+		if err := e.dashboard.Start(); err != nil {
+			return err
+		}
+		defer e.dashboard.Close()
+	}
+
 	ticker := time.NewTicker(time.Duration(e.config.IntervalSeconds) * time.Second)
 	defer ticker.Stop()
 
+	// Con Type=notify systemd da el servicio por arrancado aquí: la configuración está
+	// cargada y todos los listeners configurados (socket de control, heartbeat, métricas y
+	// dashboard) ya escuchan
+	e.sdNotify(sdnotify.Ready, sdnotify.Status("running first check pass"))
+
+	// Entre pasadas el bucle marca progreso al ritmo del watchdog de systemd
+	var keepalive <-chan time.Time
+	if interval := e.startSystemdWatchdog(ctx); interval > 0 {
+		progressTicker := time.NewTicker(interval)
+		defer progressTicker.Stop()
+		keepalive = progressTicker.C
+	}
+
 	// Primera ejecución inmediata
 	e.CheckOnce(ctx)
+	e.sdNotify(sdnotify.Status(e.statusSummary()))
 
 	for {
 		select {
 		case <-ctx.Done():
+			e.sdNotify(sdnotify.Stopping, sdnotify.Status("stopping"))
+			e.cancelApproved()
+			e.approved.Wait()
+			e.persistState()
 			e.logger.Info("watchdog stopped", logger.Fields("reason", ctx.Err()))
 			return ctx.Err()
+		case <-keepalive:
+			e.markProgress()
 		case <-ticker.C:
 			e.CheckOnce(ctx)
+			e.sdNotify(sdnotify.Status(e.statusSummary()))
 		}
 	}
 }
@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/heartbeat"
+	"github.com/tgextreme/neon-watchdog/internal/instance"
+	"github.com/tgextreme/neon-watchdog/internal/logger"
+)
+
+// registerHeartbeats da de alta los tokens de heartbeat para aceptar pings desde el arranque
+func registerHeartbeats(store *heartbeat.Store, checkList []config.Check) {
+	for _, check := range checkList {
+		if check.Type == "heartbeat" && check.Heartbeat != nil {
+			store.Register(check.Heartbeat.Token)
+		}
+		registerHeartbeats(store, check.Checks)
+	}
+}
+
+// Ping registra un ping de heartbeat (`neon-watchdog ping <token> [start|success|fail|<exit_code>]`).
+// Con el daemon en marcha se envía por heartbeat.socket_path; si no hay daemon se guarda en
+// el state file de heartbeats con el bloqueo de instancia tomado, y lo ve el siguiente check
+// del timer
+func Ping(cfg *config.Config, token, suffix, body string, log *logger.Logger) error {
+	kind, exitCode, err := heartbeat.ParsePing(suffix)
+	if err != nil {
+		return err
+	}
+
+	if socket := cfg.HeartbeatSocket(); socket != "" {
+		err := heartbeat.SendPing(socket, token, suffix, body)
+		// Socket inexistente o sin nadie escuchando: no hay daemon
+		if err == nil || !(errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ECONNREFUSED)) {
+			return err
+		}
+	}
+
+	stateFile := cfg.HeartbeatStateFile()
+	if stateFile == "" {
+		return fmt.Errorf("no daemon listening and no heartbeat state file configured")
+	}
+
+	lock, err := instance.AcquireWait(cfg.LockFile(), instance.ModeCheck, lockWait)
+	if err != nil {
+		return fmt.Errorf("cannot record ping (set heartbeat.socket_path to ping the daemon): %w", err)
+	}
+	defer lock.Release()
+
+	store := heartbeat.NewStore(&config.HeartbeatConfig{StateFile: stateFile}, log)
+	for _, target := range cfg.GetActiveTargets() {
+		registerHeartbeats(store, target.Checks)
+	}
+	return store.Record(token, kind, exitCode, body)
+}
@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/instance"
+	"github.com/tgextreme/neon-watchdog/internal/logger"
+)
+
+const (
+	// lockWait tiempo que el daemon espera a que termine un check puntual en curso
+	lockWait = time.Minute
+	// delegateTimeout tiempo máximo de una pasada pedida al daemon por el socket de control
+	delegateTimeout = 5 * time.Minute
+)
+
+// RunExclusive ejecuta el daemon con el bloqueo de instancia única y atiende el socket de
+// control. Falla si ya hay otro daemon; si hay un check puntual en curso espera a que termine
+func (e *Engine) RunExclusive(ctx context.Context) error {
+	lock, err := instance.AcquireWait(e.config.LockFile(), instance.ModeDaemon, lockWait)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+	e.logPreviousHolder(lock)
+
+	// Con el bloqueo tomado nadie más escribe el estado: se carga el último guardado
+	if err := e.Restore(true); err != nil {
+		return err
+	}
+
+	server, err := instance.Serve(e.config.ControlSocket(), e.controlCommand(ctx), e.logger)
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+
+	return e.Run(ctx)
+}
+
+// CheckOnceExclusive ejecuta una pasada puntual (timer) con el bloqueo de instancia única.
+// Si el bloqueo lo tiene un daemon, según instance.on_locked le pide la pasada por el
+// socket de control o termina sin hacer nada; si lo tiene otro check, termina
+func (e *Engine) CheckOnceExclusive(ctx context.Context) (bool, error) {
+	lock, err := instance.Acquire(e.config.LockFile(), instance.ModeCheck)
+
+	var locked *instance.LockedError
+	if errors.As(err, &locked) && !locked.Stale {
+		if locked.Holder.Mode == instance.ModeDaemon && e.config.OnLocked() == config.OnLockedDelegate {
+			return e.delegateCheck(locked.Holder)
+		}
+		e.logger.Info("another instance is running, skipping check", logger.Fields(
+			"pid", locked.Holder.PID,
+			"mode", locked.Holder.Mode,
+		))
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer lock.Release()
+	e.logPreviousHolder(lock)
+
+	// Sin daemon no hay quien apruebe las acciones
+	if err := e.config.ValidateOneshot(); err != nil {
+		return false, err
+	}
+
+	if err := e.Restore(false); err != nil {
+		return false, err
+	}
+	return e.CheckOnce(ctx), nil
+}
+
+// delegateCheck pide al daemon una pasada por el socket de control
+func (e *Engine) delegateCheck(daemon instance.Holder) (bool, error) {
+	e.logger.Info("daemon is running, delegating check", logger.Fields("pid", daemon.PID))
+
+	reply, err := instance.Request(e.config.ControlSocket(), "check", delegateTimeout)
+	if err != nil {
+		return false, fmt.Errorf("daemon (pid %d) holds the lock but did not run the check: %w", daemon.PID, err)
+	}
+	return reply == "healthy", nil
+}
+
+// controlCommand atiende los comandos del socket de control: ping y check
+func (e *Engine) controlCommand(ctx context.Context) instance.Handler {
+	return func(command string) (string, error) {
+		switch command {
+		case "ping":
+			return fmt.Sprintf("pong %d", os.Getpid()), nil
+		case "check":
+			e.logger.Info("check requested via control socket", nil)
+			if e.CheckOnce(ctx) {
+				return "healthy", nil
+			}
+			return "unhealthy", nil
+		}
+		return "", fmt.Errorf("unknown command '%s' (must be: ping, check)", command)
+	}
+}
+
+// logPreviousHolder avisa si la instancia anterior terminó sin liberar el bloqueo
+func (e *Engine) logPreviousHolder(lock *instance.Lock) {
+	if lock.Previous == nil {
+		return
+	}
+	e.logger.Warn("stale lock taken over: previous instance did not exit cleanly", logger.Fields(
+		"pid", lock.Previous.PID,
+		"mode", lock.Previous.Mode,
+		"since", lock.Previous.Since.Format(time.RFC3339),
+	))
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/logger"
+	"github.com/tgextreme/neon-watchdog/internal/maintenance"
+	"github.com/tgextreme/neon-watchdog/internal/notifications"
+)
+
+// Maintenance retorna las ventanas de mantenimiento y los silencios activos
+func (e *Engine) Maintenance() *maintenance.Manager {
+	return e.maintenance
+}
+
+// actionSuppressed indica si una ventana o silencio suprime la acción de recuperación.
+// Solo se registra y publica el primer intento suprimido por cada ventana o silencio
+func (e *Engine) actionSuppressed(target config.Target, state *TargetState) bool {
+	match, ok := e.maintenance.Suppressed(target, config.SuppressActions, time.Now())
+
+	e.state.mu.Lock()
+	previous := state.SuppressedBy
+	state.SuppressedBy = ""
+	if ok {
+		state.SuppressedBy = match.Kind + ":" + match.ID
+	}
+	e.state.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	if previous != state.SuppressedBy {
+		e.logger.Info("recovery action suppressed by maintenance", logger.Fields(
+			"target", target.Name,
+			match.Kind, match.ID,
+			"until", match.Until.Format(time.RFC3339),
+		))
+		message := fmt.Sprintf("recovery action suppressed by %s %s until %s",
+			match.Kind, match.ID, match.Until.Format(time.RFC3339))
+		e.publish(notifications.Event{
+			Type:     "maintenance",
+			Target:   target.Name,
+			Message:  message,
+			Severity: "info",
+			Details:  map[string]interface{}{"maintenance": match},
+		})
+		if e.history != nil {
+			e.history.RecordEvent("recovery_suppressed", target.Name, message, map[string]interface{}{"maintenance": match})
+		}
+	}
+	return true
+}
+
+// notificationSuppressed indica si las notificaciones del target están silenciadas
+func (e *Engine) notificationSuppressed(targetName string) (maintenance.Match, bool) {
+	for _, target := range e.config.Targets {
+		if target.Name == targetName {
+			return e.maintenance.Suppressed(target, config.SuppressNotifications, time.Now())
+		}
+	}
+	return maintenance.Match{}, false
+}
@@ -0,0 +1,300 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/actions"
+	"github.com/tgextreme/neon-watchdog/internal/approval"
+	"github.com/tgextreme/neon-watchdog/internal/checks"
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/logger"
+	"github.com/tgextreme/neon-watchdog/internal/notifications"
+)
+
+// pendingAction lo necesario para ejecutar una acción cuando se apruebe
+type pendingAction struct {
+	target      config.Target
+	action      actions.Action
+	failed      []checks.Result
+	diagnostics string
+}
+
+// Approvals retorna la cola de acciones pendientes de aprobación (mode: approval)
+func (e *Engine) Approvals() *approval.Queue {
+	return e.approvals
+}
+
+// dryRunAction registra y notifica la acción que se habría ejecutado. Aplica el cooldown y
+// el límite por hora sobre las acciones simuladas, para que las notificaciones reflejen lo
+// que haría el modo enforce, pero sin tocar el estado real: al pasar a enforce no hay
+// cooldown ni reinicios gastados por acciones que nunca se ejecutaron
+func (e *Engine) dryRunAction(target config.Target, state *TargetState, action actions.Action, failed []checks.Result) {
+	now := time.Now()
+	e.state.mu.Lock()
+	recent := state.dryRuns[:0]
+	for _, t := range state.dryRuns {
+		if now.Sub(t) < time.Hour {
+			recent = append(recent, t)
+		}
+	}
+	state.dryRuns = recent
+
+	cooldown := time.Duration(target.Policy.RestartCooldownSeconds) * time.Second
+	inCooldown := len(recent) > 0 && now.Sub(recent[len(recent)-1]) < cooldown
+	if inCooldown || len(recent) >= target.Policy.MaxRestartsPerHour {
+		e.state.mu.Unlock()
+		e.logger.Debug("dry run: recovery action would be blocked by cooldown or rate limit", logger.Fields(
+			"target", target.Name,
+			"simulated_actions_last_hour", len(recent),
+		))
+		return
+	}
+	state.dryRuns = append(state.dryRuns, now)
+	consecutiveFailures := state.ConsecutiveFailures
+	e.state.mu.Unlock()
+
+	reason := failureReason(failed)
+	e.logger.Warn("dry run: recovery action not executed", logger.Fields(
+		"target", target.Name,
+		"action", action.Name(),
+		"consecutive_failures", consecutiveFailures,
+	))
+
+	details := map[string]interface{}{
+		"action":               action.Name(),
+		"dry_run":              true,
+		"reason":               reason,
+		"consecutive_failures": consecutiveFailures,
+	}
+	message := fmt.Sprintf("dry run: would execute %s", action.Name())
+	e.notify(notifications.Event{
+		Type:     "action",
+		Target:   target.Name,
+		Message:  message,
+		Severity: "warning",
+		Details:  details,
+	})
+	if e.history != nil {
+		e.history.RecordEvent("recovery_dry_run", target.Name, message, details)
+	}
+}
+
+// queueAction encola la acción hasta que un operador la apruebe o venza el plazo.
+// Los diagnósticos se recogen al encolar, cuando la evidencia todavía existe
+func (e *Engine) queueAction(ctx context.Context, target config.Target, state *TargetState, action actions.Action, failed []checks.Result) {
+	if pending, ok := e.approvals.Pending(target.Name); ok {
+		e.logger.Debug("recovery action already awaiting approval or running", logger.Fields(
+			"target", target.Name,
+			"id", pending.ID,
+			"status", pending.Status,
+		))
+		return
+	}
+
+	e.state.mu.RLock()
+	consecutiveFailures := state.ConsecutiveFailures
+	e.state.mu.RUnlock()
+
+	diagnosticsPath := e.collectDiagnostics(ctx, target, failed)
+	timeout := time.Duration(target.Policy.ApprovalTimeoutSeconds) * time.Second
+	queued, _ := e.approvals.Add(approval.Action{
+		Target:              target.Name,
+		Action:              action.Name(),
+		Reason:              failureReason(failed),
+		ConsecutiveFailures: consecutiveFailures,
+		Diagnostics:         diagnosticsPath,
+	}, timeout)
+
+	e.pendingMu.Lock()
+	e.pending[queued.ID] = pendingAction{
+		target:      target,
+		action:      action,
+		failed:      failed,
+		diagnostics: diagnosticsPath,
+	}
+	e.pendingMu.Unlock()
+
+	e.logger.Warn("recovery action awaiting approval", logger.Fields(
+		"target", target.Name,
+		"action", action.Name(),
+		"id", queued.ID,
+		"expires_at", queued.ExpiresAt.Format(time.RFC3339),
+	))
+
+	message := fmt.Sprintf("recovery action %s awaiting approval (id %s, expires %s)",
+		action.Name(), queued.ID, queued.ExpiresAt.Format(time.RFC3339))
+	e.notify(approvalEvent(queued, message, "warning"))
+	if e.history != nil {
+		e.history.RecordEvent("recovery_pending", target.Name, message, map[string]interface{}{"approval": queued})
+	}
+}
+
+// handleApproval ejecuta las acciones aprobadas y registra las rechazadas
+func (e *Engine) handleApproval(decided approval.Action) {
+	e.pendingMu.Lock()
+	pending, ok := e.pending[decided.ID]
+	delete(e.pending, decided.ID)
+	e.pendingMu.Unlock()
+
+	if decided.Status == approval.StatusRejected {
+		e.logger.Info("recovery action rejected", logger.Fields(
+			"target", decided.Target,
+			"id", decided.ID,
+			"by", decided.DecidedBy,
+		))
+		message := fmt.Sprintf("recovery action %s rejected by %s", decided.Action, decided.DecidedBy)
+		e.publish(approvalEvent(decided, message, "info"))
+		if e.history != nil {
+			e.history.RecordEvent("recovery_rejected", decided.Target, message, map[string]interface{}{"approval": decided})
+		}
+		return
+	}
+
+	if !ok {
+		e.approvals.Finish(decided.ID, false, "action no longer available")
+		return
+	}
+
+	e.logger.Info("recovery action approved", logger.Fields(
+		"target", decided.Target,
+		"id", decided.ID,
+		"by", decided.DecidedBy,
+	))
+
+	// La acción se ejecuta fuera de la petición del operador; el resultado queda en la cola
+	e.approved.Add(1)
+	go func() {
+		defer e.approved.Done()
+		e.runApproved(decided.ID, pending)
+	}()
+}
+
+// runApproved ejecuta una acción aprobada como lo haría una pasada: bajo runMu, con el
+// contexto del engine y comprobando otra vez pausas, mantenimiento, cooldown y límite por
+// hora, que pueden haber cambiado mientras esperaba la decisión
+func (e *Engine) runApproved(id string, pending pendingAction) {
+	e.runMu.Lock()
+	defer e.runMu.Unlock()
+
+	target := pending.target
+	e.state.mu.RLock()
+	state := e.state.Targets[target.Name]
+	e.state.mu.RUnlock()
+
+	skipped := ""
+	switch {
+	case e.approvedCtx.Err() != nil:
+		skipped = "watchdog stopping"
+	case state == nil:
+		skipped = "target no longer exists"
+	case e.recoveryPaused(target, state):
+		skipped = "target paused"
+	case e.actionSuppressed(target, state):
+		skipped = "suppressed by maintenance"
+	default:
+		if err := e.recoveryBlocked(target, state); err != nil {
+			skipped = err.Error()
+		}
+	}
+	if skipped != "" {
+		e.logger.Warn("approved recovery action not executed", logger.Fields(
+			"target", target.Name,
+			"id", id,
+			"reason", skipped,
+		))
+		e.approvals.Finish(id, false, "not executed: "+skipped)
+		return
+	}
+
+	result := e.runAction(e.approvedCtx, target, state, pending.action, pending.failed, pending.diagnostics)
+	e.approvals.Finish(id, result.Success, result.Message)
+}
+
+// restorePending prepara la ejecución de las acciones pendientes de la cola guardada. Las
+// de targets que ya no existen o que han dejado de estar en modo approval se cancelan
+func (e *Engine) restorePending() {
+	targets := make(map[string]config.Target)
+	for _, target := range e.config.GetActiveTargets() {
+		targets[target.Name] = target
+	}
+
+	for _, queued := range e.approvals.List() {
+		if queued.Status != approval.StatusPending {
+			continue
+		}
+
+		target, ok := targets[queued.Target]
+		if !ok || e.config.ModeFor(target) != config.ModeApproval {
+			e.approvals.CancelTarget(queued.Target, "target no longer in approval mode")
+			continue
+		}
+		action, err := actions.NewAction(target.Action, false, e.logger)
+		if err != nil {
+			e.approvals.CancelTarget(queued.Target, fmt.Sprintf("failed to create action: %v", err))
+			continue
+		}
+
+		e.pending[queued.ID] = pendingAction{
+			target:      target,
+			action:      action,
+			failed:      parseFailureReason(queued.Reason),
+			diagnostics: queued.Diagnostics,
+		}
+	}
+}
+
+// expireApprovals marca como vencidas las acciones sin decisión y lo notifica
+func (e *Engine) expireApprovals() {
+	for _, expired := range e.approvals.Expire() {
+		e.pendingMu.Lock()
+		delete(e.pending, expired.ID)
+		e.pendingMu.Unlock()
+
+		e.logger.Warn("recovery action approval expired", logger.Fields(
+			"target", expired.Target,
+			"id", expired.ID,
+		))
+		message := fmt.Sprintf("recovery action %s expired without approval", expired.Action)
+		e.notify(approvalEvent(expired, message, "warning"))
+		if e.history != nil {
+			e.history.RecordEvent("recovery_expired", expired.Target, message, map[string]interface{}{"approval": expired})
+		}
+	}
+}
+
+// cancelApproval descarta la acción pendiente de un target que se ha recuperado
+func (e *Engine) cancelApproval(targetName string) {
+	cancelled, ok := e.approvals.CancelTarget(targetName, "target recovered")
+	if !ok {
+		return
+	}
+
+	e.pendingMu.Lock()
+	delete(e.pending, cancelled.ID)
+	e.pendingMu.Unlock()
+
+	e.logger.Info("pending recovery action cancelled: target recovered", logger.Fields(
+		"target", targetName,
+		"id", cancelled.ID,
+	))
+	e.publish(approvalEvent(cancelled, fmt.Sprintf("recovery action %s cancelled: target recovered", cancelled.Action), "info"))
+}
+
+// approvalEvent construye el evento de una acción de la cola de aprobación
+func approvalEvent(a approval.Action, message, severity string) notifications.Event {
+	return notifications.Event{
+		Type:     "approval",
+		Target:   a.Target,
+		Message:  message,
+		Severity: severity,
+		Details: map[string]interface{}{
+			"id":         a.ID,
+			"action":     a.Action,
+			"status":     a.Status,
+			"expires_at": a.ExpiresAt,
+			"approval":   a,
+		},
+	}
+}
@@ -0,0 +1,185 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/atomicfile"
+	"github.com/tgextreme/neon-watchdog/internal/logger"
+)
+
+// stateVersion versión actual del formato del state file
+const stateVersion = 2
+
+// errStateTooNew el state file lo escribió una versión posterior del watchdog. No se usa la
+// copia .bak: guardar encima perdería el estado de la versión nueva
+var errStateTooNew = errors.New("state file is newer than supported")
+
+// stateMigrations migraciones del state file: la posición i pasa de la versión i+1 a la i+2.
+// Trabajan sobre el JSON genérico para no depender de los tipos actuales
+var stateMigrations = []func(state map[string]interface{}) error{
+	migrateStateV1,
+}
+
+// stateFile formato en disco del estado
+type stateFile struct {
+	Version int                     `json:"version"`
+	SavedAt time.Time               `json:"saved_at"`
+	Targets map[string]*TargetState `json:"targets"`
+}
+
+// migrateStateV1 los state files sin versión podían guardar restarts_in_last_hour a null
+func migrateStateV1(state map[string]interface{}) error {
+	targets, _ := state["targets"].(map[string]interface{})
+	for name, raw := range targets {
+		target, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("target %s: unexpected format", name)
+		}
+		if target["restarts_in_last_hour"] == nil {
+			target["restarts_in_last_hour"] = []interface{}{}
+		}
+	}
+	return nil
+}
+
+// LoadState carga el estado desde un archivo. Si está corrupto se usa la copia .bak y el
+// archivo dañado se conserva como .corrupt; si es de una versión posterior falla sin tocarlo.
+// Se descarta el estado de targets que ya no están en la configuración
+func (e *Engine) LoadState(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	file, err := readStateFile(path)
+	if errors.Is(err, errStateTooNew) {
+		return fmt.Errorf("error loading state file %s: %w", path, err)
+	}
+	if err != nil {
+		backup, bakErr := readStateFile(path + ".bak")
+		switch {
+		case errors.Is(err, os.ErrNotExist) && errors.Is(bakErr, os.ErrNotExist):
+			return nil // No es un error si no existe
+		case bakErr != nil:
+			return fmt.Errorf("error loading state file: %w", err)
+		}
+
+		e.logger.Warn("state file unreadable, restored from backup", logger.Fields("path", path, "error", err))
+		if !errors.Is(err, os.ErrNotExist) {
+			if renameErr := os.Rename(path, path+".corrupt"); renameErr != nil {
+				e.logger.Warn("cannot keep corrupt state file", logger.Fields("error", renameErr))
+			}
+		}
+		file = backup
+	}
+
+	configured := make(map[string]bool, len(e.config.Targets))
+	for _, target := range e.config.Targets {
+		configured[target.Name] = true
+	}
+
+	e.state.mu.Lock()
+	defer e.state.mu.Unlock()
+
+	for name, target := range file.Targets {
+		if !configured[name] || target == nil {
+			e.logger.Info("dropping state of removed target", logger.Fields("target", name))
+			continue
+		}
+		target.Name = name
+		if target.RestartsInLastHour == nil {
+			target.RestartsInLastHour = []time.Time{}
+		}
+		e.state.Targets[name] = target
+	}
+
+	e.logger.Info("state loaded from file", logger.Fields("path", path, "version", file.Version))
+	return nil
+}
+
+// readStateFile lee, migra a la versión actual y decodifica un state file
+func readStateFile(path string) (*stateFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("error parsing state file: %w", err)
+	}
+
+	// Los archivos sin campo version son de la versión 1
+	version := 1
+	if v, ok := raw["version"].(float64); ok {
+		version = int(v)
+	}
+	if version > stateVersion {
+		return nil, fmt.Errorf("%w: version %d, supported %d", errStateTooNew, version, stateVersion)
+	}
+	for ; version < stateVersion; version++ {
+		if err := stateMigrations[version-1](raw); err != nil {
+			return nil, fmt.Errorf("error migrating state file from version %d: %w", version, err)
+		}
+	}
+	raw["version"] = stateVersion
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error migrating state file: %w", err)
+	}
+	var file stateFile
+	if err := json.Unmarshal(migrated, &file); err != nil {
+		return nil, fmt.Errorf("error parsing state file: %w", err)
+	}
+	return &file, nil
+}
+
+// SaveState guarda el estado de forma atómica conservando la versión anterior como .bak. Si el contenido no ha cambiado no se escribe
+func (e *Engine) SaveState(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	e.saveMu.Lock()
+	defer e.saveMu.Unlock()
+
+	targets := e.state.snapshot()
+	if e.savedPath == path && e.savedTargets != nil && slices.Equal(targets, e.savedTargets) {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(struct {
+		Version int             `json:"version"`
+		SavedAt time.Time       `json:"saved_at"`
+		Targets json.RawMessage `json:"targets"`
+	}{stateVersion, time.Now(), targets}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling state: %w", err)
+	}
+
+	if err := atomicfile.Write(path, data, 0644, atomicfile.WithBackup()); err != nil {
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+	e.savedPath, e.savedTargets = path, targets
+	return nil
+}
+
+// snapshot serializa los targets bajo el lock para no leer un TargetState a medio actualizar
+func (s *State) snapshot() json.RawMessage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, _ := json.Marshal(s.Targets)
+	return data
+}
+
+// persistState guarda el estado tras un cambio para que sobreviva a un reinicio
+func (e *Engine) persistState() {
+	if err := e.SaveState(e.config.StateFile); err != nil {
+		e.logger.Error("failed to save state", logger.Fields("error", err))
+	}
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/logger"
+	"github.com/tgextreme/neon-watchdog/internal/sdnotify"
+)
+
+// sdNotify envía mensajes a systemd si el daemon corre como unidad Type=notify
+func (e *Engine) sdNotify(messages ...string) {
+	if _, err := sdnotify.Notify(messages...); err != nil {
+		e.logger.Warn("failed to notify systemd", logger.Fields("error", err))
+	}
+}
+
+// markProgress registra que el bucle de scheduling avanza (lo comprueba el watchdog de systemd)
+func (e *Engine) markProgress() {
+	e.progress.Store(time.Now().UnixNano())
+}
+
+// expectProgress marca progreso antes de una operación acotada por timeout (checks de un
+// target, diagnóstico, acción con sus hooks): el bucle se considera vivo mientras dure ese
+// plazo y solo se da por bloqueado si la operación lo supera
+func (e *Engine) expectProgress(timeout time.Duration) {
+	e.progress.Store(time.Now().Add(timeout).UnixNano())
+}
+
+// statusSummary resumen para STATUS=: targets activos, caídos y pausados
+func (e *Engine) statusSummary() string {
+	active := e.config.GetActiveTargets()
+
+	e.state.mu.RLock()
+	defer e.state.mu.RUnlock()
+
+	unhealthy, paused := 0, 0
+	for _, target := range active {
+		state := e.state.Targets[target.Name]
+		if state == nil {
+			continue
+		}
+		if !state.IsHealthy {
+			unhealthy++
+		}
+		if state.Pause != nil {
+			paused++
+		}
+	}
+
+	summary := fmt.Sprintf("%d targets, %d unhealthy", len(active), unhealthy)
+	if paused > 0 {
+		summary += fmt.Sprintf(", %d paused", paused)
+	}
+	return summary
+}
+
+// startSystemdWatchdog envía WATCHDOG=1 a la mitad de WatchdogSec mientras el bucle de
+// scheduling haya avanzado dentro de ese intervalo: así el último ping enviado siempre deja
+// margen antes de que venza WatchdogSec. Si el engine se bloquea deja de enviarlos y systemd
+// mata y reinicia el daemon. Retorna el intervalo con el que debe marcar progreso el bucle
+// entre pasadas (0 si la unidad no tiene WatchdogSec)
+func (e *Engine) startSystemdWatchdog(ctx context.Context) time.Duration {
+	timeout, ok := sdnotify.WatchdogInterval()
+	if !ok {
+		return 0
+	}
+	interval := timeout / 2
+
+	e.logger.Info("systemd watchdog enabled", logger.Fields("watchdog_sec", timeout.Seconds()))
+	e.markProgress()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		stalled := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			since := time.Since(time.Unix(0, e.progress.Load()))
+			if since > interval {
+				// Solo se avisa una vez por bloqueo: systemd matará el proceso enseguida
+				if !stalled {
+					e.logger.Error("scheduling loop stalled, withholding systemd watchdog ping", logger.Fields(
+						"stalled_seconds", since.Seconds(),
+					))
+				}
+				stalled = true
+				continue
+			}
+			stalled = false
+			e.sdNotify(sdnotify.Watchdog)
+		}
+	}()
+	// El bucle marca progreso al doble de ritmo que se comprueba para no rozar el límite
+	return interval / 2
+}
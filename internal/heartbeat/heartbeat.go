@@ -0,0 +1,376 @@
+package heartbeat
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/logger"
+)
+
+// Tipos de ping aceptados
+const (
+	KindStart   = "start"
+	KindSuccess = "success"
+	KindFail    = "fail"
+)
+
+// MaxBodySize tamaño máximo del cuerpo guardado por ping
+const MaxBodySize = 10 * 1024
+
+// ErrUnknownToken se devuelve cuando el token no corresponde a ningún check configurado
+var ErrUnknownToken = errors.New("unknown heartbeat token")
+
+// Status estado acumulado de los pings de un token
+type Status struct {
+	Token        string    `json:"token"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastPing     time.Time `json:"last_ping,omitempty"`
+	LastStart    time.Time `json:"last_start,omitempty"`
+	LastSuccess  time.Time `json:"last_success,omitempty"`
+	LastFail     time.Time `json:"last_fail,omitempty"`
+	LastKind     string    `json:"last_kind,omitempty"`
+	LastExitCode int       `json:"last_exit_code"`
+	LastBody     string    `json:"last_body,omitempty"`
+	Running      bool      `json:"running"`
+}
+
+// Store guarda los pings recibidos por HTTP, socket Unix o CLI
+type Store struct {
+	mu         sync.RWMutex
+	entries    map[string]*Status
+	stateFile  string
+	socketPath string
+	listener   net.Listener
+	log        *logger.Logger
+}
+
+var (
+	defaultMu    sync.RWMutex
+	defaultStore = NewStore(nil, nil)
+)
+
+// Default retorna el store compartido por checkers y dashboard
+func Default() *Store {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultStore
+}
+
+// Configure crea el store compartido a partir de la configuración
+func Configure(cfg *config.HeartbeatConfig, log *logger.Logger) *Store {
+	s := NewStore(cfg, log)
+
+	defaultMu.Lock()
+	defaultStore = s
+	defaultMu.Unlock()
+
+	return s
+}
+
+// NewStore crea un nuevo store de heartbeats
+func NewStore(cfg *config.HeartbeatConfig, log *logger.Logger) *Store {
+	if cfg == nil {
+		cfg = &config.HeartbeatConfig{}
+	}
+	if log == nil {
+		log = logger.New("ERROR", os.Stderr)
+	}
+
+	s := &Store{
+		entries:    make(map[string]*Status),
+		stateFile:  cfg.StateFile,
+		socketPath: cfg.SocketPath,
+		log:        log,
+	}
+
+	if err := s.Load(); err != nil {
+		log.Warn("failed to load heartbeat state", logger.Fields("error", err.Error()))
+	}
+
+	return s
+}
+
+// Register da de alta un token; los pings de tokens no registrados se rechazan.
+// El alta se guarda para que FirstSeen se conserve entre ejecuciones del timer
+func (s *Store) Register(token string) {
+	s.mu.Lock()
+	_, ok := s.entries[token]
+	if !ok {
+		s.entries[token] = &Status{Token: token, FirstSeen: time.Now()}
+	}
+	s.mu.Unlock()
+
+	if ok {
+		return
+	}
+	if err := s.Save(); err != nil {
+		s.log.Error("failed to save heartbeat state", logger.Fields("error", err.Error()))
+	}
+}
+
+// Get retorna una copia del estado de un token
+func (s *Store) Get(token string) (Status, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st, ok := s.entries[token]
+	if !ok {
+		return Status{}, false
+	}
+	return *st, true
+}
+
+// Record registra un ping. Un success con exit code distinto de 0 cuenta como fail
+func (s *Store) Record(token, kind string, exitCode int, body string) error {
+	if kind == KindSuccess && exitCode != 0 {
+		kind = KindFail
+	}
+	if kind != KindStart && kind != KindSuccess && kind != KindFail {
+		return fmt.Errorf("invalid ping kind: %s", kind)
+	}
+	if len(body) > MaxBodySize {
+		body = body[:MaxBodySize]
+	}
+
+	s.mu.Lock()
+	st, ok := s.entries[token]
+	if !ok {
+		s.mu.Unlock()
+		return ErrUnknownToken
+	}
+
+	now := time.Now()
+	st.LastPing = now
+	st.LastKind = kind
+	switch kind {
+	case KindStart:
+		st.LastStart = now
+		st.Running = true
+	case KindSuccess:
+		st.LastSuccess = now
+		st.LastExitCode = exitCode
+		st.LastBody = body
+		st.Running = false
+	case KindFail:
+		st.LastFail = now
+		st.LastExitCode = exitCode
+		st.LastBody = body
+		st.Running = false
+	}
+	s.mu.Unlock()
+
+	s.log.Debug("heartbeat ping received", logger.Fields(
+		"token", token,
+		"kind", kind,
+		"exit_code", exitCode,
+	))
+
+	if err := s.Save(); err != nil {
+		s.log.Error("failed to save heartbeat state", logger.Fields("error", err.Error()))
+	}
+	return nil
+}
+
+// ParsePing interpreta el sufijo de un ping: "", start, success, fail o un exit code
+func ParsePing(suffix string) (kind string, exitCode int, err error) {
+	switch suffix {
+	case "", KindSuccess:
+		return KindSuccess, 0, nil
+	case KindStart:
+		return KindStart, 0, nil
+	case KindFail:
+		return KindFail, 1, nil
+	}
+
+	code, convErr := strconv.Atoi(suffix)
+	if convErr != nil || code < 0 || code > 255 {
+		return "", 0, fmt.Errorf("invalid ping suffix: %s", suffix)
+	}
+	if code == 0 {
+		return KindSuccess, 0, nil
+	}
+	return KindFail, code, nil
+}
+
+// Save persiste el estado de los heartbeats al disco
+func (s *Store) Save() error {
+	if s.stateFile == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal heartbeat state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.stateFile), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmpFile := s.stateFile + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := os.Rename(tmpFile, s.stateFile); err != nil {
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	return nil
+}
+
+// Load carga el estado de los heartbeats del disco
+func (s *Store) Load() error {
+	if s.stateFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.stateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read heartbeat state: %w", err)
+	}
+
+	entries := make(map[string]*Status)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to unmarshal heartbeat state: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for token, st := range entries {
+		if st != nil {
+			s.entries[token] = st
+		}
+	}
+	return nil
+}
+
+// Start inicia el listener del socket Unix si está configurado
+func (s *Store) Start() error {
+	if s.socketPath == "" {
+		return nil
+	}
+
+	// Eliminar socket huérfano de una ejecución anterior
+	os.Remove(s.socketPath)
+
+	ln, err := net.Listen("unix", s.socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on heartbeat socket: %w", err)
+	}
+	os.Chmod(s.socketPath, 0660)
+	s.listener = ln
+
+	s.log.Info("heartbeat socket listening", logger.Fields("path", s.socketPath))
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					s.log.Error("heartbeat socket failed", logger.Fields("error", err.Error()))
+				}
+				return
+			}
+			go s.handleConn(conn)
+		}
+	}()
+
+	return nil
+}
+
+// Close cierra el listener del socket Unix y borra el archivo
+func (s *Store) Close() error {
+	if s.listener == nil {
+		return nil
+	}
+	err := s.listener.Close()
+	os.Remove(s.socketPath)
+	s.listener = nil
+	return err
+}
+
+// handleConn procesa un ping por socket.
+// Protocolo: una línea "<token> [start|success|fail|<exit_code>]" seguida opcionalmente del cuerpo
+func (s *Store) handleConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintf(conn, "ERR %v\n", err)
+		return
+	}
+
+	parts := strings.Fields(line)
+	if len(parts) == 0 || len(parts) > 2 {
+		fmt.Fprintln(conn, "ERR expected: <token> [start|success|fail|<exit_code>]")
+		return
+	}
+
+	suffix := ""
+	if len(parts) == 2 {
+		suffix = parts[1]
+	}
+	kind, exitCode, err := ParsePing(suffix)
+	if err != nil {
+		fmt.Fprintf(conn, "ERR %v\n", err)
+		return
+	}
+
+	body := make([]byte, MaxBodySize)
+	n, _ := io.ReadFull(reader, body)
+
+	if err := s.Record(parts[0], kind, exitCode, string(body[:n])); err != nil {
+		fmt.Fprintf(conn, "ERR %v\n", err)
+		return
+	}
+	fmt.Fprintln(conn, "OK")
+}
+
+// SendPing envía un ping al socket Unix del daemon (usado por `neon-watchdog ping`)
+func SendPing(socketPath, token, suffix, body string) error {
+	if _, _, err := ParsePing(suffix); err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("unix", socketPath, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to heartbeat socket: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	line := strings.TrimSpace(token + " " + suffix)
+	if _, err := fmt.Fprintf(conn, "%s\n%s", line, body); err != nil {
+		return fmt.Errorf("failed to send ping: %w", err)
+	}
+	if uc, ok := conn.(*net.UnixConn); ok {
+		uc.CloseWrite()
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read reply: %w", err)
+	}
+	reply = strings.TrimSpace(reply)
+	if reply != "OK" {
+		return fmt.Errorf("ping rejected: %s", strings.TrimPrefix(reply, "ERR "))
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -59,11 +60,11 @@ func NewHistory(cfg *config.HistoryConfig, stateFile string, log *logger.Logger)
 		}
 	}
 
-	if cfg.MaxEntries == 0 {
+	if cfg.MaxEntries <= 0 {
 		cfg.MaxEntries = 1000
 	}
 
-	if cfg.RetentionHours == 0 {
+	if cfg.RetentionHours <= 0 {
 		cfg.RetentionHours = 168
 	}
 
@@ -125,26 +126,21 @@ func (h *History) RecordEvent(eventType, target, message string, details map[str
 		stats.FailedRecoveries++
 	}
 
-	// Limpiar eventos antiguos
-	h.cleanOldEvents()
-
-	// Limitar número de eventos
-	if len(h.events) > h.cfg.MaxEntries {
-		h.events = h.events[len(h.events)-h.cfg.MaxEntries:]
-	}
+	h.prune()
 }
 
-// cleanOldEvents elimina eventos más antiguos que RetentionHours
-func (h *History) cleanOldEvents() {
+// prune descarta los eventos más antiguos que RetentionHours y los que superan MaxEntries.
+// Los eventos están en orden cronológico: basta con recortar el principio
+func (h *History) prune() {
 	cutoff := time.Now().Add(-time.Duration(h.cfg.RetentionHours) * time.Hour)
-
-	newEvents := make([]Event, 0)
-	for _, event := range h.events {
-		if event.Timestamp.After(cutoff) {
-			newEvents = append(newEvents, event)
-		}
+	first := sort.Search(len(h.events), func(i int) bool {
+		return h.events[i].Timestamp.After(cutoff)
+	})
+	if excess := len(h.events) - first - h.cfg.MaxEntries; excess > 0 {
+		first += excess
 	}
-	h.events = newEvents
+	// Sin copiar: append reubica el array cuando se llena y el anterior se libera
+	h.events = h.events[first:]
 }
 
 // GetEvents retorna todos los eventos
@@ -283,6 +279,7 @@ func (h *History) Load() error {
 	if h.events == nil {
 		h.events = make([]Event, 0)
 	}
+	h.prune()
 	if h.stats == nil {
 		h.stats = make(map[string]*TargetStats)
 	}
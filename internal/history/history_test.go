@@ -0,0 +1,31 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/logger"
+)
+
+func TestHistoryLimits(t *testing.T) {
+	h := NewHistory(&config.HistoryConfig{MaxEntries: 5, RetentionHours: 1}, "", logger.New("error", io.Discard))
+	h.events = append(h.events, Event{Timestamp: time.Now().Add(-2 * time.Hour), Type: "check_failed", Target: "old"})
+
+	for i := 0; i < 20; i++ {
+		h.RecordEvent("check_passed", "api", fmt.Sprintf("event %d", i), nil)
+	}
+
+	events := h.GetEvents("", 0)
+	if len(events) != 5 {
+		t.Fatalf("expected max_entries events, got %d", len(events))
+	}
+	if events[0].Message != "event 15" || events[4].Message != "event 19" {
+		t.Fatalf("expected the newest events, got %q .. %q", events[0].Message, events[4].Message)
+	}
+	if len(h.GetEvents("old", 0)) != 0 {
+		t.Fatal("events older than retention_hours must be dropped")
+	}
+}
@@ -0,0 +1,155 @@
+// Package httpclient cliente HTTP compartido por el check y la acción http: timeout,
+// autenticación basic/bearer y TLS (CA propia, mTLS) según la configuración.
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// maxBodyBytes parte del body de la respuesta que se conserva (para mensajes de error)
+const maxBodyBytes = 64 * 1024
+
+// Request petición a enviar
+type Request struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    string
+}
+
+// Response respuesta recibida; Body está limitado a los primeros 64KB
+type Response struct {
+	StatusCode int
+	Body       string
+}
+
+// Client cliente HTTP con la autenticación y el TLS de la configuración
+type Client struct {
+	http *http.Client
+	auth *config.HTTPAuth
+}
+
+// New crea un cliente con el timeout indicado. auth y tlsCfg pueden ser nil
+func New(timeout time.Duration, auth *config.HTTPAuth, tlsCfg *config.HTTPTLS) (*Client, error) {
+	client := &http.Client{Timeout: timeout}
+
+	if tlsCfg != nil {
+		tlsConfig, err := clientTLSConfig(tlsCfg)
+		if err != nil {
+			return nil, err
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+
+	return &Client{http: client, auth: auth}, nil
+}
+
+// Do envía la petición y lee la respuesta
+func (c *Client) Do(ctx context.Context, r Request) (*Response, error) {
+	var bodyReader io.Reader
+	if r.Body != "" {
+		bodyReader = bytes.NewBufferString(r.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.Method, r.URL, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for key, value := range r.Headers {
+		req.Header.Set(key, value)
+	}
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	return &Response{StatusCode: resp.StatusCode, Body: string(body)}, nil
+}
+
+// authorize añade la cabecera Authorization; los secretos en archivo se leen en cada petición
+// para que las rotaciones no requieran recargar la configuración
+func (c *Client) authorize(req *http.Request) error {
+	if c.auth == nil {
+		return nil
+	}
+
+	if c.auth.BearerToken != "" || c.auth.BearerTokenFile != "" {
+		token, err := secret(c.auth.BearerToken, c.auth.BearerTokenFile)
+		if err != nil {
+			return fmt.Errorf("cannot read bearer_token_file: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+
+	if c.auth.Username != "" {
+		password, err := secret(c.auth.Password, c.auth.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("cannot read password_file: %w", err)
+		}
+		req.SetBasicAuth(c.auth.Username, password)
+	}
+	return nil
+}
+
+// secret retorna el valor literal o el contenido del archivo sin el salto de línea final
+func secret(value, file string) (string, error) {
+	if file == "" {
+		return value, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// clientTLSConfig construye la configuración TLS/mTLS del cliente
+func clientTLSConfig(cfg *config.HTTPTLS) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CACert != "" {
+		pem, err := os.ReadFile(cfg.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read ca_cert: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,155 @@
+// Package instance garantiza que solo un watchdog actúe a la vez sobre el mismo estado:
+// bloqueo flock sobre un archivo del directorio de estado y socket de control por el que
+// un check puntual (timer) puede pedir una pasada al daemon que tiene el bloqueo.
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// Modos de la instancia que tiene el bloqueo
+const (
+	ModeDaemon = "daemon"
+	ModeCheck  = "check"
+)
+
+// Holder instancia que tiene (o tuvo) el bloqueo
+type Holder struct {
+	PID   int       `json:"pid"`
+	Mode  string    `json:"mode"`
+	Since time.Time `json:"since"`
+}
+
+// LockedError el bloqueo lo tiene otra instancia. Stale indica que el proceso que lo tomó
+// ya no existe pero el descriptor sigue abierto (heredado por un proceso hijo)
+type LockedError struct {
+	Path   string
+	Holder Holder
+	Stale  bool
+}
+
+func (e *LockedError) Error() string {
+	if e.Stale {
+		return fmt.Sprintf("stale lock %s: pid %d (%s) is not running but a process still holds the lock (check for orphaned children)",
+			e.Path, e.Holder.PID, e.Holder.Mode)
+	}
+	return fmt.Sprintf("another instance is running (pid %d, %s since %s)",
+		e.Holder.PID, e.Holder.Mode, e.Holder.Since.Format(time.RFC3339))
+}
+
+// Lock bloqueo de instancia tomado
+type Lock struct {
+	file *os.File
+	path string
+
+	// Previous instancia anterior que terminó sin liberar el bloqueo (caída); nil si no hubo
+	Previous *Holder
+}
+
+// Acquire toma el bloqueo sin esperar. Si lo tiene otra instancia retorna *LockedError
+func Acquire(path, mode string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	// O_CLOEXEC (por defecto en Go): los checks y acciones no heredan el bloqueo
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		holder, _ := readHolder(file)
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, &LockedError{Path: path, Holder: holder, Stale: holder.PID > 0 && !alive(holder.PID)}
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	lock := &Lock{file: file, path: path}
+
+	// El archivo conserva el holder de una instancia que no llegó a liberar el bloqueo
+	if previous, err := readHolder(file); err == nil && previous.PID > 0 {
+		lock.Previous = &previous
+	}
+
+	if err := lock.write(Holder{PID: os.Getpid(), Mode: mode, Since: time.Now()}); err != nil {
+		lock.Release()
+		return nil, err
+	}
+	return lock, nil
+}
+
+// AcquireWait reintenta Acquire hasta timeout mientras el bloqueo lo tenga un check puntual
+// (dura una pasada). Si lo tiene un daemon falla en el acto
+func AcquireWait(path, mode string, timeout time.Duration) (*Lock, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		lock, err := Acquire(path, mode)
+		var locked *LockedError
+		if !errors.As(err, &locked) || locked.Holder.Mode != ModeCheck || locked.Stale || time.Now().After(deadline) {
+			return lock, err
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// Release libera el bloqueo. El archivo se vacía pero no se borra: borrarlo permitiría que
+// dos instancias bloquearan archivos distintos con la misma ruta
+func (l *Lock) Release() error {
+	if l.file == nil {
+		return nil
+	}
+	l.file.Truncate(0)
+	syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// ReadHolder retorna quién tiene el bloqueo según el archivo (puede estar vacío)
+func ReadHolder(path string) (Holder, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Holder{}, err
+	}
+	defer file.Close()
+	return readHolder(file)
+}
+
+func readHolder(file *os.File) (Holder, error) {
+	var holder Holder
+	data := make([]byte, 512)
+	n, err := file.ReadAt(data, 0)
+	if n == 0 {
+		return holder, err
+	}
+	if err := json.Unmarshal(data[:n], &holder); err != nil {
+		return holder, fmt.Errorf("invalid lock file content: %w", err)
+	}
+	return holder, nil
+}
+
+func (l *Lock) write(holder Holder) error {
+	data, _ := json.Marshal(holder)
+	if err := l.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	if _, err := l.file.WriteAt(append(data, '\n'), 0); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return l.file.Sync()
+}
+
+// alive indica si existe un proceso con ese PID
+func alive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,103 @@
+package instance
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/logger"
+)
+
+// Handler atiende un comando del socket de control y retorna la respuesta de una línea
+type Handler func(command string) (string, error)
+
+// Server socket de control del daemon
+type Server struct {
+	ln   net.Listener
+	path string
+}
+
+// Serve abre el socket de control. Debe llamarse con el bloqueo tomado: así el socket
+// huérfano de una instancia caída se puede borrar sin pisar a otra viva.
+// Protocolo: una línea con el comando; respuesta "OK <resultado>" o "ERR <error>"
+func Serve(path string, handler Handler, log *logger.Logger) (*Server, error) {
+	os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on control socket: %w", err)
+	}
+	os.Chmod(path, 0660)
+
+	log.Info("control socket listening", logger.Fields("path", path))
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					log.Error("control socket failed", logger.Fields("error", err.Error()))
+				}
+				return
+			}
+			go handleConn(conn, handler)
+		}
+	}()
+
+	return &Server{ln: ln, path: path}, nil
+}
+
+// Close cierra el socket y borra el archivo
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	os.Remove(s.path)
+	return err
+}
+
+func handleConn(conn net.Conn, handler Handler) {
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintf(conn, "ERR %v\n", err)
+		return
+	}
+
+	// Sin deadline de escritura: una pasada de checks puede tardar
+	conn.SetReadDeadline(time.Time{})
+	reply, err := handler(strings.TrimSpace(line))
+	if err != nil {
+		fmt.Fprintf(conn, "ERR %v\n", err)
+		return
+	}
+	fmt.Fprintf(conn, "OK %s\n", reply)
+}
+
+// Request envía un comando al socket de control y espera la respuesta hasta timeout
+func Request(path, command string, timeout time.Duration) (string, error) {
+	conn, err := net.DialTimeout("unix", path, 5*time.Second)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to control socket: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err := fmt.Fprintf(conn, "%s\n", command); err != nil {
+		return "", fmt.Errorf("failed to send command: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read reply: %w", err)
+	}
+	reply = strings.TrimSpace(reply)
+	if result, ok := strings.CutPrefix(reply, "OK"); ok {
+		return strings.TrimSpace(result), nil
+	}
+	return "", fmt.Errorf("command rejected: %s", strings.TrimPrefix(reply, "ERR "))
+}
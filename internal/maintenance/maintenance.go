@@ -0,0 +1,287 @@
+// Package maintenance decide si un target está en mantenimiento: ventanas definidas en la
+// configuración (puntuales o recurrentes con cron) y silencios ad-hoc creados desde la API
+// del dashboard o el CLI. Los silencios se persisten en disco para sobrevivir a un reinicio.
+package maintenance
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/atomicfile"
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// Silence silencio ad-hoc con autor, motivo y vencimiento
+type Silence struct {
+	ID        string            `json:"id"`
+	Targets   []string          `json:"targets,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Author    string            `json:"author"`
+	Reason    string            `json:"reason"`
+	Suppress  []string          `json:"suppress,omitempty"` // vacío = actions y notifications
+	CreatedAt time.Time         `json:"created_at"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// Match ventana o silencio activo que afecta a un target
+type Match struct {
+	Kind     string    `json:"kind"` // window o silence
+	ID       string    `json:"id"`   // nombre de la ventana o ID del silencio
+	Reason   string    `json:"reason,omitempty"`
+	Until    time.Time `json:"until"`
+	Suppress []string  `json:"suppress,omitempty"`
+}
+
+// WindowStatus ventana de la configuración con su estado actual
+type WindowStatus struct {
+	config.MaintenanceWindow
+	Active bool      `json:"active"`
+	Until  time.Time `json:"until,omitempty"` // fin de la ocurrencia activa
+}
+
+// window ventana con sus tiempos ya interpretados
+type window struct {
+	cfg        config.MaintenanceWindow
+	start, end time.Time
+	schedule   *config.Schedule
+	loc        *time.Location
+	duration   time.Duration
+}
+
+// Manager ventanas de la configuración y silencios persistidos
+type Manager struct {
+	mu       sync.Mutex
+	windows  []window
+	silences []*Silence
+	file     string
+}
+
+// NewManager crea el manager con las ventanas de la configuración (cfg puede ser nil) y
+// los silencios guardados en file. Los silencios se cargan con Load
+func NewManager(cfg *config.MaintenanceConfig, file string) *Manager {
+	m := &Manager{file: file}
+	if cfg == nil {
+		return m
+	}
+
+	// La configuración ya está validada: los errores de parseo no pueden ocurrir aquí
+	for _, w := range cfg.Windows {
+		loc, _ := w.Location()
+		win := window{cfg: w, loc: loc, duration: time.Duration(w.DurationMinutes) * time.Minute}
+		if w.Schedule != "" {
+			win.schedule, _ = config.ParseSchedule(w.Schedule)
+		} else {
+			win.start, _ = time.ParseInLocation(time.RFC3339, w.Start, loc)
+			win.end, _ = time.ParseInLocation(time.RFC3339, w.End, loc)
+		}
+		m.windows = append(m.windows, win)
+	}
+	return m
+}
+
+// activeUntil indica si la ventana está activa en now y hasta cuándo
+func (w *window) activeUntil(now time.Time) (time.Time, bool) {
+	if w.schedule == nil {
+		return w.end, !now.Before(w.start) && now.Before(w.end)
+	}
+
+	// Busca hacia atrás un minuto de arranque cuya ocurrencia siga abierta
+	minute := now.In(w.loc).Truncate(time.Minute)
+	for elapsed := time.Duration(0); elapsed < w.duration; elapsed += time.Minute {
+		start := minute.Add(-elapsed)
+		if w.schedule.Matches(start) {
+			return start.Add(w.duration), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Active retorna las ventanas y silencios activos que afectan al target
+func (m *Manager) Active(target config.Target, now time.Time) []Match {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var matches []Match
+	for i := range m.windows {
+		w := &m.windows[i]
+		if !config.ScopeMatches(w.cfg.Targets, w.cfg.Labels, target) {
+			continue
+		}
+		if until, ok := w.activeUntil(now); ok {
+			matches = append(matches, Match{
+				Kind:     "window",
+				ID:       w.cfg.Name,
+				Until:    until,
+				Suppress: w.cfg.Suppress,
+			})
+		}
+	}
+	for _, s := range m.silences {
+		if now.Before(s.ExpiresAt) && config.ScopeMatches(s.Targets, s.Labels, target) {
+			matches = append(matches, Match{
+				Kind:     "silence",
+				ID:       s.ID,
+				Reason:   fmt.Sprintf("%s (%s)", s.Reason, s.Author),
+				Until:    s.ExpiresAt,
+				Suppress: s.Suppress,
+			})
+		}
+	}
+	return matches
+}
+
+// Suppressed retorna la primera ventana o silencio activo que suprime what
+// (config.SuppressActions o config.SuppressNotifications) para el target
+func (m *Manager) Suppressed(target config.Target, what string, now time.Time) (Match, bool) {
+	for _, match := range m.Active(target, now) {
+		if config.Suppresses(match.Suppress, what) {
+			return match, true
+		}
+	}
+	return Match{}, false
+}
+
+// Windows retorna las ventanas de la configuración con su estado actual
+func (m *Manager) Windows(now time.Time) []WindowStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]WindowStatus, 0, len(m.windows))
+	for i := range m.windows {
+		status := WindowStatus{MaintenanceWindow: m.windows[i].cfg}
+		status.Until, status.Active = m.windows[i].activeUntil(now)
+		if !status.Active {
+			status.Until = time.Time{}
+		}
+		list = append(list, status)
+	}
+	return list
+}
+
+// Silences retorna los silencios vigentes, los que vencen antes primero
+func (m *Manager) Silences() []Silence {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	list := make([]Silence, 0, len(m.silences))
+	for _, s := range m.silences {
+		if now.Before(s.ExpiresAt) {
+			list = append(list, *s)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ExpiresAt.Before(list[j].ExpiresAt) })
+	return list
+}
+
+// AddSilence valida y guarda un silencio nuevo. ExpiresAt debe estar en el futuro
+func (m *Manager) AddSilence(s Silence) (Silence, error) {
+	if len(s.Targets) == 0 && len(s.Labels) == 0 {
+		return Silence{}, fmt.Errorf("targets or labels is required (use [\"*\"] for all targets)")
+	}
+	if s.Author == "" {
+		return Silence{}, fmt.Errorf("author is required")
+	}
+	if s.Reason == "" {
+		return Silence{}, fmt.Errorf("reason is required")
+	}
+	if err := config.ValidateSuppress(s.Suppress); err != nil {
+		return Silence{}, err
+	}
+
+	now := time.Now()
+	if !s.ExpiresAt.After(now) {
+		return Silence{}, fmt.Errorf("expires_at must be in the future")
+	}
+	s.ID = newID()
+	s.CreatedAt = now
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune(now)
+	m.silences = append(m.silences, &s)
+	if err := m.save(); err != nil {
+		return s, fmt.Errorf("silence created but not persisted: %w", err)
+	}
+	return s, nil
+}
+
+// RemoveSilence elimina un silencio antes de su vencimiento
+func (m *Manager) RemoveSilence(id string) (Silence, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, s := range m.silences {
+		if s.ID != id {
+			continue
+		}
+		removed := *s
+		m.silences = append(m.silences[:i], m.silences[i+1:]...)
+		if err := m.save(); err != nil {
+			return removed, fmt.Errorf("silence removed but not persisted: %w", err)
+		}
+		return removed, nil
+	}
+	return Silence{}, fmt.Errorf("silence %s not found", id)
+}
+
+// Load carga los silencios guardados y descarta los vencidos
+func (m *Manager) Load() error {
+	data, err := os.ReadFile(m.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // No hay silencios previos
+		}
+		return fmt.Errorf("failed to read silences file: %w", err)
+	}
+
+	var silences []*Silence
+	if err := json.Unmarshal(data, &silences); err != nil {
+		return fmt.Errorf("failed to unmarshal silences: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.silences = silences
+	m.prune(time.Now())
+	return nil
+}
+
+// prune descarta los silencios vencidos
+func (m *Manager) prune(now time.Time) {
+	kept := m.silences[:0]
+	for _, s := range m.silences {
+		if now.Before(s.ExpiresAt) {
+			kept = append(kept, s)
+		}
+	}
+	m.silences = kept
+}
+
+// save escribe los silencios en disco
+func (m *Manager) save() error {
+	silences := m.silences
+	if silences == nil {
+		silences = []*Silence{}
+	}
+	data, err := json.MarshalIndent(silences, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal silences: %w", err)
+	}
+
+	return atomicfile.Write(m.file, data, 0644)
+}
+
+// newID identificador corto para usar desde el CLI
+func newID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1,8 +1,11 @@
 package metrics
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,7 +20,29 @@ type Collector struct {
 	mu         sync.RWMutex
 	checks     map[string]*CheckMetrics
 	recoveries map[string]int64
+	perfdata   map[perfKey]perfValue
 	uptime     time.Time
+	server     *http.Server
+}
+
+// perfKey identifica un valor de perfdata por target, check y label
+type perfKey struct {
+	target string
+	check  string
+	label  string
+}
+
+// perfValue último valor de perfdata recibido
+type perfValue struct {
+	value float64
+	unit  string
+}
+
+// Perfdata valor numérico reportado por un check
+type Perfdata struct {
+	Label string
+	Unit  string
+	Value float64
 }
 
 // CheckMetrics métricas por target
@@ -46,13 +71,15 @@ func NewCollector(cfg *config.MetricsConfig, log *logger.Logger) *Collector {
 		log:        log,
 		checks:     make(map[string]*CheckMetrics),
 		recoveries: make(map[string]int64),
+		perfdata:   make(map[perfKey]perfValue),
 		uptime:     time.Now(),
 	}
 }
 
-// Start inicia el servidor de métricas
+// Start abre el puerto de métricas y lo atiende en segundo plano. El puerto se abre antes
+// de retornar: un error (puerto ocupado) se devuelve en lugar de solo registrarse
 func (c *Collector) Start() error {
-	if !c.cfg.Enabled {
+	if !c.cfg.Enabled || c.server != nil {
 		return nil
 	}
 
@@ -60,16 +87,21 @@ func (c *Collector) Start() error {
 		c.cfg.Port = 9090
 	}
 
-	http.HandleFunc(c.cfg.Path, c.handleMetrics)
-
 	addr := fmt.Sprintf(":%d", c.cfg.Port)
-	c.log.Info("metrics server starting", logger.Fields(
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("metrics server cannot listen on %s: %w", addr, err)
+	}
+
+	http.HandleFunc(c.cfg.Path, c.handleMetrics)
+	c.server = &http.Server{}
+	c.log.Info("metrics server listening", logger.Fields(
 		"port", c.cfg.Port,
 		"path", c.cfg.Path,
 	))
 
 	go func() {
-		if err := http.ListenAndServe(addr, nil); err != nil {
+		if err := c.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			c.log.Error("metrics server failed", logger.Fields("error", err.Error()))
 		}
 	}()
@@ -77,6 +109,14 @@ func (c *Collector) Start() error {
 	return nil
 }
 
+// Close cierra el puerto de métricas
+func (c *Collector) Close() error {
+	if c.server == nil {
+		return nil
+	}
+	return c.server.Close()
+}
+
 // RecordCheck registra una ejecución de check
 func (c *Collector) RecordCheck(target string, healthy bool, duration time.Duration, consecutiveFailures int) {
 	if !c.cfg.Enabled {
@@ -117,6 +157,59 @@ func (c *Collector) RecordRecovery(target string) {
 	c.recoveries[target]++
 }
 
+// SetPerfdata registra los valores numéricos reportados por un check (ej: perfdata Nagios).
+// Reemplaza los de la pasada anterior: las labels que el check ya no reporta desaparecen
+func (c *Collector) SetPerfdata(target, check string, values []Perfdata) {
+	if !c.cfg.Enabled {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.perfdata {
+		if key.target == target && key.check == check {
+			delete(c.perfdata, key)
+		}
+	}
+	for _, v := range values {
+		c.perfdata[perfKey{target: target, check: check, label: v.Label}] = perfValue{value: v.Value, unit: v.Unit}
+	}
+}
+
+// RetainTargets descarta las series de los targets que no están en targets (eliminados de la
+// configuración o desactivados)
+func (c *Collector) RetainTargets(targets []string) {
+	keep := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		keep[target] = true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.perfdata {
+		if !keep[key.target] {
+			delete(c.perfdata, key)
+		}
+	}
+	for target := range c.checks {
+		if !keep[target] {
+			delete(c.checks, target)
+		}
+	}
+	for target := range c.recoveries {
+		if !keep[target] {
+			delete(c.recoveries, target)
+		}
+	}
+}
+
+// escapeLabel escapa un valor de label según el formato de exposición de Prometheus
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
 // handleMetrics maneja el endpoint de métricas
 func (c *Collector) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	c.mu.RLock()
@@ -197,4 +290,15 @@ func (c *Collector) handleMetrics(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "neon_watchdog_last_check_timestamp_seconds{target=\"%s\"} %d\n",
 			target, metrics.LastCheckTime.Unix())
 	}
+
+	// Perfdata de checks
+	if len(c.perfdata) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "# HELP neon_watchdog_perfdata Last perfdata value reported by a check\n")
+		fmt.Fprintf(w, "# TYPE neon_watchdog_perfdata gauge\n")
+		for key, pv := range c.perfdata {
+			fmt.Fprintf(w, "neon_watchdog_perfdata{target=\"%s\",check=\"%s\",label=\"%s\",unit=\"%s\"} %g\n",
+				escapeLabel(key.target), escapeLabel(key.check), escapeLabel(key.label), escapeLabel(pv.unit), pv.value)
+		}
+	}
 }
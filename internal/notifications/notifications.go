@@ -150,10 +150,10 @@ Time: %s
 
 Message:
 %s
-
+%s
 ---
 This is an automated alert from Neon Watchdog
-`, event.Type, event.Target, event.Severity, event.Timestamp.Format(time.RFC3339), event.Message)
+`, event.Type, event.Target, event.Severity, event.Timestamp.Format(time.RFC3339), event.Message, formatDetails(event.Details))
 
 	msg := fmt.Sprintf("From: %s\r\n"+
 		"To: %s\r\n"+
@@ -230,6 +230,19 @@ This is an automated alert from Neon Watchdog
 	return nil
 }
 
+// formatDetails formatea los detalles del evento como texto indentado
+func formatDetails(details map[string]interface{}) string {
+	if len(details) == 0 {
+		return ""
+	}
+
+	data, err := json.MarshalIndent(details, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("\nDetails:\n%s\n", data)
+}
+
 // WebhookNotifier envía notificaciones por webhook
 type WebhookNotifier struct {
 	cfg    *config.WebhookConfig
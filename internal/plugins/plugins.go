@@ -0,0 +1,307 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/process"
+)
+
+// ProtocolVersion versión del protocolo JSON sobre stdin/stdout
+const ProtocolVersion = 1
+
+// DefaultDir directorio por defecto donde se buscan los plugins
+const DefaultDir = config.DefaultPluginsDir
+
+// maxResponseSize límite de la respuesta JSON de un plugin
+const maxResponseSize = 1024 * 1024
+
+// maxStderrSize parte de stderr que se incluye en los errores
+const maxStderrSize = 300
+
+// describeTimeout tiempo máximo para que un plugin describa su esquema
+const describeTimeout = 5 * time.Second
+
+// Comandos del protocolo
+const (
+	CommandDescribe = "describe"
+	CommandCheck    = "check"
+	CommandAction   = "action"
+)
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+func init() {
+	config.SetPluginValidator(Validate)
+}
+
+// Request petición enviada al plugin por stdin
+type Request struct {
+	ProtocolVersion int                    `json:"protocol_version"`
+	Command         string                 `json:"command"` // describe, check, action
+	Target          string                 `json:"target,omitempty"`
+	TimeoutMs       int64                  `json:"timeout_ms,omitempty"`
+	Operation       string                 `json:"operation,omitempty"` // acciones: start, restart
+	Config          map[string]interface{} `json:"config,omitempty"`
+}
+
+// Description respuesta a "describe": tipos soportados y esquema de configuración
+type Description struct {
+	ProtocolVersion int      `json:"protocol_version"`
+	Name            string   `json:"name"`
+	Kinds           []string `json:"kinds"` // check, action
+	ConfigSchema    *Schema  `json:"config_schema,omitempty"`
+}
+
+// Metric valor numérico devuelto por un plugin
+type Metric struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit,omitempty"`
+}
+
+// CheckResult respuesta a "check"
+type CheckResult struct {
+	ProtocolVersion int                    `json:"protocol_version"`
+	Status          string                 `json:"status"` // ok, warning, critical, unknown
+	Message         string                 `json:"message"`
+	Metrics         []Metric               `json:"metrics,omitempty"`
+	Details         map[string]interface{} `json:"details,omitempty"`
+}
+
+// ActionResult respuesta a "action"
+type ActionResult struct {
+	ProtocolVersion int                    `json:"protocol_version"`
+	Success         bool                   `json:"success"`
+	Message         string                 `json:"message"`
+	Details         map[string]interface{} `json:"details,omitempty"`
+}
+
+// protocolHeader permite verificar la versión antes de decodificar la respuesta completa
+type protocolHeader struct {
+	ProtocolVersion int `json:"protocol_version"`
+}
+
+// Path resuelve la ruta del ejecutable de un plugin dentro del directorio
+func Path(dir, name string) (string, error) {
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("invalid plugin name: %q", name)
+	}
+	if dir == "" {
+		dir = DefaultDir
+	}
+
+	path := filepath.Join(dir, name)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("plugin %s not found in %s", name, dir)
+	}
+	if info.IsDir() || info.Mode()&0111 == 0 {
+		return "", fmt.Errorf("plugin %s is not executable", path)
+	}
+	return path, nil
+}
+
+// Call ejecuta el plugin enviando la petición por stdin y decodifica la respuesta de stdout
+func Call(ctx context.Context, dir, name string, req Request, resp interface{}) error {
+	path, err := Path(dir, name)
+	if err != nil {
+		return err
+	}
+
+	req.ProtocolVersion = ProtocolVersion
+	if deadline, ok := ctx.Deadline(); ok && req.TimeoutMs == 0 {
+		req.TimeoutMs = time.Until(deadline).Milliseconds()
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Grupo de procesos propio: al vencer el contexto se matan también los hijos del plugin
+	cmd, err := process.Command(ctx, nil, path)
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", name, err)
+	}
+	stdout := process.NewCappedBuffer(maxResponseSize)
+	stderr := process.NewCappedBuffer(maxStderrSize)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		errOutput := strings.TrimSpace(stderr.String())
+		if stderr.Truncated {
+			errOutput += "..."
+		}
+		return fmt.Errorf("plugin %s failed: %v (stderr: %s)", name, err, errOutput)
+	}
+
+	if stdout.Truncated {
+		return fmt.Errorf("plugin %s response too large (more than %d bytes)", name, maxResponseSize)
+	}
+
+	output := []byte(stdout.String())
+	var header protocolHeader
+	if err := json.Unmarshal(output, &header); err != nil {
+		return fmt.Errorf("plugin %s returned invalid JSON: %w", name, err)
+	}
+	if header.ProtocolVersion != ProtocolVersion {
+		return fmt.Errorf("plugin %s speaks protocol version %d, expected %d", name, header.ProtocolVersion, ProtocolVersion)
+	}
+
+	if err := json.Unmarshal(output, resp); err != nil {
+		return fmt.Errorf("plugin %s returned invalid response: %w", name, err)
+	}
+	return nil
+}
+
+// Describe obtiene la descripción y el esquema de configuración de un plugin
+func Describe(ctx context.Context, dir, name string) (*Description, error) {
+	desc := &Description{}
+	if err := Call(ctx, dir, name, Request{Command: CommandDescribe}, desc); err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
+
+// Validate verifica que el plugin soporta el tipo pedido y que la configuración cumple su esquema
+func Validate(dir, name, kind string, cfg map[string]interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), describeTimeout)
+	defer cancel()
+
+	desc, err := Describe(ctx, dir, name)
+	if err != nil {
+		return err
+	}
+
+	supported := false
+	for _, k := range desc.Kinds {
+		if k == kind {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return fmt.Errorf("plugin %s does not support kind '%s' (supports: %s)", name, kind, strings.Join(desc.Kinds, ", "))
+	}
+
+	if desc.ConfigSchema == nil {
+		return nil
+	}
+	if cfg == nil {
+		cfg = map[string]interface{}{}
+	}
+	return desc.ConfigSchema.Validate(cfg, "config")
+}
+
+// Schema subconjunto de JSON Schema para validar la configuración de un plugin
+type Schema struct {
+	Type                 string             `json:"type,omitempty"` // string, number, integer, boolean, array, object
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+}
+
+// Validate valida un valor decodificado de YAML/JSON contra el esquema
+func (s *Schema) Validate(value interface{}, path string) error {
+	if s == nil {
+		return nil
+	}
+
+	if s.Type != "" && !matchesType(s.Type, value) {
+		return fmt.Errorf("%s: expected %s, got %T", path, s.Type, value)
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, allowed := range s.Enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: value %v not in %v", path, value, s.Enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, req := range s.Required {
+			if _, ok := v[req]; !ok {
+				return fmt.Errorf("%s.%s is required", path, req)
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			prop, ok := s.Properties[k]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return fmt.Errorf("%s.%s is not a known option", path, k)
+				}
+				continue
+			}
+			if err := prop.Validate(v[k], path+"."+k); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if err := s.Items.Validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchesType compara el tipo JSON Schema con el tipo Go decodificado
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		switch n := value.(type) {
+		case int, int64, uint64:
+			return true
+		case float64:
+			return n == float64(int64(n))
+		}
+		return false
+	case "number":
+		switch value.(type) {
+		case int, int64, uint64, float64:
+			return true
+		}
+		return false
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writePlugin(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCallResponseTooLarge(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "chatty", `head -c 2000000 /dev/zero`)
+
+	var resp CheckResult
+	err := Call(context.Background(), dir, "chatty", Request{Command: CommandCheck}, &resp)
+	if err == nil || !strings.Contains(err.Error(), "response too large") {
+		t.Fatalf("expected response too large error, got %v", err)
+	}
+}
+
+func TestCallKillsProcessGroupOnTimeout(t *testing.T) {
+	dir := t.TempDir()
+	// El hijo en segundo plano mantiene stdout abierto: sin matar el grupo Call no volvería
+	writePlugin(t, dir, "hang", `sleep 30 & wait`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	var resp CheckResult
+	if err := Call(ctx, dir, "hang", Request{Command: CommandCheck}, &resp); err == nil {
+		t.Fatal("expected an error from a plugin that times out")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Call returned after %s: the plugin's children were not killed", elapsed)
+	}
+}
@@ -0,0 +1,35 @@
+package process
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// rlimitResources recursos de setrlimit(2) por nombre de config.ResourceLimits
+var rlimitResources = map[string]int{
+	"as":      unix.RLIMIT_AS,
+	"core":    unix.RLIMIT_CORE,
+	"cpu":     unix.RLIMIT_CPU,
+	"data":    unix.RLIMIT_DATA,
+	"fsize":   unix.RLIMIT_FSIZE,
+	"memlock": unix.RLIMIT_MEMLOCK,
+	"nofile":  unix.RLIMIT_NOFILE,
+	"nproc":   unix.RLIMIT_NPROC,
+	"stack":   unix.RLIMIT_STACK,
+}
+
+// setLimits fija los rlimits (soft y hard) del proceso pid con prlimit(2)
+func setLimits(pid int, limits map[string]uint64) error {
+	for name, value := range limits {
+		resource, ok := rlimitResources[name]
+		if !ok {
+			return fmt.Errorf("unknown limit '%s'", name)
+		}
+		rlim := unix.Rlimit{Cur: value, Max: value}
+		if err := unix.Prlimit(pid, resource, &rlim, nil); err != nil {
+			return fmt.Errorf("cannot set limit %s=%d: %w", name, value, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package process
+
+import "fmt"
+
+// setLimits solo está soportado en Linux (prlimit)
+func setLimits(pid int, limits map[string]uint64) error {
+	if len(limits) > 0 {
+		return fmt.Errorf("resource limits are only supported on linux")
+	}
+	return nil
+}
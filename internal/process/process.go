@@ -0,0 +1,268 @@
+// Package process ejecuta los procesos de checks, acciones y hooks con las opciones de
+// config.ExecOptions: entorno, directorio, usuario/grupo, umask, nice, rlimits y salida
+// limitada. Cada proceso corre en su propio grupo y al vencer el contexto se mata el
+// grupo entero para no dejar nietos huérfanos.
+package process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/tgextreme/neon-watchdog/internal/config"
+)
+
+// DefaultMaxOutputBytes límite por defecto de stdout/stderr guardado por stream
+const DefaultMaxOutputBytes = 64 * 1024
+
+// defaultPath PATH usado con clear_env
+const defaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// waitDelay tiempo que Wait espera a que se cierren stdout/stderr tras matar el grupo
+const waitDelay = 5 * time.Second
+
+// Cmd proceso preparado con sus opciones de ejecución
+type Cmd struct {
+	*exec.Cmd
+	nice   int
+	limits map[string]uint64
+
+	// gate/release: pipe en el que espera el hijo hasta tener nice y rlimits aplicados
+	gate    *os.File
+	release *os.File
+}
+
+// Command prepara el proceso name args... con las opciones indicadas (opts puede ser nil)
+func Command(ctx context.Context, opts *config.ExecOptions, name string, args ...string) (*Cmd, error) {
+	if opts == nil {
+		opts = &config.ExecOptions{}
+	}
+
+	// umask, nice y rlimits no se pueden fijar para el hijo desde Go sin afectar a todo el
+	// watchdog: el hijo arranca como un sh que aplica el umask, espera en fd 3 a que
+	// Start fije nice y rlimits sobre su PID y hace exec del comando (mismo PID)
+	gated := opts.Nice != 0 || len(opts.Limits) > 0
+	if opts.Umask != "" || gated {
+		script := `exec "$0" "$@"`
+		if gated {
+			script = `read -r _ <&3 && exec 3<&- && ` + script
+		}
+		if opts.Umask != "" {
+			umask, err := config.ParseUmask(opts.Umask)
+			if err != nil {
+				return nil, err
+			}
+			script = fmt.Sprintf("umask %04o && %s", umask, script)
+		}
+		args = append([]string{"-c", script, name}, args...)
+		name = "/bin/sh"
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = opts.WorkingDir
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = waitDelay
+
+	env, err := environment(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.User != "" || opts.Group != "" {
+		cred, home, username, err := credential(opts)
+		if err != nil {
+			return nil, err
+		}
+		cmd.SysProcAttr.Credential = cred
+		if username != "" {
+			env = setEnv(env, "HOME", home)
+			env = setEnv(env, "USER", username)
+			env = setEnv(env, "LOGNAME", username)
+		}
+	}
+	cmd.Env = env
+
+	c := &Cmd{Cmd: cmd, nice: opts.Nice, limits: opts.Limits}
+	if gated {
+		c.gate, c.release, err = os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		cmd.ExtraFiles = []*os.File{c.gate}
+	}
+	return c, nil
+}
+
+// Start arranca el proceso, le aplica nice y rlimits y solo entonces deja que ejecute el comando
+func (c *Cmd) Start() error {
+	if c.gate == nil {
+		return c.Cmd.Start()
+	}
+	defer c.release.Close()
+
+	err := c.Cmd.Start()
+	c.gate.Close()
+	if err != nil {
+		return err
+	}
+
+	pid := c.Process.Pid
+	if c.nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, pid, c.nice); err != nil {
+			c.kill()
+			return fmt.Errorf("cannot set nice %d: %w", c.nice, err)
+		}
+	}
+	if err := setLimits(pid, c.limits); err != nil {
+		c.kill()
+		return err
+	}
+
+	if _, err := c.release.Write([]byte("go\n")); err != nil {
+		c.kill()
+		return fmt.Errorf("cannot release process: %w", err)
+	}
+	return nil
+}
+
+// Run arranca el proceso y espera a que termine
+func (c *Cmd) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+	return c.Wait()
+}
+
+// kill mata el grupo del proceso y recoge su estado
+func (c *Cmd) kill() {
+	syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+	c.Wait()
+}
+
+// environment construye el entorno: el del watchdog (salvo clear_env), env_file y env
+func environment(opts *config.ExecOptions) ([]string, error) {
+	var env []string
+	if opts.ClearEnv {
+		env = []string{"PATH=" + defaultPath}
+	} else {
+		env = os.Environ()
+	}
+
+	if opts.EnvFile != "" {
+		fileEnv, err := config.ReadEnvFile(opts.EnvFile)
+		if err != nil {
+			return nil, err
+		}
+		env = mergeEnv(env, fileEnv)
+	}
+	return mergeEnv(env, opts.Env), nil
+}
+
+// mergeEnv añade o reemplaza variables en orden estable
+func mergeEnv(env []string, vars map[string]string) []string {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = setEnv(env, k, vars[k])
+	}
+	return env
+}
+
+func setEnv(env []string, key, value string) []string {
+	prefix := key + "="
+	for i, kv := range env {
+		if len(kv) >= len(prefix) && kv[:len(prefix)] == prefix {
+			env[i] = prefix + value
+			return env
+		}
+	}
+	return append(env, prefix+value)
+}
+
+// credential resuelve UID/GID y grupos suplementarios. Con solo group se mantiene el UID
+func credential(opts *config.ExecOptions) (*syscall.Credential, string, string, error) {
+	cred := &syscall.Credential{
+		Uid: uint32(os.Getuid()),
+		Gid: uint32(os.Getgid()),
+	}
+	var home, username string
+
+	if opts.User != "" {
+		u, err := config.LookupUser(opts.User)
+		if err != nil {
+			return nil, "", "", err
+		}
+		uid, _ := strconv.ParseUint(u.Uid, 10, 32)
+		gid, _ := strconv.ParseUint(u.Gid, 10, 32)
+		cred.Uid, cred.Gid = uint32(uid), uint32(gid)
+		home, username = u.HomeDir, u.Username
+
+		groupIDs, err := u.GroupIds()
+		if err == nil {
+			for _, id := range groupIDs {
+				if g, err := strconv.ParseUint(id, 10, 32); err == nil {
+					cred.Groups = append(cred.Groups, uint32(g))
+				}
+			}
+		}
+	} else {
+		// Sin user se conservan los grupos suplementarios actuales
+		cred.NoSetGroups = true
+	}
+
+	if opts.Group != "" {
+		g, err := config.LookupGroup(opts.Group)
+		if err != nil {
+			return nil, "", "", err
+		}
+		gid, _ := strconv.ParseUint(g.Gid, 10, 32)
+		cred.Gid = uint32(gid)
+	}
+
+	return cred, home, username, nil
+}
+
+// CappedBuffer guarda hasta Max bytes y descarta el resto sin bloquear al proceso hijo
+type CappedBuffer struct {
+	Max       int
+	Truncated bool
+	buf       []byte
+}
+
+// NewCappedBuffer crea un buffer limitado a max bytes (DefaultMaxOutputBytes si max <= 0)
+func NewCappedBuffer(max int) *CappedBuffer {
+	if max <= 0 {
+		max = DefaultMaxOutputBytes
+	}
+	return &CappedBuffer{Max: max}
+}
+
+func (b *CappedBuffer) Write(p []byte) (int, error) {
+	room := b.Max - len(b.buf)
+	if room <= 0 {
+		b.Truncated = b.Truncated || len(p) > 0
+		return len(p), nil
+	}
+	if len(p) > room {
+		b.buf = append(b.buf, p[:room]...)
+		b.Truncated = true
+		return len(p), nil
+	}
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+func (b *CappedBuffer) String() string {
+	return string(b.buf)
+}
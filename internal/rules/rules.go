@@ -0,0 +1,604 @@
+// Package rules implementa el lenguaje de expresiones de healthy_when: combina el resultado
+// de los checks con nombre de un target con and/or/not, comparaciones y paréntesis.
+//
+//	http and (http.latency < 500ms or db.replication_lag < 10) and not disk.failed
+//
+// Cada check se referencia por su nombre; sin campo equivale a "<nombre>.ok". Campos:
+// ok, failed, degraded (bool), status ("ok", "degraded", "failed"), message (string),
+// latency (ms) y cualquier otro nombre como métrica numérica reportada por el check.
+// has(<check>.<métrica>) indica si el check reportó la métrica; usar una métrica ausente
+// es un error de evaluación, así que las opcionales se protegen con has(...) and ...
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CheckValues valores de un check disponibles para la expresión
+type CheckValues struct {
+	Success   bool
+	Degraded  bool
+	LatencyMs float64
+	Message   string
+	Metrics   map[string]float64
+}
+
+// Env resultados de la pasada indexados por nombre de check
+type Env map[string]CheckValues
+
+// Expr expresión compilada
+type Expr struct {
+	src  string
+	root node
+	refs []string
+}
+
+// Compile analiza y comprueba tipos de la expresión; el resultado debe ser booleano
+func Compile(src string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("position %d: unexpected %s", tok.pos+1, tok)
+	}
+	if t := root.typ(); t != typeBool {
+		return nil, fmt.Errorf("expression must be boolean, got %s", t)
+	}
+
+	expr := &Expr{src: src, root: root}
+	seen := map[string]bool{}
+	for _, ref := range p.refs {
+		if !seen[ref] {
+			seen[ref] = true
+			expr.refs = append(expr.refs, ref)
+		}
+	}
+	return expr, nil
+}
+
+// String texto original de la expresión
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Refs nombres de check referenciados, en orden de aparición
+func (e *Expr) Refs() []string {
+	return e.refs
+}
+
+// Eval evalúa la expresión. Una métrica que el check no reportó es un error
+func (e *Expr) Eval(env Env) (bool, error) {
+	v, err := e.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, _ := v.(bool)
+	return b, nil
+}
+
+// ---- léxico ----
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("'%s'", t.text)
+}
+
+// durationUnits sufijos admitidos en literales numéricos; el valor se expresa en ms
+var durationUnits = map[string]float64{"ms": 1, "s": 1000, "m": 60000}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		c, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			var b strings.Builder
+			for end < len(src) && rune(src[end]) != c {
+				if src[end] == '\\' && end+1 < len(src) {
+					end++
+				}
+				r, n := utf8.DecodeRuneInString(src[end:])
+				b.WriteRune(r)
+				end += n
+			}
+			if end >= len(src) {
+				return nil, fmt.Errorf("position %d: unterminated string", i+1)
+			}
+			tokens = append(tokens, token{kind: tokString, text: b.String(), pos: i})
+			i = end + 1
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(rune(src[i+1]))):
+			end := i
+			for end < len(src) && (isDigit(rune(src[end])) || src[end] == '.') {
+				end++
+			}
+			num, err := strconv.ParseFloat(src[i:end], 64)
+			if err != nil {
+				return nil, fmt.Errorf("position %d: invalid number '%s'", i+1, src[i:end])
+			}
+			unitEnd := scan(src, end, unicode.IsLetter)
+			if unit := src[end:unitEnd]; unit != "" {
+				factor, ok := durationUnits[unit]
+				if !ok {
+					return nil, fmt.Errorf("position %d: unknown unit '%s' (must be: ms, s, m)", end+1, unit)
+				}
+				num *= factor
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: src[i:unitEnd], num: num, pos: i})
+			i = unitEnd
+		case unicode.IsLetter(c) || c == '_':
+			end := scan(src, i, func(r rune) bool {
+				return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'
+			})
+			word := src[i:end]
+			switch word {
+			case "and", "or", "not", "contains", "matches":
+				tokens = append(tokens, token{kind: tokOp, text: word, pos: i})
+			default:
+				tokens = append(tokens, token{kind: tokIdent, text: word, pos: i})
+			}
+			i = end
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!"} {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("position %d: unexpected character '%c'", i+1, c)
+			}
+			// Alias simbólicos de los operadores lógicos
+			text := map[string]string{"&&": "and", "||": "or", "!": "not"}[op]
+			if text == "" {
+				text = op
+			}
+			tokens = append(tokens, token{kind: tokOp, text: text, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// scan avanza desde i mientras las runas cumplan accept y retorna el índice final
+func scan(src string, i int, accept func(rune) bool) int {
+	for i < len(src) {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		if !accept(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// isDigit solo dígitos ASCII: strconv.ParseFloat no admite otros
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// ---- sintaxis ----
+
+type valueType string
+
+const (
+	typeBool   valueType = "boolean"
+	typeNumber valueType = "number"
+	typeString valueType = "string"
+)
+
+type node interface {
+	typ() valueType
+	eval(env Env) (interface{}, error)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	refs   []string
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) isOp(text string) bool {
+	tok := p.peek()
+	return tok.kind == tokOp && tok.text == text
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("or") {
+		tok := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if err := expectBool(tok, left, right); err != nil {
+			return nil, err
+		}
+		left = &logicNode{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("and") {
+		tok := p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if err := expectBool(tok, left, right); err != nil {
+			return nil, err
+		}
+		left = &logicNode{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isOp("not") {
+		tok := p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if err := expectBool(tok, operand); err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	if tok.kind != tokOp {
+		return left, nil
+	}
+	switch tok.text {
+	case "==", "!=", "<", "<=", ">", ">=", "contains", "matches":
+	default:
+		return left, nil
+	}
+	p.next()
+
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := &compareNode{op: tok.text, left: left, right: right}
+	switch tok.text {
+	case "==", "!=":
+		if left.typ() != right.typ() {
+			return nil, fmt.Errorf("position %d: cannot compare %s with %s", tok.pos+1, left.typ(), right.typ())
+		}
+	case "<", "<=", ">", ">=":
+		if left.typ() != typeNumber || right.typ() != typeNumber {
+			return nil, fmt.Errorf("position %d: '%s' requires numbers, got %s and %s", tok.pos+1, tok.text, left.typ(), right.typ())
+		}
+	case "contains":
+		if left.typ() != typeString || right.typ() != typeString {
+			return nil, fmt.Errorf("position %d: 'contains' requires strings, got %s and %s", tok.pos+1, left.typ(), right.typ())
+		}
+	case "matches":
+		lit, ok := right.(*literalNode)
+		if left.typ() != typeString || !ok || lit.typ() != typeString {
+			return nil, fmt.Errorf("position %d: 'matches' requires a string and a string literal pattern", tok.pos+1)
+		}
+		re, err := regexp.Compile(lit.value.(string))
+		if err != nil {
+			return nil, fmt.Errorf("position %d: invalid pattern: %w", tok.pos+1, err)
+		}
+		cmp.re = re
+	}
+	return cmp, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, fmt.Errorf("position %d: expected ')', got %s", closing.pos+1, closing)
+		}
+		return inner, nil
+	case tokNumber:
+		return &literalNode{value: tok.num}, nil
+	case tokString:
+		return &literalNode{value: tok.text}, nil
+	case tokIdent:
+		if tok.text == "true" || tok.text == "false" {
+			return &literalNode{value: tok.text == "true"}, nil
+		}
+		if tok.text == "has" && p.peek().kind == tokLParen {
+			return p.parseHas(tok)
+		}
+		return p.parseField(tok)
+	}
+	return nil, fmt.Errorf("position %d: expected a value, got %s", tok.pos+1, tok)
+}
+
+// parseField resuelve "<check>" o "<check>.<campo>"
+func (p *parser) parseField(tok token) (node, error) {
+	check, field, _ := strings.Cut(tok.text, ".")
+	if check == "" || strings.HasSuffix(tok.text, ".") || strings.Contains(field, ".") {
+		return nil, fmt.Errorf("position %d: invalid reference '%s' (must be <check> or <check>.<field>)", tok.pos+1, tok.text)
+	}
+	if field == "" {
+		field = "ok"
+	}
+	p.refs = append(p.refs, check)
+	return &fieldNode{check: check, field: field}, nil
+}
+
+// parseHas resuelve "has(<check>.<campo>)"
+func (p *parser) parseHas(tok token) (node, error) {
+	p.next()
+	arg := p.next()
+	if arg.kind != tokIdent {
+		return nil, fmt.Errorf("position %d: 'has' requires a reference, got %s", arg.pos+1, arg)
+	}
+	field, err := p.parseField(arg)
+	if err != nil {
+		return nil, err
+	}
+	if closing := p.next(); closing.kind != tokRParen {
+		return nil, fmt.Errorf("position %d: expected ')', got %s", closing.pos+1, closing)
+	}
+	return &hasNode{field: field.(*fieldNode)}, nil
+}
+
+func expectBool(tok token, operands ...node) error {
+	for _, operand := range operands {
+		if operand.typ() != typeBool {
+			return fmt.Errorf("position %d: '%s' requires boolean operands, got %s", tok.pos+1, tok.text, operand.typ())
+		}
+	}
+	return nil
+}
+
+// ---- evaluación ----
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) typ() valueType {
+	switch n.value.(type) {
+	case bool:
+		return typeBool
+	case float64:
+		return typeNumber
+	}
+	return typeString
+}
+
+func (n *literalNode) eval(Env) (interface{}, error) {
+	return n.value, nil
+}
+
+type fieldNode struct {
+	check string
+	field string
+}
+
+func (n *fieldNode) typ() valueType {
+	switch n.field {
+	case "ok", "failed", "degraded":
+		return typeBool
+	case "status", "message":
+		return typeString
+	}
+	return typeNumber
+}
+
+func (n *fieldNode) eval(env Env) (interface{}, error) {
+	values, ok := env[n.check]
+	if !ok {
+		return nil, fmt.Errorf("check '%s' has no result", n.check)
+	}
+	switch n.field {
+	case "ok":
+		return values.Success, nil
+	case "failed":
+		return !values.Success, nil
+	case "degraded":
+		return values.Degraded, nil
+	case "status":
+		switch {
+		case !values.Success:
+			return "failed", nil
+		case values.Degraded:
+			return "degraded", nil
+		}
+		return "ok", nil
+	case "message":
+		return values.Message, nil
+	case "latency":
+		return values.LatencyMs, nil
+	}
+	if v, ok := values.Metrics[n.field]; ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("check '%s' did not report metric '%s'", n.check, n.field)
+}
+
+// hasNode indica si el check reportó el campo; los campos fijos siempre existen
+type hasNode struct {
+	field *fieldNode
+}
+
+func (n *hasNode) typ() valueType {
+	return typeBool
+}
+
+func (n *hasNode) eval(env Env) (interface{}, error) {
+	values, ok := env[n.field.check]
+	if !ok {
+		return nil, fmt.Errorf("check '%s' has no result", n.field.check)
+	}
+	switch n.field.field {
+	case "ok", "failed", "degraded", "status", "message", "latency":
+		return true, nil
+	}
+	_, ok = values.Metrics[n.field.field]
+	return ok, nil
+}
+
+type logicNode struct {
+	op          string
+	left, right node
+}
+
+func (n *logicNode) typ() valueType {
+	return typeBool
+}
+
+func (n *logicNode) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	l, _ := left.(bool)
+	if (n.op == "and" && !l) || (n.op == "or" && l) {
+		return l, nil
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	r, _ := right.(bool)
+	return r, nil
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) typ() valueType {
+	return typeBool
+}
+
+func (n *notNode) eval(env Env) (interface{}, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, _ := v.(bool)
+	return !b, nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+	re          *regexp.Regexp
+}
+
+func (n *compareNode) typ() valueType {
+	return typeBool
+}
+
+func (n *compareNode) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	case "contains":
+		return strings.Contains(left.(string), right.(string)), nil
+	case "matches":
+		return n.re.MatchString(left.(string)), nil
+	}
+
+	l, r := left.(float64), right.(float64)
+	switch n.op {
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	}
+	return l >= r, nil
+}
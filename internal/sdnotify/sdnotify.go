@@ -0,0 +1,62 @@
+// Package sdnotify implementa el protocolo sd_notify de systemd sin libsystemd: un
+// datagrama por mensaje al socket unix de NOTIFY_SOCKET.
+package sdnotify
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mensajes del protocolo
+const (
+	Ready    = "READY=1"
+	Stopping = "STOPPING=1"
+	Watchdog = "WATCHDOG=1"
+)
+
+// Status mensaje STATUS= con el texto que muestra systemctl status
+func Status(text string) string {
+	// El protocolo es de una línea por campo
+	return "STATUS=" + strings.ReplaceAll(text, "\n", " ")
+}
+
+// Notify envía los mensajes a systemd en un solo datagrama. Retorna false sin error si el
+// proceso no lo ha arrancado systemd con Type=notify (NOTIFY_SOCKET vacío)
+func Notify(messages ...string) (bool, error) {
+	path := os.Getenv("NOTIFY_SOCKET")
+	if path == "" {
+		return false, nil
+	}
+	// Los sockets del namespace abstracto llegan con @ en lugar del byte nulo inicial
+	if strings.HasPrefix(path, "@") {
+		path = "\x00" + path[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(strings.Join(messages, "\n"))); err != nil {
+		return false, fmt.Errorf("failed to send notification: %w", err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval retorna el WatchdogSec de la unidad (WATCHDOG_USEC). false si no está
+// configurado o si WATCHDOG_PID indica que el watchdog es de otro proceso
+func WatchdogInterval() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+	return time.Duration(usec) * time.Microsecond, true
+}
@@ -7,6 +7,7 @@ SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
 BINARY_NAME="neon-watchdog"
 CONFIG_PATH="${CONFIG_PATH:-/etc/neon-watchdog/config.yml}"
 LOCAL_CONFIG_PATH="${SCRIPT_DIR}/examples/config.yml"
+DASHBOARD_URL="${DASHBOARD_URL:-http://localhost:8080}"
 
 # Colores
 RED='\033[0;31m'
@@ -59,6 +60,25 @@ Commands:
   
   check           Ejecutar un check manual
   test            Validar configuración
+
+  approvals       Listar acciones pendientes de aprobación (mode: approval)
+  approve <id>    Aprobar y ejecutar una acción pendiente
+  reject <id>     Rechazar una acción pendiente
+
+  silences        Listar silencios y ventanas de mantenimiento activas
+  silence <target|label=valor> <duración> <motivo>
+                  Silenciar un target (o los que tengan la label) durante la duración (30m, 2h)
+  unsilence <id>  Eliminar un silencio antes de que venza
+
+  paused          Listar targets pausados
+  pause <target> [checks|recovery] <motivo>
+                  Pausar la recuperación (default) o también los checks de un target
+  resume <target> <motivo>
+                  Reanudar un target pausado
+  run-checks <target> <motivo>
+                  Ejecutar ahora los checks de un target
+  recover <target> [--force] <motivo>
+                  Ejecutar ahora la acción de recuperación (--force ignora el cooldown)
   
   enable-daemon   Habilitar modo daemon (en lugar de timer)
   disable-daemon  Deshabilitar modo daemon
@@ -72,6 +92,9 @@ Examples:
   $0 start          # Iniciar servicio
   $0 logs           # Ver logs
   $0 check          # Ejecutar check manual
+  DASHBOARD_USER=admin $0 approvals   # Acciones pendientes (API del dashboard)
+  DASHBOARD_USER=admin $0 silence api 2h "deploy v2.3"   # Sin acciones ni notificaciones
+  DASHBOARD_USER=admin $0 pause postgres checks "vacuum full"
 
 EOF
     exit 0
@@ -180,6 +203,192 @@ cmd_test() {
     "$BINARY_NAME" test-config -c "$CONFIG"
 }
 
+# Llamada autenticada a la API del dashboard (DASHBOARD_URL, DASHBOARD_USER, DASHBOARD_PASSWORD)
+api() {
+    local method="$1" path="$2"
+    [ -n "${DASHBOARD_USER:-}" ] || error "Set DASHBOARD_USER (and DASHBOARD_PASSWORD) to use the dashboard API"
+
+    local auth="$DASHBOARD_USER"
+    if [ -n "${DASHBOARD_PASSWORD:-}" ]; then
+        auth="$DASHBOARD_USER:$DASHBOARD_PASSWORD"
+    fi
+    if [ -n "${3:-}" ]; then
+        curl -sS --fail-with-body -u "$auth" -X "$method" \
+            -H "Content-Type: application/json" -d "$3" "$DASHBOARD_URL$path"
+        return
+    fi
+    curl -sS --fail-with-body -u "$auth" -X "$method" "$DASHBOARD_URL$path"
+}
+
+# Escapa un texto como cadena JSON
+json_string() {
+    printf '"%s"' "$(printf '%s' "$1" | sed 's/\\/\\\\/g; s/"/\\"/g')"
+}
+
+# Comando: approvals
+cmd_approvals() {
+    local out
+    if ! out=$(api GET "/api/approvals?status=pending"); then
+        error "Cannot list approvals: $out"
+    fi
+
+    if ! command -v jq &> /dev/null; then
+        echo "$out"
+        return
+    fi
+    if [ "$(echo "$out" | jq length)" = "0" ]; then
+        info "No pending approvals."
+        return
+    fi
+    echo "$out" | jq -r '.[] | "\(.id)  \(.target)  \(.action)  (expires \(.expires_at))\n    \(.reason)"'
+}
+
+# Comandos: approve / reject
+cmd_decide() {
+    local decision="$1" id="${2:-}"
+    [ -n "$id" ] || error "Usage: $0 $decision <id>"
+
+    local decided
+    case "$decision" in
+        approve) decided="approved" ;;
+        reject)  decided="rejected" ;;
+        *)       error "Unknown decision: $decision" ;;
+    esac
+
+    local out
+    if ! out=$(api POST "/api/approvals/$id/$decision"); then
+        error "Cannot $decision $id: $out"
+    fi
+    success "Approval $id: $decided"
+}
+
+# Comando: silences
+cmd_silences() {
+    local out
+    if ! out=$(api GET "/api/maintenance"); then
+        error "Cannot list silences: $out"
+    fi
+
+    if ! command -v jq &> /dev/null; then
+        echo "$out"
+        return
+    fi
+    echo "$out" | jq -r '
+        (.windows[] | select(.active) | "window   \(.name)  until \(.until)  suppress: \(.suppress // ["actions","notifications"] | join(","))"),
+        (.silences[] | "\(.id)  \((.targets // []) + ((.labels // {}) | to_entries | map("\(.key)=\(.value)")) | join(","))  until \(.expires_at)  by \(.author)\n    \(.reason)")'
+    if [ "$(echo "$out" | jq '[.windows[] | select(.active)] + .silences | length')" = "0" ]; then
+        info "No active silences or maintenance windows."
+    fi
+}
+
+# Comando: silence <target|label=valor> <duración> <motivo>
+cmd_silence() {
+    local scope="${1:-}" duration="${2:-}"
+    shift 2 2>/dev/null || true
+    local reason="$*"
+    [ -n "$scope" ] && [ -n "$duration" ] && [ -n "$reason" ] || \
+        error "Usage: $0 silence <target|label=value> <duration> <reason>"
+
+    local selector
+    case "$scope" in
+        *=*) selector="\"labels\": {$(json_string "${scope%%=*}"): $(json_string "${scope#*=}")}" ;;
+        *)   selector="\"targets\": [$(json_string "$scope")]" ;;
+    esac
+
+    local body out
+    body="{$selector, \"duration\": $(json_string "$duration"), \"reason\": $(json_string "$reason")}"
+    if ! out=$(api POST "/api/silences" "$body"); then
+        error "Cannot create silence: $out"
+    fi
+    if command -v jq &> /dev/null; then
+        success "Silence $(echo "$out" | jq -r .id) created (expires $(echo "$out" | jq -r .expires_at))"
+        return
+    fi
+    success "Silence created: $out"
+}
+
+# Comando: unsilence <id>
+cmd_unsilence() {
+    local id="${1:-}"
+    [ -n "$id" ] || error "Usage: $0 unsilence <id>"
+
+    local out
+    if ! out=$(api DELETE "/api/silences/$id"); then
+        error "Cannot remove silence $id: $out"
+    fi
+    success "Silence $id removed"
+}
+
+# Comando: paused
+cmd_paused() {
+    local out
+    if ! out=$(api GET "/api/paused"); then
+        error "Cannot list paused targets: $out"
+    fi
+
+    if ! command -v jq &> /dev/null; then
+        echo "$out"
+        return
+    fi
+    if [ "$(echo "$out" | jq length)" = "0" ]; then
+        info "No paused targets."
+        return
+    fi
+    echo "$out" | jq -r 'to_entries[] | "\(.key)  \(.value.scope)  by \(.value.by) since \(.value.since)\n    \(.value.reason)"'
+}
+
+# Comandos: pause / resume / run-checks / recover sobre un target
+cmd_control() {
+    local operation="$1" target="${2:-}"
+    shift 2 2>/dev/null || true
+
+    local extra=""
+    case "$operation" in
+        pause)
+            case "${1:-}" in
+                checks|recovery) extra=", \"scope\": \"$1\""; shift ;;
+            esac
+            ;;
+        recover)
+            if [ "${1:-}" = "--force" ]; then
+                extra=", \"force\": true"
+                shift
+            fi
+            ;;
+    esac
+
+    local reason="$*"
+    [ -n "$target" ] && [ -n "$reason" ] || error "Usage: $0 $operation <target> <reason> (see '$0 help')"
+
+    local path="$operation"
+    [ "$operation" = "run-checks" ] && path="check"
+
+    local out
+    if ! out=$(api POST "/api/targets/$target/$path" "{\"reason\": $(json_string "$reason")$extra}"); then
+        error "Cannot $operation $target: $out"
+    fi
+
+    case "$operation" in
+        run-checks)
+            if echo "$out" | grep -q '"healthy":true'; then
+                success "$target is healthy"
+            else
+                warning "$target is unhealthy"
+            fi
+            ;;
+        recover)
+            if echo "$out" | grep -q '"success":true'; then
+                success "Recovery of $target succeeded: $out"
+            else
+                error "Recovery of $target failed: $out"
+            fi
+            ;;
+        *)
+            success "$target: ${operation}d"
+            ;;
+    esac
+}
+
 # Comando: enable-daemon
 cmd_enable_daemon() {
     info "Switching to daemon mode..."
@@ -238,6 +447,28 @@ case "${1:-}" in
     test)
         cmd_test
         ;;
+    approvals)
+        cmd_approvals
+        ;;
+    approve|reject)
+        cmd_decide "$1" "${2:-}"
+        ;;
+    silences)
+        cmd_silences
+        ;;
+    silence)
+        shift
+        cmd_silence "$@"
+        ;;
+    unsilence)
+        cmd_unsilence "${2:-}"
+        ;;
+    paused)
+        cmd_paused
+        ;;
+    pause|resume|run-checks|recover)
+        cmd_control "$@"
+        ;;
     enable-daemon)
         cmd_enable_daemon
         ;;
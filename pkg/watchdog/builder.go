@@ -0,0 +1,96 @@
+package watchdog
+
+import (
+	"fmt"
+	"time"
+)
+
+// Builder construye una configuración desde código en lugar de un archivo
+type Builder struct {
+	cfg Config
+}
+
+// NewBuilder crea un builder con los valores por defecto del watchdog
+func NewBuilder() *Builder {
+	return &Builder{
+		cfg: Config{
+			IntervalSeconds: 30,
+			TimeoutSeconds:  10,
+			LogLevel:        "INFO",
+		},
+	}
+}
+
+// Interval define cada cuánto se ejecutan los checks en modo daemon
+func (b *Builder) Interval(d time.Duration) *Builder {
+	b.cfg.IntervalSeconds = int(d / time.Second)
+	return b
+}
+
+// Timeout define el tiempo máximo de cada check y acción
+func (b *Builder) Timeout(d time.Duration) *Builder {
+	b.cfg.TimeoutSeconds = int(d / time.Second)
+	return b
+}
+
+// LogLevel define el nivel de log (DEBUG, INFO, WARN, ERROR)
+func (b *Builder) LogLevel(level string) *Builder {
+	b.cfg.LogLevel = level
+	return b
+}
+
+// StateFile define dónde se persiste el estado entre ejecuciones
+func (b *Builder) StateFile(path string) *Builder {
+	b.cfg.StateFile = path
+	return b
+}
+
+// PluginsDir define el directorio de plugins externos
+func (b *Builder) PluginsDir(dir string) *Builder {
+	b.cfg.PluginsDir = dir
+	return b
+}
+
+// DefaultPolicy define la política aplicada a los targets sin política propia
+func (b *Builder) DefaultPolicy(p Policy) *Builder {
+	b.cfg.DefaultPolicy = p
+	return b
+}
+
+// AddTarget añade un target a monitorizar
+func (b *Builder) AddTarget(t Target) *Builder {
+	b.cfg.Targets = append(b.cfg.Targets, t)
+	return b
+}
+
+// Build valida la configuración y completa los valores por defecto
+func (b *Builder) Build() (*Config, error) {
+	cfg := b.cfg
+	cfg.Targets = append([]Target(nil), b.cfg.Targets...)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	cfg.SetDefaults()
+	return &cfg, nil
+}
+
+// NewTarget crea un target habilitado con su acción de recuperación y sus checks
+func NewTarget(name string, action ActionConfig, checks ...Check) Target {
+	return Target{
+		Name:    name,
+		Enabled: true,
+		Checks:  checks,
+		Action:  action,
+	}
+}
+
+// CustomCheck crea la configuración de un check de un tipo registrado con RegisterCheck
+func CustomCheck(checkType string, options map[string]interface{}) Check {
+	return Check{Type: checkType, Options: options}
+}
+
+// CustomAction crea la configuración de una acción de un tipo registrado con RegisterAction
+func CustomAction(actionType string, options map[string]interface{}) ActionConfig {
+	return ActionConfig{Type: actionType, Options: options}
+}
@@ -0,0 +1,185 @@
+// Package watchdog expone el motor de neon-watchdog para embeberlo en otros programas Go
+// y registrar tipos de check y de acción propios.
+//
+//	watchdog.RegisterCheck("queue_depth", newQueueChecker, nil)
+//
+//	cfg, err := watchdog.NewBuilder().
+//		Interval(30 * time.Second).
+//		AddTarget(watchdog.NewTarget("worker",
+//			watchdog.ActionConfig{Type: "systemd", Systemd: &watchdog.SystemdAction{Unit: "worker.service"}},
+//			watchdog.CustomCheck("queue_depth", map[string]interface{}{"max": 1000}),
+//		)).
+//		Build()
+//
+//	eng, err := watchdog.New(cfg, watchdog.WithEventHandler(func(ev watchdog.Event) { ... }))
+//	eng.Run(ctx)
+//
+// RunExclusive y CheckOnceExclusive hacen lo mismo que Run y CheckOnce con el bloqueo de
+// instancia única (instance en la configuración), como los comandos run y check.
+package watchdog
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tgextreme/neon-watchdog/internal/actions"
+	"github.com/tgextreme/neon-watchdog/internal/checks"
+	"github.com/tgextreme/neon-watchdog/internal/config"
+	"github.com/tgextreme/neon-watchdog/internal/engine"
+	"github.com/tgextreme/neon-watchdog/internal/logger"
+	"github.com/tgextreme/neon-watchdog/internal/notifications"
+)
+
+// Tipos de configuración
+type (
+	Config        = config.Config
+	Target        = config.Target
+	Check         = config.Check
+	ActionConfig  = config.Action
+	Policy        = config.Policy
+	ExecAction    = config.ExecAction
+	SystemdAction = config.SystemdAction
+	ActionHooks   = config.ActionHooks
+	Hook          = config.Hook
+
+	MaintenanceConfig = config.MaintenanceConfig
+	MaintenanceWindow = config.MaintenanceWindow
+)
+
+// Tipos de ejecución
+type (
+	Engine       = engine.Engine
+	Checker      = checks.Checker
+	Result       = checks.Result
+	Metric       = checks.Metric
+	Action       = actions.Action
+	ActionResult = actions.Result
+	HookContext  = actions.HookContext
+	Event        = notifications.Event
+	EventHandler = engine.EventHandler
+	Logger       = logger.Logger
+)
+
+// CheckFactory construye un checker a partir de su configuración
+type CheckFactory = checks.Factory
+
+// ActionFactory construye una acción; isFirstFailure permite elegir entre start y restart
+type ActionFactory = actions.Factory
+
+// CheckValidator valida la configuración de un check al cargarla (puede ser nil)
+type CheckValidator = config.CheckValidator
+
+// ActionValidator valida la configuración de una acción al cargarla (puede ser nil)
+type ActionValidator = config.ActionValidator
+
+// RegisterCheck da de alta un tipo de check. Debe llamarse antes de cargar la configuración
+// (normalmente desde init). Falla si el nombre ya existe
+func RegisterCheck(name string, factory CheckFactory, validate CheckValidator) {
+	if factory == nil {
+		panic(fmt.Sprintf("watchdog: nil factory for check type %q", name))
+	}
+	config.RegisterCheckType(name, validate)
+	checks.Register(name, factory)
+}
+
+// RegisterAction da de alta un tipo de acción. Debe llamarse antes de cargar la configuración
+// (normalmente desde init). Falla si el nombre ya existe
+func RegisterAction(name string, factory ActionFactory, validate ActionValidator) {
+	if factory == nil {
+		panic(fmt.Sprintf("watchdog: nil factory for action type %q", name))
+	}
+	config.RegisterActionType(name, validate)
+	actions.Register(name, factory)
+}
+
+// CheckTypes retorna los tipos de check registrados, incluidos los integrados
+func CheckTypes() []string {
+	return config.CheckTypes()
+}
+
+// ActionTypes retorna los tipos de acción registrados, incluidos los integrados
+func ActionTypes() []string {
+	return config.ActionTypes()
+}
+
+// DecodeOptions decodifica el bloque 'options' de un check o acción en una estructura con tags yaml
+func DecodeOptions(options map[string]interface{}, out interface{}) error {
+	return config.DecodeOptions(options, out)
+}
+
+// NewLogger crea un logger con el formato del watchdog (DEBUG, INFO, WARN, ERROR)
+func NewLogger(level string, output io.Writer) *Logger {
+	return logger.New(level, output)
+}
+
+// LoadConfig carga y valida un archivo de configuración YAML o JSON
+func LoadConfig(path string) (*Config, error) {
+	return config.Load(path)
+}
+
+// Ping registra un ping de heartbeat como `neon-watchdog ping`: por el socket del daemon
+// (heartbeat.socket_path) o, sin daemon, en el state file de heartbeats. suffix es "",
+// start, success, fail o un exit code
+func Ping(cfg *Config, token, suffix, body string) error {
+	return engine.Ping(cfg, token, suffix, body, logger.New(cfg.LogLevel, os.Stderr))
+}
+
+// options opciones de construcción del engine
+type options struct {
+	logger   *Logger
+	handlers []EventHandler
+}
+
+// Option modifica la construcción del engine
+type Option func(*options)
+
+// WithLogger usa el logger indicado en lugar de uno a stderr con el nivel de la configuración
+func WithLogger(l *Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithEventHandler suscribe un handler a los eventos del engine desde su creación
+func WithEventHandler(handler EventHandler) Option {
+	return func(o *options) {
+		o.handlers = append(o.handlers, handler)
+	}
+}
+
+// New valida la configuración, carga el estado guardado (state_file, heartbeats,
+// silencios y cola de aprobaciones) y crea un engine listo para CheckOnce o Run. El
+// proceso no toma el bloqueo de instancia: no debe compartir state_file con un daemon
+func New(cfg *Config, opts ...Option) (*Engine, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config is nil")
+	}
+
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	cfg.SetDefaults()
+
+	log := o.logger
+	if log == nil {
+		log = logger.New(cfg.LogLevel, os.Stderr)
+	}
+
+	eng := engine.New(cfg, log)
+
+	// Un state file ilegible (y sin .bak válido) no impide arrancar: se empieza de cero. Uno
+	// de una versión posterior sí, para no sobrescribirlo
+	if err := eng.Restore(true); err != nil {
+		return nil, err
+	}
+	for _, handler := range o.handlers {
+		eng.Subscribe(handler)
+	}
+	return eng, nil
+}